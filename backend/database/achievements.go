@@ -0,0 +1,35 @@
+package database
+
+import (
+	"log"
+
+	"rust-legacy-site/models"
+)
+
+// seedAchievements creates default achievements if there are none
+func seedAchievements() error {
+	var count int64
+	DB.Model(&models.Achievement{}).Count(&count)
+	if count > 0 {
+		return nil
+	}
+	defaults := []models.Achievement{
+		{Key: "kills_100", Name: "Охотник за головами", NameEn: "Headhunter",
+			Description: "Убить 100 игроков", DescriptionEn: "Kill 100 players",
+			Icon: "crosshair", Metric: "killedPlayers", Threshold: 100, Enabled: true, Order: 1},
+		{Key: "playtime_100h", Name: "Старожил", NameEn: "Old-timer",
+			Description: "Провести на сервере 100 часов", DescriptionEn: "Play 100 hours on the server",
+			Icon: "clock", Metric: "playTime", Threshold: 100 * 60, Enabled: true, Order: 2},
+		{Key: "sulfur_10k", Name: "Серный барон", NameEn: "Sulfur baron",
+			Description: "Добыть 10 000 серы", DescriptionEn: "Farm 10,000 sulfur",
+			Icon: "pickaxe", Metric: "sulfur", Threshold: 10000, Enabled: true, Order: 3},
+		{Key: "clan_leader", Name: "Вождь", NameEn: "Chieftain",
+			Description: "Стать лидером клана", DescriptionEn: "Become a clan leader",
+			Icon: "crown", Metric: "clanLeader", Threshold: 1, Enabled: true, Order: 4},
+	}
+	if err := DB.Create(&defaults).Error; err != nil {
+		return err
+	}
+	log.Printf("[Database] Seeded %d default achievements", len(defaults))
+	return nil
+}
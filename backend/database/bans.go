@@ -0,0 +1,19 @@
+package database
+
+import (
+	"log"
+	"time"
+)
+
+// migrateBans leaves one active ban per SteamID (the newest) and creates the partial unique index that enforces it
+func migrateBans() error {
+	res := DB.Exec(`UPDATE bans SET active = false, unbanned_at = ?, unbanned_by = 'system', unban_reason = 'duplicate active ban', rcon_status = 'ok'
+		WHERE active AND id NOT IN (SELECT MAX(id) FROM bans WHERE active GROUP BY steam_id)`, time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("[Database] bans: %d duplicate active bans deactivated", res.RowsAffected)
+	}
+	return DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_bans_active_steam_id ON bans (steam_id) WHERE active`).Error
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const clanHistoryKeepDays = 365
+
+// RefreshClanStats rebuilds clan_stats from clan members in a single query and stores today's
+// snapshot in clan_stat_histories. Called after stats sync / import and on startup.
+func RefreshClanStats() error {
+	now := time.Now()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM clan_stats").Error; err != nil {
+			return err
+		}
+		// Участник = clan_members (заполняется и синхронизацией, и импортом), DISTINCT — на случай дублей
+		if err := tx.Exec(`INSERT INTO clan_stats (clan_id, hex_id, members, online_members, total_kills, total_deaths,
+				avg_kills, kd, wood, metal, sulfur, total_farm, raid_objects, refreshed_at)
+			SELECT c.id, c.hex_id, agg.members, agg.online_members, agg.kills, agg.deaths,
+				CASE WHEN agg.members = 0 THEN 0 ELSE agg.kills::float8 / agg.members END,
+				CASE WHEN agg.deaths = 0 THEN agg.kills::float8 ELSE agg.kills::float8 / agg.deaths END,
+				agg.wood, agg.metal, agg.sulfur, agg.wood + agg.metal + agg.sulfur, agg.raid_objects, ?
+			FROM clans c
+			LEFT JOIN LATERAL (
+				SELECT COUNT(p.id) AS members,
+					COUNT(p.id) FILTER (WHERE p.is_online) AS online_members,
+					COALESCE(SUM(p.killed_players), 0) AS kills,
+					COALESCE(SUM(p.deaths), 0) AS deaths,
+					COALESCE(SUM(s.wood), 0) AS wood,
+					COALESCE(SUM(s.metal), 0) AS metal,
+					COALESCE(SUM(s.sulfur), 0) AS sulfur,
+					COALESCE(SUM(s.raid_objects), 0) AS raid_objects
+				FROM (SELECT DISTINCT steam_id FROM clan_members WHERE clan_id = c.id) m
+				JOIN players p ON p.steam_id = m.steam_id
+				LEFT JOIN player_stats s ON s.steam_id = p.steam_id
+			) agg ON true`, now).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO clan_stat_histories (hex_id, day, name, level, experience, members,
+				total_kills, total_deaths, kd, total_farm, raid_objects)
+			SELECT c.hex_id, ?::date, c.name, c.level, c.experience, cs.members,
+				cs.total_kills, cs.total_deaths, cs.kd, cs.total_farm, cs.raid_objects
+			FROM clans c JOIN clan_stats cs ON cs.clan_id = c.id
+			WHERE c.hex_id <> ''
+			ON CONFLICT (hex_id, day) DO UPDATE SET name = excluded.name, level = excluded.level,
+				experience = excluded.experience, members = excluded.members, total_kills = excluded.total_kills,
+				total_deaths = excluded.total_deaths, kd = excluded.kd, total_farm = excluded.total_farm,
+				raid_objects = excluded.raid_objects`, now.Format("2006-01-02")).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM clan_stat_histories WHERE day < ?::date",
+			now.AddDate(0, 0, -clanHistoryKeepDays).Format("2006-01-02")).Error
+	})
+}
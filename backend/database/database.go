@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"rust-legacy-site/models"
@@ -70,45 +71,145 @@ func Connect() error {
 	return nil
 }
 
+// migratedModels - all tables of the site (AutoMigrate, media references in media.go)
+var migratedModels = []interface{}{
+	&models.ServerInfo{},
+	&models.Description{},
+	&models.Feature{},
+	&models.News{},
+	&models.HowToStartStep{},
+	&models.ServerDetail{},
+	&models.Plugin{},
+	&models.Command{},
+	&models.Rule{},
+	&models.PaymentMethod{},
+	&models.LegalDocument{},
+	&models.Clan{},
+	&models.ClanMember{},
+	&models.ClanStats{},
+	&models.ClanStatHistory{},
+	&models.Player{},
+	&models.PlayerStats{},
+	&models.PlayerName{},
+	&models.Achievement{},
+	&models.PlayerAchievement{},
+	&models.KillEvent{},
+	&models.PlayerSession{},
+	&models.Ban{},
+	&models.Ticket{},
+	&models.TicketMessage{},
+	&models.PlayerCounters{},
+	&models.PlayerStatDelta{},
+	&models.User{},
+	&models.Order{},
+	&models.Transaction{},
+	&models.AdminUser{},
+	&models.Setting{},
+	&models.ShopCategory{},
+	&models.ShopItem{},
+	&models.DownloadLink{},
+	&models.Theme{},
+	&models.FontSettings{},
+	&models.CompanyInfo{},
+	&models.OnlineHistory{},
+	&models.OnlineHistoryRollup{},
+	&models.RconJob{},
+	&models.RconJobRun{},
+	&models.RconConsoleCommand{},
+	&models.RconAllowedCommand{},
+	&models.SecurityEvent{},
+	&models.ContentRevision{},
+	&models.MediaFile{},
+	&models.MirrorHealth{},
+	&models.MirrorCheck{},
+	&models.DownloadClick{},
+}
+
 func Migrate() error {
-	err := DB.AutoMigrate(
-		&models.ServerInfo{},
-		&models.Description{},
-		&models.Feature{},
-		&models.News{},
-		&models.HowToStartStep{},
-		&models.ServerDetail{},
-		&models.Plugin{},
-		&models.Command{},
-		&models.Rule{},
-		&models.PaymentMethod{},
-		&models.LegalDocument{},
-		&models.Clan{},
-		&models.ClanMember{},
-		&models.Player{},
-		&models.PlayerStats{},
-		&models.User{},
-		&models.Order{},
-		&models.Transaction{},
-		&models.AdminUser{},
-		&models.Setting{},
-		&models.ShopCategory{},
-		&models.ShopItem{},
-		&models.DownloadLink{},
-		&models.Theme{},
-		&models.FontSettings{},
-		&models.CompanyInfo{},
-		&models.OnlineHistory{},
-	)
+	newsAnnounceTracked := DB.Migrator().HasColumn(&models.News{}, "AnnouncedAt")
+	err := DB.AutoMigrate(migratedModels...)
 
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := migrateShopRconTemplates(); err != nil {
+		return fmt.Errorf("failed to migrate shop RCON templates: %w", err)
+	}
+
+	if err := LinkTranslationGroups(); err != nil {
+		return fmt.Errorf("failed to link translation groups: %w", err)
+	}
+
+	if err := migrateNews(!newsAnnounceTracked); err != nil {
+		return fmt.Errorf("failed to migrate news: %w", err)
+	}
+
+	if err := migrateBans(); err != nil {
+		return fmt.Errorf("failed to migrate bans: %w", err)
+	}
+
+	if err := BackfillRevisions(); err != nil {
+		return err
+	}
+
+	migrateNameSearch()
+
+	if err := seedAchievements(); err != nil {
+		return fmt.Errorf("failed to seed achievements: %w", err)
+	}
+
+	if err := RefreshClanStats(); err != nil {
+		return fmt.Errorf("failed to refresh clan stats: %w", err)
+	}
+
 	log.Println("Database migration completed")
 	return nil
 }
 
+// migrateShopRconTemplates converts legacy "*" commands to {{steamid}} templates and,
+// if the allowlist is empty, seeds it with commands already used by shop items.
+func migrateShopRconTemplates() error {
+	var items []models.ShopItem
+	if err := DB.Where("rcon_command <> '' AND rcon_command NOT LIKE ?", "%{{%").Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !strings.Contains(item.RconCommand, "*") {
+			continue
+		}
+		tpl := strings.ReplaceAll(item.RconCommand, "*", "{{steamid}}")
+		if err := DB.Model(&models.ShopItem{}).Where("id = ?", item.ID).Update("rcon_command", tpl).Error; err != nil {
+			return err
+		}
+		log.Printf("[Database] shop item %d: RCON command converted to template", item.ID)
+	}
+
+	var count int64
+	DB.Model(&models.RconAllowedCommand{}).Count(&count)
+	if count > 0 {
+		return nil
+	}
+	var commands []string
+	DB.Model(&models.ShopItem{}).Where("rcon_command <> ''").Pluck("rcon_command", &commands)
+	seen := make(map[string]bool)
+	for _, tpl := range commands {
+		for _, line := range strings.Split(tpl, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			name := strings.ToLower(strings.TrimLeft(fields[0], "/"))
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			DB.Create(&models.RconAllowedCommand{Command: name, Description: "seeded from existing shop items"})
+		}
+	}
+	return nil
+}
+
 func Seed() error {
 	var count int64
 	DB.Model(&models.ServerInfo{}).Count(&count)
@@ -382,6 +483,14 @@ func Seed() error {
 		return err
 	}
 
+	// Связываем RU/EN версии seed-данных
+	if err := LinkTranslationGroups(); err != nil {
+		return err
+	}
+	if err := BackfillRevisions(); err != nil {
+		return err
+	}
+
 	log.Println("Database seeded successfully")
 	return nil
 }
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/models"
+
+	"gorm.io/gorm/schema"
+)
+
+type mediaReference struct {
+	table   string
+	columns []string
+}
+
+var (
+	mediaRefsOnce sync.Once
+	mediaRefs     []mediaReference
+)
+
+// mediaReferences returns columns where uploaded media URLs may be used (URL поля и HTML контент):
+// fields tagged media:"ref" of the migrated models. Старые ревизии (content_revisions) тоже держат файлы для отката.
+func mediaReferences() []mediaReference {
+	mediaRefsOnce.Do(func() {
+		var err error
+		if mediaRefs, err = mediaReferencesOf(migratedModels, DB.NamingStrategy); err != nil {
+			panic(err)
+		}
+	})
+	return mediaRefs
+}
+
+// mediaReferencesOf collects media:"ref" columns of the models
+func mediaReferencesOf(list []interface{}, namer schema.Namer) ([]mediaReference, error) {
+	var refs []mediaReference
+	cache := &sync.Map{}
+	for _, m := range list {
+		s, err := schema.Parse(m, cache, namer)
+		if err != nil {
+			return nil, err
+		}
+		ref := mediaReference{table: s.Table}
+		for _, f := range s.Fields {
+			if f.DBName != "" && f.Tag.Get("media") == "ref" {
+				ref.columns = append(ref.columns, f.DBName)
+			}
+		}
+		if len(ref.columns) > 0 {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// mediaUsedExpr - SQL condition "media_files row m is referenced somewhere"
+func mediaUsedExpr() string {
+	var parts []string
+	for _, ref := range mediaReferences() {
+		var cond []string
+		for _, col := range ref.columns {
+			cond = append(cond, fmt.Sprintf("strpos(r.%[1]s, m.key) > 0 OR (m.thumb_key <> '' AND strpos(r.%[1]s, m.thumb_key) > 0)", col))
+		}
+		parts = append(parts, fmt.Sprintf("EXISTS (SELECT 1 FROM %s r WHERE %s)", ref.table, strings.Join(cond, " OR ")))
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// UsedMediaIDs returns which of the media files are referenced by content
+func UsedMediaIDs(ids []uint) (map[uint]bool, error) {
+	used := make(map[uint]bool)
+	if len(ids) == 0 {
+		return used, nil
+	}
+	var rows []uint
+	err := DB.Raw("SELECT m.id FROM media_files m WHERE m.id IN ? AND "+mediaUsedExpr(), ids).Scan(&rows).Error
+	for _, id := range rows {
+		used[id] = true
+	}
+	return used, err
+}
+
+// UnusedMedia returns media files uploaded before the time and not referenced anywhere
+func UnusedMedia(before time.Time) ([]models.MediaFile, error) {
+	var files []models.MediaFile
+	err := DB.Raw("SELECT m.* FROM media_files m WHERE m.created_at < ? AND NOT "+mediaUsedExpr(), before).Scan(&files).Error
+	return files, err
+}
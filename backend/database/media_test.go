@@ -0,0 +1,46 @@
+package database
+
+import (
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestMediaReferencesOf(t *testing.T) {
+	refs, err := mediaReferencesOf(migratedModels, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	for _, ref := range refs {
+		for _, col := range ref.columns {
+			got[ref.table+"."+col] = true
+		}
+	}
+	for _, want := range []string{
+		"news.image_url",
+		"news.content",
+		"how_to_start_steps.video_url",
+		"server_details.content",
+		"features.icon",
+		"descriptions.content",
+		"payment_methods.image_url",
+		"shop_items.description",
+		"rules.content",
+		"legal_documents.content",
+		"content_revisions.content",
+		"settings.value",
+		"achievements.icon",
+		"plugins.description",
+		"commands.description",
+	} {
+		t.Run(want, func(t *testing.T) {
+			if !got[want] {
+				t.Errorf("%s is not a media reference", want)
+			}
+		})
+	}
+	if got["media_files.url"] {
+		t.Error("media_files.url must not keep files referenced")
+	}
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/slug"
+)
+
+// UniqueNewsSlug returns slug based on base that is not used by other news (excludeID)
+func UniqueNewsSlug(base string, excludeID uint) string {
+	s := slug.Make(base)
+	if s == "" {
+		s = "news"
+	}
+	candidate := s
+	for i := 2; ; i++ {
+		var count int64
+		DB.Model(&models.News{}).Where("slug = ? AND id <> ?", candidate, excludeID).Count(&count)
+		if count == 0 {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", s, i)
+	}
+}
+
+// migrateNews fills slugs of old news and adds unique index on non-empty slugs.
+// markAnnounced: announced_at column has just been added — already visible news are not announced again.
+func migrateNews(markAnnounced bool) error {
+	var news []models.News
+	if err := DB.Where("slug IS NULL OR slug = ''").Order("id ASC").Find(&news).Error; err != nil {
+		return err
+	}
+	for _, n := range news {
+		s := UniqueNewsSlug(n.Title, n.ID)
+		if err := DB.Model(&models.News{}).Where("id = ?", n.ID).Update("slug", s).Error; err != nil {
+			return err
+		}
+	}
+	if len(news) > 0 {
+		log.Printf("[Database] news: slugs generated for %d rows", len(news))
+	}
+	if err := DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_news_slug_unique ON news (slug) WHERE slug <> ''`).Error; err != nil {
+		return err
+	}
+	if markAnnounced {
+		return DB.Exec(`UPDATE news SET announced_at = published_at WHERE published AND published_at <= NOW() AND announced_at IS NULL`).Error
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/models"
+)
+
+var (
+	trigramAvailable     bool
+	trigramAvailableOnce sync.Once
+)
+
+// migrateNameSearch enables pg_trgm and creates indexes for player search.
+// Without pg_trgm (нет прав на CREATE EXTENSION) search falls back to prefix/substring match.
+func migrateNameSearch() {
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("[Players] pg_trgm is not available, fuzzy search disabled: %v", err)
+	}
+	stmts := []string{
+		"CREATE INDEX IF NOT EXISTS idx_players_username_lower ON players (lower(username) text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_player_names_name_lower ON player_names (lower(name) text_pattern_ops)",
+	}
+	if TrigramAvailable() {
+		stmts = append(stmts,
+			"CREATE INDEX IF NOT EXISTS idx_players_username_trgm ON players USING gin (lower(username) gin_trgm_ops)",
+			"CREATE INDEX IF NOT EXISTS idx_player_names_name_trgm ON player_names USING gin (lower(name) gin_trgm_ops)",
+		)
+	}
+	for _, stmt := range stmts {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Printf("[Players] %s: %v", stmt, err)
+		}
+	}
+}
+
+// TrigramAvailable reports whether pg_trgm extension is installed
+func TrigramAvailable() bool {
+	trigramAvailableOnce.Do(func() {
+		var n int64
+		DB.Raw("SELECT COUNT(*) FROM pg_extension WHERE extname = 'pg_trgm'").Scan(&n)
+		trigramAvailable = n > 0
+	})
+	return trigramAvailable
+}
+
+// NameChange - player was seen with a different username
+type NameChange struct {
+	SteamID string
+	OldName string
+	NewName string
+	Since   time.Time // с какого времени известен старый ник (если истории ещё нет)
+}
+
+// RecordNameChanges appends new names to player_names. If a player has no history yet,
+// the old name is stored first so that it stays searchable.
+func RecordNameChanges(changes []NameChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	steamIDs := make([]string, 0, len(changes))
+	for _, c := range changes {
+		steamIDs = append(steamIDs, c.SteamID)
+	}
+	var known []string
+	if err := DB.Model(&models.PlayerName{}).Where("steam_id IN ?", steamIDs).Distinct().Pluck("steam_id", &known).Error; err != nil {
+		return err
+	}
+	hasHistory := make(map[string]bool, len(known))
+	for _, id := range known {
+		hasHistory[id] = true
+	}
+
+	now := time.Now()
+	var rows []models.PlayerName
+	for _, c := range changes {
+		newName := strings.TrimSpace(c.NewName)
+		if newName == "" || newName == strings.TrimSpace(c.OldName) {
+			continue
+		}
+		if !hasHistory[c.SteamID] && strings.TrimSpace(c.OldName) != "" {
+			since := c.Since
+			if since.IsZero() || !since.Before(now) {
+				since = now.Add(-time.Second)
+			}
+			rows = append(rows, models.PlayerName{SteamID: c.SteamID, Name: strings.TrimSpace(c.OldName), SeenAt: since})
+			hasHistory[c.SteamID] = true
+		}
+		rows = append(rows, models.PlayerName{SteamID: c.SteamID, Name: newName, SeenAt: now})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return DB.CreateInBatches(&rows, 500).Error
+}
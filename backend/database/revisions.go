@@ -0,0 +1,26 @@
+package database
+
+import "fmt"
+
+// BackfillRevisions creates revision v1 for legal documents and rules that have no history yet
+// (записи до появления ревизий и seed).
+func BackfillRevisions() error {
+	stmts := []string{
+		`INSERT INTO content_revisions (entity_type, entity_id, version, language, type, title, content, author_name, comment, created_at)
+		SELECT 'legal_document', id, 1, language, type, title, content, 'system', 'initial version', COALESCE(updated_at, NOW())
+		FROM legal_documents WHERE COALESCE(version, 0) = 0
+		ON CONFLICT DO NOTHING`,
+		`UPDATE legal_documents SET version = 1 WHERE COALESCE(version, 0) = 0`,
+		`INSERT INTO content_revisions (entity_type, entity_id, version, language, title, content, "order", author_name, comment, created_at)
+		SELECT 'rule', id, 1, language, title, content, "order", 'system', 'initial version', COALESCE(updated_at, NOW())
+		FROM rules WHERE COALESCE(version, 0) = 0
+		ON CONFLICT DO NOTHING`,
+		`UPDATE rules SET version = 1 WHERE COALESCE(version, 0) = 0`,
+	}
+	for _, q := range stmts {
+		if err := DB.Exec(q).Error; err != nil {
+			return fmt.Errorf("backfill revisions: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"log"
+
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/sanitize"
+
+	"gorm.io/gorm"
+)
+
+// ResanitizeContent runs HTML sanitizer over rich-text content already stored in DB
+// (записи, созданные до появления санитайзера). With dryRun only reports what would change.
+// Rules and legal documents get a new revision by "system", so the change is visible in their history.
+func ResanitizeContent(dryRun bool) (int, error) {
+	tables := []struct {
+		name     string
+		model    interface{}
+		revision string // entity_type в content_revisions, "" = без истории
+	}{
+		{"how_to_start_steps", &models.HowToStartStep{}, ""},
+		{"news", &models.News{}, ""},
+		{"server_details", &models.ServerDetail{}, ""},
+		{"rules", &models.Rule{}, "rule"},
+		{"legal_documents", &models.LegalDocument{}, "legal_document"},
+	}
+
+	changed := 0
+	for _, t := range tables {
+		var rows []struct {
+			ID      uint
+			Content string
+		}
+		if err := DB.Model(t.model).Select("id, content").Find(&rows).Error; err != nil {
+			return changed, err
+		}
+		for _, row := range rows {
+			clean := sanitize.HTML(row.Content)
+			if clean == row.Content {
+				continue
+			}
+			changed++
+			log.Printf("[Sanitize] %s #%d: %d -> %d bytes", t.name, row.ID, len(row.Content), len(clean))
+			if dryRun {
+				continue
+			}
+			if t.revision != "" {
+				if err := resanitizeWithRevision(t.name, t.revision, row.ID, clean); err != nil {
+					return changed, err
+				}
+				continue
+			}
+			// UpdateColumn: не трогаем updated_at, содержимое по смыслу не менялось
+			if err := DB.Model(t.model).Where("id = ?", row.ID).UpdateColumn("content", clean).Error; err != nil {
+				return changed, err
+			}
+		}
+	}
+	return changed, nil
+}
+
+// revisionInsert copies the current row of a versioned table into content_revisions
+var revisionInsert = map[string]string{
+	"rules": `INSERT INTO content_revisions (entity_type, entity_id, version, language, title, content, "order", author_name, comment, created_at)
+		SELECT 'rule', id, version, language, title, content, "order", 'system', 'HTML re-sanitized', NOW() FROM rules WHERE id = ?`,
+	"legal_documents": `INSERT INTO content_revisions (entity_type, entity_id, version, language, type, title, content, author_name, comment, created_at)
+		SELECT 'legal_document', id, version, language, type, title, content, 'system', 'HTML re-sanitized', NOW() FROM legal_documents WHERE id = ?`,
+}
+
+// resanitizeWithRevision stores sanitized content as the next version and records its revision in one transaction
+func resanitizeWithRevision(table, entityType string, id uint, clean string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var version int
+		if err := tx.Raw(`SELECT COALESCE(MAX(version), 0) + 1 FROM content_revisions WHERE entity_type = ? AND entity_id = ?`, entityType, id).
+			Scan(&version).Error; err != nil {
+			return err
+		}
+		if err := tx.Table(table).Where("id = ?", id).UpdateColumns(map[string]interface{}{"content": clean, "version": version}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(revisionInsert[table], id).Error
+	})
+}
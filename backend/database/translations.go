@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+	"log"
+)
+
+// translationKeys - natural key that RU/EN versions of the same row share (used only for backfill)
+var translationKeys = []struct {
+	table string
+	key   string
+}{
+	{"features", `server_info_id || ':' || "order"`},
+	{"news", `id`}, // новости не связываем автоматически
+	{"how_to_start_steps", `step_number`},
+	{"server_details", `server_id || ':' || section || ':' || "order"`},
+	{"plugins", `server_id || ':' || "order"`},
+	{"rules", `"order"`},
+	{"legal_documents", `type`},
+	{"shop_categories", `"order"`},
+	// порядок и цены у языковых версий различаются; одинаковая RCON команда в связанной категории = тот же товар
+	{"shop_items", `CASE WHEN rcon_command <> '' THEN (SELECT c.translation_group FROM shop_categories c WHERE c.id = category_id) || ':' || rcon_command ELSE 'id:' || id END`},
+}
+
+// LinkTranslationGroups assigns translation groups to rows without one.
+// Rows sharing the natural key are linked only if the key has at most one row per language.
+func LinkTranslationGroups() error {
+	for _, t := range translationKeys {
+		res := DB.Exec(fmt.Sprintf(`WITH keyed AS (
+			SELECT id, language, (%[2]s)::text AS k FROM %[1]s
+			WHERE translation_group IS NULL OR translation_group = ''
+		), linkable AS (
+			SELECT k FROM keyed GROUP BY k HAVING COUNT(*) = COUNT(DISTINCT language)
+		)
+		UPDATE %[1]s SET translation_group = CASE
+			WHEN keyed.k IN (SELECT k FROM linkable) THEN substr(md5('%[1]s:' || keyed.k), 1, 16)
+			ELSE substr(md5('%[1]s#' || %[1]s.id), 1, 16)
+		END
+		FROM keyed WHERE %[1]s.id = keyed.id`, t.table, t.key))
+		if res.Error != nil {
+			return fmt.Errorf("%s: %w", t.table, res.Error)
+		}
+		if res.RowsAffected > 0 {
+			log.Printf("[Database] %s: translation groups assigned to %d rows", t.table, res.RowsAffected)
+		}
+	}
+	return nil
+}
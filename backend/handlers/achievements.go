@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/achievements"
+	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/rcon"
+	"rust-legacy-site/pkg/security"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// achievementRewardMaxTries - после стольких ошибок RCON награда помечается failed
+const achievementRewardMaxTries = 5
+
+// runAchievements evaluates rules and delivers pending rewards; called in background after stats sync
+func runAchievements() {
+	achievements.Evaluate()
+	for _, pa := range achievements.PendingRewards() {
+		// Параллельные запуски (sync, импорт, кланы) видят одни и те же pending — выдаёт только захвативший
+		if achievements.Claim(pa.ID) {
+			grantAchievementReward(pa)
+		}
+	}
+}
+
+// grantAchievementReward executes reward command of the claimed achievement via RCON
+func grantAchievementReward(pa models.PlayerAchievement) {
+	err := executeAchievementReward(pa)
+	update := map[string]interface{}{"reward_status": achievementRewardStatus(pa, err), "reward_error": "", "reward_tries": pa.RewardTries + 1}
+	if err != nil {
+		update["reward_error"] = err.Error()
+		log.Printf("[Achievements] reward %d for %s: %v", pa.AchievementID, pa.SteamID, err)
+	}
+	database.DB.Model(&models.PlayerAchievement{}).Where("id = ?", pa.ID).Updates(update)
+}
+
+// achievementRewardStatus returns reward status after a delivery attempt that ended with err
+func achievementRewardStatus(pa models.PlayerAchievement, err error) string {
+	switch {
+	case err == nil:
+		return achievements.RewardOK
+	case rcon.OutcomeUnknown(err):
+		// повтор мог бы выдать награду дважды
+		return achievements.RewardReview
+	case pa.RewardTries+1 >= achievementRewardMaxTries || pa.Achievement == nil || pa.Achievement.RewardCommand == "":
+		return achievements.RewardFailed
+	default:
+		return achievements.RewardPending
+	}
+}
+
+// executeAchievementReward runs reward lines starting at pa.RewardStep and records progress after each line,
+// so a retry doesn't deliver already executed lines again. A line with unknown outcome is left for review.
+func executeAchievementReward(pa models.PlayerAchievement) error {
+	if pa.Achievement == nil || pa.Achievement.RewardCommand == "" {
+		return fmt.Errorf("achievement has no reward command")
+	}
+	var player models.Player
+	database.DB.Select("username").Where("steam_id = ?", pa.SteamID).First(&player)
+	vars := rcon.Vars{"steamid": pa.SteamID, "username": player.Username}
+	if rcon.Suspicious(player.Username) {
+		security.Log(security.KindRconInjection, "achievement", "", player.Username, fmt.Sprintf("achievement %d {{username}}", pa.AchievementID))
+	}
+	cmds, err := rcon.Render(pa.Achievement.RewardCommand, vars)
+	if err != nil {
+		return err
+	}
+	if err := rcon.CheckAllowed(cmds, rconAllowlist()); err != nil {
+		return err
+	}
+	target, err := rcon.ResolveTarget(pa.Achievement.ServerID)
+	if err != nil {
+		return err
+	}
+	_, err = runCommandSteps(cmds, pa.RewardStep, func(cmd string) error {
+		_, err := target.Execute(cmd)
+		return err
+	}, func(step int) {
+		database.DB.Model(&models.PlayerAchievement{}).Where("id = ?", pa.ID).Update("reward_step", step)
+	})
+	return err
+}
+
+// validateAchievement checks rule and reward template (only {{steamid}} / {{username}}, allowlisted commands)
+func validateAchievement(a models.Achievement) error {
+	if err := achievements.Validate(a); err != nil {
+		return err
+	}
+	if strings.TrimSpace(a.RewardCommand) == "" {
+		return nil
+	}
+	if _, err := rcon.Render(a.RewardCommand, rcon.Vars{"steamid": "76561197960265728", "username": "player"}); err != nil {
+		return err
+	}
+	return rcon.CheckAllowed(rcon.Lines(a.RewardCommand), rconAllowlist())
+}
+
+// GetAchievements returns achievements with unlock counts; admin gets disabled ones and reward commands
+// GET /api/achievements
+func GetAchievements(w http.ResponseWriter, r *http.Request) {
+	isAdmin := authpkg.IsAdminRequest(r)
+	query := database.DB.Order("\"order\" ASC, id ASC")
+	if !isAdmin {
+		query = query.Where("enabled = ?", true)
+	}
+	list := []models.Achievement{}
+	if err := query.Find(&list).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var counts []struct {
+		AchievementID uint
+		Count         int64
+	}
+	database.DB.Model(&models.PlayerAchievement{}).Select("achievement_id, COUNT(*) AS count").Group("achievement_id").Scan(&counts)
+	byID := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		byID[c.AchievementID] = c.Count
+	}
+	for i := range list {
+		list[i].Unlocked = byID[list[i].ID]
+		if !isAdmin {
+			list[i].RewardCommand = ""
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// playerAchievements returns unlocked achievements of the player (newest first), reward commands hidden
+func playerAchievements(steamID string) []models.PlayerAchievement {
+	list := []models.PlayerAchievement{}
+	database.DB.Preload("Achievement").Where("steam_id = ?", steamID).Order("unlocked_at DESC, id DESC").Find(&list)
+	for i := range list {
+		if list[i].Achievement != nil {
+			list[i].Achievement.RewardCommand = ""
+		}
+	}
+	return list
+}
+
+// GetPlayerAchievements - GET /api/players/{steamid}/achievements
+func GetPlayerAchievements(w http.ResponseWriter, r *http.Request) {
+	steamID := mux.Vars(r)["steamid"]
+	if !checkSteamID(r, "players/achievements", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(playerAchievements(steamID))
+}
+
+func CreateAchievement(w http.ResponseWriter, r *http.Request) {
+	var a models.Achievement
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.ID = 0
+	if err := validateAchievement(a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := database.DB.Create(&a).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+func UpdateAchievement(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var a models.Achievement
+	if err := database.DB.First(&a, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	prevCommand := a.RewardCommand
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.ID = uint(id)
+	if err := validateAchievement(a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := database.DB.Save(&a).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if a.RewardCommand != prevCommand {
+		// прогресс по строкам относится к старой команде — невыданные награды начинаются заново
+		database.DB.Model(&models.PlayerAchievement{}).
+			Where("achievement_id = ? AND reward_status = ?", a.ID, achievements.RewardPending).
+			Update("reward_step", 0)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// DeleteAchievement deletes achievement together with its unlocks
+func DeleteAchievement(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	database.DB.Where("achievement_id = ?", id).Delete(&models.PlayerAchievement{})
+	if err := database.DB.Delete(&models.Achievement{}, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EvaluateAchievementsNow runs achievement evaluation and reward delivery in background
+// POST /api/admin/achievements/evaluate
+func EvaluateAchievementsNow(w http.ResponseWriter, r *http.Request) {
+	go runAchievements()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"ok": "started"})
+}
+
+// GetAchievementRewardsForReview lists rewards stopped on a line with unknown outcome
+// GET /api/admin/achievements/rewards/review
+func GetAchievementRewardsForReview(w http.ResponseWriter, r *http.Request) {
+	var list []models.PlayerAchievement
+	if err := database.DB.Preload("Achievement").Where("reward_status = ?", achievements.RewardReview).
+		Order("unlocked_at ASC").Find(&list).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	type reviewReward struct {
+		models.PlayerAchievement
+		RewardStep  int    `json:"rewardStep"`
+		RewardError string `json:"rewardError"`
+	}
+	resp := make([]reviewReward, 0, len(list))
+	for _, pa := range list {
+		resp = append(resp, reviewReward{PlayerAchievement: pa, RewardStep: pa.RewardStep, RewardError: pa.RewardError})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ReviewAchievementReward resolves a reward marked for review after the admin checked the server:
+// "retry" runs the line again, "skip" counts it as executed; the rest is delivered on the next run
+// POST /api/admin/achievements/{id}/rewards/{steamid}/review
+func ReviewAchievementReward(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	var req struct {
+		Action string `json:"action"` // retry | skip
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	update := map[string]interface{}{"reward_status": achievements.RewardPending, "reward_error": "", "reward_tries": 0}
+	switch req.Action {
+	case "retry":
+	case "skip":
+		update["reward_step"] = gorm.Expr("reward_step + 1")
+	default:
+		http.Error(w, "action must be retry or skip", http.StatusBadRequest)
+		return
+	}
+	res := database.DB.Model(&models.PlayerAchievement{}).
+		Where("achievement_id = ? AND steam_id = ? AND reward_status = ?", id, vars["steamid"], achievements.RewardReview).
+		Updates(update)
+	if res.Error != nil {
+		http.Error(w, res.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if res.RowsAffected == 0 {
+		http.Error(w, "reward does not need review", http.StatusConflict)
+		return
+	}
+	go runAchievements()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"rewardStatus": achievements.RewardPending})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/achievements"
+	"rust-legacy-site/pkg/rcon"
+)
+
+func TestAchievementRewardStatus(t *testing.T) {
+	reward := &models.Achievement{RewardCommand: "inv.giveplayer {{steamid}} Wood 10"}
+	errDial := fmt.Errorf("rcon connect: %w", errors.New("connection refused"))
+	tests := []struct {
+		name string
+		pa   models.PlayerAchievement
+		err  error
+		want string
+	}{
+		{"delivered", models.PlayerAchievement{Achievement: reward}, nil, achievements.RewardOK},
+		{"dial error retried", models.PlayerAchievement{Achievement: reward}, errDial, achievements.RewardPending},
+		{"last try failed", models.PlayerAchievement{Achievement: reward, RewardTries: achievementRewardMaxTries - 1}, errDial, achievements.RewardFailed},
+		{"no command", models.PlayerAchievement{Achievement: &models.Achievement{}}, errors.New("achievement has no reward command"), achievements.RewardFailed},
+		{"timeout", models.PlayerAchievement{Achievement: reward}, fmt.Errorf("%q: %w", "inv.giveplayer", rcon.ErrTimeout), achievements.RewardReview},
+		{"disconnect on last try", models.PlayerAchievement{Achievement: reward, RewardTries: achievementRewardMaxTries - 1}, rcon.ErrDisconnected, achievements.RewardReview},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := achievementRewardStatus(tt.pa, tt.err); got != tt.want {
+				t.Errorf("achievementRewardStatus(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ func ClearClansAndPlayers(w http.ResponseWriter, r *http.Request) {
 	database.DB.Exec("DELETE FROM players")
 	database.DB.Exec("DELETE FROM clan_members")
 	database.DB.Exec("DELETE FROM clans")
+	statsUpdated()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -67,6 +68,7 @@ func DeleteClanByName(w http.ResponseWriter, r *http.Request) {
 	database.DB.Where("clan_id = ?", id).Delete(&models.ClanMember{})
 	database.DB.Model(&models.Player{}).Where("clan_id = ?", id).Update("clan_id", nil)
 	database.DB.Delete(&models.Clan{}, id)
+	statsUpdated()
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": "clan deleted"})
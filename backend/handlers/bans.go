@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/bans"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+const (
+	bansPerPageMax     = 100
+	banEvidenceMax     = 10
+	banEvidenceURLMax  = 500
+	banReasonMaxLength = 1000
+)
+
+var errAlreadyBanned = errors.New("player is already banned")
+
+type banRequest struct {
+	SteamID  string    `json:"steamId"`
+	Reason   string    `json:"reason"`
+	Duration *string   `json:"duration"` // "12h", "7d", "30m"; "" / "0" / "permanent" = навсегда
+	Evidence *[]string `json:"evidence"`
+}
+
+// parseBanDuration returns ban length; 0 = permanent
+func parseBanDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "0" || s == "permanent" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (examples: 30m, 12h, 7d, permanent)", s)
+	}
+	return d, nil
+}
+
+// normalizeEvidence trims links and keeps only http(s) URLs
+func normalizeEvidence(links []string) ([]string, error) {
+	out := make([]string, 0, len(links))
+	for _, l := range links {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		u, err := url.Parse(l)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(l) > banEvidenceURLMax {
+			return nil, fmt.Errorf("invalid evidence link %q", l)
+		}
+		out = append(out, l)
+	}
+	if len(out) > banEvidenceMax {
+		return nil, fmt.Errorf("too many evidence links (max %d)", banEvidenceMax)
+	}
+	return out, nil
+}
+
+// GetBans returns ban list (newest first). Public: active bans only; ?all=1 — with expired and lifted.
+// GET /api/bans?q=name|steamid&all=1&page=1&limit=50 (total count in X-Total-Count)
+func GetBans(w http.ResponseWriter, r *http.Request) {
+	query := database.DB.Model(&models.Ban{})
+	if r.URL.Query().Get("all") != "1" {
+		query = query.Where("active = ? AND (expires_at IS NULL OR expires_at > ?)", true, time.Now())
+	}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		query = query.Where("steam_id = ? OR username ILIKE ? ESCAPE '\\'", q, "%"+escapeLike(q)+"%")
+	}
+
+	writeBanPage(w, r, query, false)
+}
+
+// writeBanPage writes a page of bans (newest first) with total count in X-Total-Count
+func writeBanPage(w http.ResponseWriter, r *http.Request, query *gorm.DB, withRconErrors bool) {
+	var total int64
+	query.Count(&total)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > bansPerPageMax {
+		limit = 50
+	}
+	list := []models.Ban{}
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&list).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !withRconErrors {
+		for i := range list {
+			list[i].RconError = "" // детали RCON — только в админке
+		}
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetPlayerBan returns active ban and ban history of the player
+// GET /api/bans/{steamid}
+func GetPlayerBan(w http.ResponseWriter, r *http.Request) {
+	steamID := mux.Vars(r)["steamid"]
+	if !checkSteamID(r, "bans", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
+	history := []models.Ban{}
+	database.DB.Where("steam_id = ?", steamID).Order("created_at DESC, id DESC").Find(&history)
+	for i := range history {
+		history[i].RconError = ""
+	}
+	active := bans.Active(steamID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"banned":  active != nil,
+		"ban":     active,
+		"history": history,
+	})
+}
+
+// GetAdminBans - GET /api/admin/bans (same as GetBans, with RCON errors)
+func GetAdminBans(w http.ResponseWriter, r *http.Request) {
+	query := database.DB.Model(&models.Ban{})
+	switch r.URL.Query().Get("status") {
+	case "active":
+		query = query.Where("active = ?", true)
+	case "inactive":
+		query = query.Where("active = ?", false)
+	case "rcon_failed":
+		query = query.Where("rcon_status = ?", bans.RconFailed)
+	}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		query = query.Where("steam_id = ? OR username ILIKE ? ESCAPE '\\'", q, "%"+escapeLike(q)+"%")
+	}
+	writeBanPage(w, r, query, true)
+}
+
+// CreateBan registers the ban; RCON on all servers is applied in the background (rconStatus pending -> ok/failed)
+// POST /api/admin/bans {steamId, reason, duration: "7d", evidence: ["https://..."]}
+func CreateBan(w http.ResponseWriter, r *http.Request) {
+	var req banRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.SteamID = strings.TrimSpace(req.SteamID)
+	if !checkSteamID(r, "admin/bans", req.SteamID) {
+		http.Error(w, "invalid steamId", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > banReasonMaxLength {
+		http.Error(w, fmt.Sprintf("reason is required (max %d characters)", banReasonMaxLength), http.StatusBadRequest)
+		return
+	}
+	ban := models.Ban{SteamID: req.SteamID, Reason: req.Reason, Active: true, RconStatus: bans.RconPending, Evidence: []string{}}
+	if req.Duration != nil {
+		d, err := parseBanDuration(*req.Duration)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if d > 0 {
+			expires := time.Now().Add(d)
+			ban.ExpiresAt = &expires
+		}
+	}
+	if req.Evidence != nil {
+		evidence, err := normalizeEvidence(*req.Evidence)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ban.Evidence = evidence
+	}
+	var player models.Player
+	if database.DB.Select("username").Where("steam_id = ?", req.SteamID).First(&player).Error == nil {
+		ban.Username = player.Username
+	}
+	if claims := getClaims(r); claims != nil {
+		ban.AdminID, ban.AdminName = claims.AdminID, claims.Username
+	}
+	var existing models.Ban
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Проверка с тем же условием, что у уникального индекса (active): истёкший, но ещё не снятый бан снимается здесь
+		if err := bans.ExpirePlayer(tx, ban.SteamID); err != nil {
+			return err
+		}
+		if tx.Where("steam_id = ? AND active = ?", ban.SteamID, true).First(&existing).Error == nil {
+			return errAlreadyBanned
+		}
+		return tx.Create(&ban).Error
+	})
+	if err == errAlreadyBanned {
+		http.Error(w, fmt.Sprintf("player is already banned (ban #%d), edit it instead", existing.ID), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		// Параллельный бан того же игрока упирается в уникальный индекс активных банов
+		if strings.Contains(err.Error(), bans.ActiveIndex) {
+			http.Error(w, "player is already banned", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	bans.Notify()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ban)
+}
+
+// UpdateBan changes reason, evidence or duration (duration is counted from the ban creation)
+// PUT /api/admin/bans/{id}
+func UpdateBan(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var ban models.Ban
+	if err := database.DB.First(&ban, id).Error; err != nil {
+		http.Error(w, "ban not found", http.StatusNotFound)
+		return
+	}
+	var req banRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		if len(reason) > banReasonMaxLength {
+			http.Error(w, fmt.Sprintf("reason is too long (max %d characters)", banReasonMaxLength), http.StatusBadRequest)
+			return
+		}
+		ban.Reason = reason
+	}
+	if req.Evidence != nil {
+		evidence, err := normalizeEvidence(*req.Evidence)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ban.Evidence = evidence
+	}
+	if req.Duration != nil {
+		if !ban.Active {
+			http.Error(w, "ban is not active", http.StatusBadRequest)
+			return
+		}
+		d, err := parseBanDuration(*req.Duration)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ban.ExpiresAt = nil
+		if d > 0 {
+			expires := ban.CreatedAt.Add(d)
+			ban.ExpiresAt = &expires // уже в прошлом — снимется на ближайшем тике
+		}
+	}
+	// Только изменяемые поля: Save перезаписал бы active/rcon_status, изменённые тиком или разбаном после чтения
+	if err := database.DB.Model(&ban).Select("reason", "evidence", "expires_at").Updates(&ban).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ban)
+}
+
+// Unban deactivates the ban; RCON unban on all servers is applied in the background
+// POST /api/admin/bans/{id}/unban {reason}
+func Unban(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var ban models.Ban
+	if err := database.DB.First(&ban, id).Error; err != nil {
+		http.Error(w, "ban not found", http.StatusNotFound)
+		return
+	}
+	if !ban.Active {
+		http.Error(w, "ban is not active", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	now := time.Now()
+	ban.Active, ban.UnbannedAt, ban.UnbanReason = false, &now, strings.TrimSpace(req.Reason)
+	ban.RconStatus, ban.RconError, ban.RconTries = bans.RconPending, "", 0
+	if claims := getClaims(r); claims != nil {
+		ban.UnbannedBy = claims.Username
+	}
+	if err := database.DB.Save(&ban).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	bans.Notify()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ban)
+}
+
+// ResyncBan queues re-applying ban (or unban) on all servers, e.g. after a new server was added
+// POST /api/admin/bans/{id}/resync
+func ResyncBan(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	res := database.DB.Model(&models.Ban{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"rcon_status": bans.RconPending, "rcon_error": "", "rcon_tries": 0})
+	if res.Error != nil {
+		http.Error(w, res.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if res.RowsAffected == 0 {
+		http.Error(w, "ban not found", http.StatusNotFound)
+		return
+	}
+	bans.Notify()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "rconStatus": bans.RconPending})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBanDuration(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"permanent", 0, false},
+		{" Permanent ", 0, false},
+		{"30m", 30 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"7D", 7 * 24 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1d", 0, true},
+		{"-5m", 0, true},
+		{"0s", 0, true},
+		{"d", 0, true},
+		{"1.5d", 0, true},
+		{"7x", 0, true},
+		{"forever", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseBanDuration(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBanDuration(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBanDuration(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,33 +2,54 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
 	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/bans"
+	"rust-legacy-site/pkg/i18n"
 	"rust-legacy-site/pkg/paygate"
-	"rust-legacy-site/pkg/rcon"
 )
 
 func CreateCheckout(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ItemID         uint   `json:"itemId"`
 		SteamID        string `json:"steamId"`
+		Quantity       int    `json:"quantity"`      // default 1
 		PaymentMethod  string `json:"paymentMethod"` // "balance" | "paygate"
 		Email          string `json:"email"`         // optional, for PayGate
+		Lang           string `json:"lang"`          // язык принятых юр. документов (default Accept-Language)
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
 		return
 	}
+	req.SteamID = strings.TrimSpace(req.SteamID)
 	if req.SteamID == "" {
 		http.Error(w, `{"error":"steamId required"}`, http.StatusBadRequest)
 		return
 	}
+	if !checkSteamID(r, "checkout", req.SteamID) {
+		http.Error(w, `{"error":"invalid steamId"}`, http.StatusBadRequest)
+		return
+	}
+	if ban := bans.Active(req.SteamID); ban != nil {
+		http.Error(w, `{"error":"steamId is banned"}`, http.StatusForbidden)
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 1 {
+		http.Error(w, `{"error":"invalid quantity"}`, http.StatusBadRequest)
+		return
+	}
 
 	var item models.ShopItem
 	if err := database.DB.First(&item, req.ItemID).Error; err != nil {
@@ -39,16 +60,34 @@ func CreateCheckout(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"item not available"}`, http.StatusBadRequest)
 		return
 	}
+	if err := validateShopCommand(item.RconCommand); err != nil {
+		log.Printf("[Checkout] item %d has invalid delivery command: %v", item.ID, err)
+		http.Error(w, `{"error":"item delivery is misconfigured"}`, http.StatusServiceUnavailable)
+		return
+	}
+	// Количество попадает в выдачу только через {{quantity}} — иначе оплата N× за одну штуку
+	if limit := shopItemMaxQuantity(item); req.Quantity > limit {
+		http.Error(w, fmt.Sprintf(`{"error":"quantity must be 1-%d for this item"}`, limit), http.StatusBadRequest)
+		return
+	}
 
 	price := item.Price
 	if item.Discount > 0 {
 		price = price * (1 - float64(item.Discount)/100)
 	}
+	price *= float64(req.Quantity)
 	if price <= 0 {
 		http.Error(w, `{"error":"invalid price"}`, http.StatusBadRequest)
 		return
 	}
 
+	// Версии оферты и др. документов, действующие на момент оформления
+	lang := i18n.Normalize(req.Lang)
+	if req.Lang == "" {
+		lang = i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+	legalRevisions := currentLegalRevisionIDs(lang)
+
 	pm := req.PaymentMethod
 	if pm == "" {
 		pm = "paygate"
@@ -86,32 +125,21 @@ func CreateCheckout(w http.ResponseWriter, r *http.Request) {
 		order := models.Order{
 			UserID:        &user.ID,
 			OrderType:     "shop",
-			Status:        "delivered",
+			Status:        "paid", // delivered — после успешной RCON выдачи
 			ItemID:        &item.ID,
 			SteamID:       req.SteamID,
+			Quantity:      req.Quantity,
 			Amount:        price,
 			Currency:      item.Currency,
 			PaymentMethod: "balance",
 			RconCommand:   item.RconCommand,
+			LegalRevisionIDs: legalRevisions,
 		}
 		database.DB.Create(&order)
 
-		// RCON доставка
-		host := os.Getenv("RCON_HOST")
-		port := 28016
-		if p := os.Getenv("RCON_PORT"); p != "" {
-			if v, err := strconv.Atoi(p); err == nil {
-				port = v
-			}
-		}
-		password := os.Getenv("RCON_PASSWORD")
-		if host != "" && password != "" {
-			if _, err := rcon.Execute(host, port, password, item.RconCommand, req.SteamID); err != nil {
-				log.Printf("[Checkout] RCON delivery failed: %v", err)
-			} else {
-				order.RconExecuted = true
-				database.DB.Save(&order)
-			}
+		// RCON доставка (при ошибке — повтор в RetryOrderDeliveries)
+		if err := deliverOrder(&order); err != nil {
+			log.Printf("[Checkout] RCON delivery failed for order %d: %v", order.ID, err)
 		}
 
 		// Transaction
@@ -146,10 +174,12 @@ func CreateCheckout(w http.ResponseWriter, r *http.Request) {
 		Status:        "pending",
 		ItemID:        &item.ID,
 		SteamID:       req.SteamID,
+		Quantity:      req.Quantity,
 		Amount:        price,
 		Currency:      item.Currency,
 		PaymentMethod: "paygate",
 		RconCommand:   item.RconCommand,
+		LegalRevisionIDs: legalRevisions,
 	}
 	if claims := getClaims(r); claims != nil && claims.Role == "user" {
 		order.UserID = &claims.UserID
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
@@ -11,11 +12,39 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// clanJSON - Clan with aggregates from clan_stats
+type clanJSON struct {
+	models.Clan
+	models.ClanStats
+}
+
+// clanSorts - ORDER BY for /api/clans?sort= (clan_stats cs LEFT JOIN)
+var clanSorts = map[string]string{
+	"experience": "clans.experience DESC",
+	"level":      "clans.level DESC, clans.experience DESC",
+	"kills":      "COALESCE(cs.total_kills, 0) DESC",
+	"kd":         "COALESCE(cs.kd, 0) DESC",
+	"farm":       "COALESCE(cs.total_farm, 0) DESC",
+	"raid":       "COALESCE(cs.raid_objects, 0) DESC",
+	"online":     "COALESCE(cs.online_members, 0) DESC",
+}
+
+// GetClans returns clans with aggregates, ranked by sort (одинаковые значения — одно место)
+// GET /api/clans?sort=experience|level|kills|kd|farm|raid|online&members=true
 func GetClans(w http.ResponseWriter, r *http.Request) {
 	var clans []models.Clan
 	withMembers := r.URL.Query().Get("members") == "true"
+	sortKey := r.URL.Query().Get("sort")
+	if sortKey == "" {
+		sortKey = "experience"
+	}
+	order, ok := clanSorts[sortKey]
+	if !ok {
+		http.Error(w, "unknown sort", http.StatusBadRequest)
+		return
+	}
 
-	query := database.DB.Order("experience DESC")
+	query := database.DB.Select("clans.*").Joins("LEFT JOIN clan_stats cs ON cs.clan_id = clans.id").Order(order + ", clans.id ASC")
 	if withMembers {
 		query = query.Preload("Members")
 	}
@@ -25,12 +54,30 @@ func GetClans(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for i := range clans {
-		clans[i].Rank = i + 1
+	var ranks []struct {
+		ID   uint
+		Rank int
+	}
+	database.DB.Raw("SELECT clans.id, RANK() OVER (ORDER BY " + order + ") AS rank FROM clans LEFT JOIN clan_stats cs ON cs.clan_id = clans.id").Scan(&ranks)
+	rankByID := make(map[uint]int, len(ranks))
+	for _, rk := range ranks {
+		rankByID[rk.ID] = rk.Rank
+	}
+	var stats []models.ClanStats
+	database.DB.Find(&stats)
+	statsByID := make(map[uint]models.ClanStats, len(stats))
+	for _, st := range stats {
+		statsByID[st.ClanID] = st
+	}
+
+	resp := make([]clanJSON, 0, len(clans))
+	for _, c := range clans {
+		c.Rank = rankByID[c.ID]
+		resp = append(resp, clanJSON{Clan: c, ClanStats: statsByID[c.ID]})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(clans)
+	json.NewEncoder(w).Encode(resp)
 }
 
 func GetClan(w http.ResponseWriter, r *http.Request) {
@@ -47,35 +94,66 @@ func GetClan(w http.ResponseWriter, r *http.Request) {
 	database.DB.Model(&models.Clan{}).Where("experience > ?", clan.Experience).Count(&rank)
 	clan.Rank = int(rank) + 1
 
-	// Load clan members as players for display + aggregated stats
+	// Load clan members as players for display
 	var memberPlayers []models.Player
 	database.DB.Where("clan_id = ?", id).Find(&memberPlayers)
-	for i := range memberPlayers {
-		var s models.PlayerStats
-		if database.DB.Where("steam_id = ?", memberPlayers[i].SteamID).First(&s).Error == nil {
-			memberPlayers[i].Stats = &s
+	if len(memberPlayers) > 0 {
+		steamIDs := make([]string, 0, len(memberPlayers))
+		for _, m := range memberPlayers {
+			steamIDs = append(steamIDs, m.SteamID)
 		}
-	}
-
-	var totalKills, totalDeaths, totalFarm int
-	for _, m := range memberPlayers {
-		totalKills += m.KilledPlayers
-		totalDeaths += m.Deaths
-		if m.Stats != nil {
-			totalFarm += m.Stats.Wood + m.Stats.Metal + m.Stats.Sulfur
+		var stats []models.PlayerStats
+		database.DB.Where("steam_id IN ?", steamIDs).Find(&stats)
+		statsBySteamID := make(map[string]models.PlayerStats, len(stats))
+		for _, st := range stats {
+			statsBySteamID[st.SteamID] = st
+		}
+		for i := range memberPlayers {
+			if st, ok := statsBySteamID[memberPlayers[i].SteamID]; ok {
+				memberPlayers[i].Stats = &st
+			}
 		}
 	}
 
+	var agg models.ClanStats
+	database.DB.Where("clan_id = ?", clan.ID).First(&agg)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"id": clan.ID, "hexId": clan.HexID, "name": clan.Name, "abbrev": clan.Abbrev,
 		"leaderSteamId": clan.LeaderSteamID, "created": clan.Created, "level": clan.Level,
 		"experience": clan.Experience, "memberCount": clan.MemberCount, "tax": clan.Tax,
 		"motd": clan.MOTD, "rank": clan.Rank, "updatedAt": clan.UpdatedAt,
-		"members": memberPlayers, "totalKills": totalKills, "totalDeaths": totalDeaths, "totalFarm": totalFarm,
+		"members": memberPlayers, "totalKills": agg.TotalKills, "totalDeaths": agg.TotalDeaths, "totalFarm": agg.TotalFarm,
+		"avgKills": agg.AvgKills, "kd": agg.KD, "raidObjects": agg.RaidObjects, "onlineMembers": agg.OnlineMembers,
+		"wood": agg.Wood, "metal": agg.Metal, "sulfur": agg.Sulfur,
 	})
 }
 
+// GetClanHistory returns daily snapshots of clan aggregates.
+// GET /api/clans/{id}/history?days=90
+func GetClanHistory(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var clan models.Clan
+	if err := database.DB.First(&clan, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 || days > 365 {
+		days = 90
+	}
+
+	history := []models.ClanStatHistory{}
+	if err := database.DB.Where("hex_id = ? AND day >= ?", clan.HexID, time.Now().AddDate(0, 0, -days).Format("2006-01-02")).
+		Order("day ASC").Find(&history).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
 func CreateClan(w http.ResponseWriter, r *http.Request) {
 	var clan models.Clan
 	if err := json.NewDecoder(r.Body).Decode(&clan); err != nil {
@@ -88,6 +166,8 @@ func CreateClan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	statsUpdated()
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(clan)
@@ -113,6 +193,7 @@ func UpdateClan(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	statsUpdated()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(clan)
@@ -128,6 +209,7 @@ func DeleteClan(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	statsUpdated()
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -94,15 +94,7 @@ func SendContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var companyEmail string
-	var info models.CompanyInfo
-	if err := database.DB.First(&info).Error; err == nil && info.Email != "" {
-		companyEmail = info.Email
-	}
-	to := email.GetRecipient(companyEmail)
-	if to == "" {
-		to = cfg.From // fallback to sender
-	}
+	to := supportRecipient(cfg)
 
 	if err := email.SendContactForm(cfg, to, req.Name, req.Email, req.Message); err != nil {
 		log.Printf("[Contact] Send failed: %v", err)
@@ -116,3 +108,16 @@ func SendContact(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ContactResponse{OK: true})
 }
+
+// supportRecipient returns staff email: CONTACT_EMAIL, company email or sender as fallback
+func supportRecipient(cfg email.Config) string {
+	var companyEmail string
+	var info models.CompanyInfo
+	if err := database.DB.First(&info).Error; err == nil && info.Email != "" {
+		companyEmail = info.Email
+	}
+	if to := email.GetRecipient(companyEmail); to != "" {
+		return to
+	}
+	return cfg.From
+}
@@ -3,14 +3,49 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/mirrors"
 
 	"github.com/gorilla/mux"
 )
 
+// downloadLinkJSON - DownloadLink with mirror health (pkg/mirrors)
+type downloadLinkJSON struct {
+	models.DownloadLink
+	Status     string     `json:"status"`
+	Size       int64      `json:"size,omitempty"`
+	ChecksumOK *bool      `json:"checksumOk,omitempty"`
+	CheckedAt  *time.Time `json:"checkedAt,omitempty"`
+}
+
+// withMirrorHealth attaches health to links. Unless all, dead and tampered mirrors are hidden
+// and degraded ones are moved to the end (порядок внутри группы сохраняется).
+func withMirrorHealth(links []models.DownloadLink, all bool) []downloadLinkJSON {
+	health := mirrors.HealthByURL()
+	res := make([]downloadLinkJSON, 0, len(links))
+	for _, l := range links {
+		item := downloadLinkJSON{DownloadLink: l, Status: mirrors.StatusUnknown}
+		if h, ok := health[l.URL]; ok {
+			item.Status, item.Size, item.ChecksumOK, item.CheckedAt = h.Status, h.Size, h.ChecksumOK, h.CheckedAt
+		}
+		if !all && !mirrors.Visible(item.Status) {
+			continue
+		}
+		res = append(res, item)
+	}
+	if !all {
+		sort.SliceStable(res, func(i, j int) bool { return mirrors.Rank(res[i].Status) < mirrors.Rank(res[j].Status) })
+	}
+	return res
+}
+
+// GetDownloadLinks returns mirrors; visitors get only working ones (admin gets all)
 func GetDownloadLinks(w http.ResponseWriter, r *http.Request) {
 	serverID := r.URL.Query().Get("serverId")
 	var links []models.DownloadLink
@@ -24,16 +59,25 @@ func GetDownloadLinks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(links)
+	json.NewEncoder(w).Encode(withMirrorHealth(links, authpkg.IsAdminRequest(r)))
 }
 
 func CreateDownloadLink(w http.ResponseWriter, r *http.Request) {
-	var link models.DownloadLink
-	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+	var input struct {
+		models.DownloadLink
+		Weight *int `json:"weight"` // не передан = 1
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if err := database.DB.Create(&link).Error; err != nil {
+	link := input.DownloadLink
+	link.Weight = 1
+	if input.Weight != nil && *input.Weight >= 0 {
+		link.Weight = *input.Weight
+	}
+	// Select("*"): иначе GORM подставит default:1 вместо явного weight=0
+	if err := database.DB.Select("*").Omit("id").Create(&link).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -72,3 +116,77 @@ func DeleteDownloadLink(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// mirrorHealthJSON - mirror state with recent checks for admin
+type mirrorHealthJSON struct {
+	models.MirrorHealth
+	LinkID *uint                `json:"linkId,omitempty"`
+	Label  string               `json:"label"`
+	Uptime float64              `json:"uptime"` // доля успешных проверок за неделю, 0..1
+	Recent []models.MirrorCheck `json:"recent"`
+}
+
+// GetMirrorHealth returns health of all download mirrors with recent checks
+// GET /api/admin/download-links/health
+func GetMirrorHealth(w http.ResponseWriter, r *http.Request) {
+	var rows []models.MirrorHealth
+	if err := database.DB.Order("server_info_id ASC, id ASC").Find(&rows).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var links []models.DownloadLink
+	database.DB.Find(&links)
+	byURL := make(map[string]models.DownloadLink)
+	for _, l := range links {
+		byURL[l.URL] = l
+	}
+
+	// аптайм и последние 20 проверок всех зеркал - двумя запросами, а не по три на зеркало
+	var uptime []struct {
+		URL   string
+		Total int64
+		OK    int64
+	}
+	database.DB.Model(&models.MirrorCheck{}).
+		Select("url, COUNT(*) AS total, COUNT(*) FILTER (WHERE ok) AS ok").
+		Group("url").Scan(&uptime)
+	uptimeByURL := make(map[string]float64, len(uptime))
+	for _, u := range uptime {
+		if u.Total > 0 {
+			uptimeByURL[u.URL] = float64(u.OK) / float64(u.Total)
+		}
+	}
+	var recent []models.MirrorCheck
+	database.DB.Raw(`SELECT id, url, ok, http_status, size, latency_ms, error, created_at FROM (
+		SELECT *, ROW_NUMBER() OVER (PARTITION BY url ORDER BY created_at DESC) AS rn FROM mirror_checks
+	) t WHERE rn <= 20 ORDER BY url, created_at DESC`).Scan(&recent)
+	recentByURL := make(map[string][]models.MirrorCheck)
+	for _, c := range recent {
+		recentByURL[c.URL] = append(recentByURL[c.URL], c)
+	}
+
+	resp := make([]mirrorHealthJSON, 0, len(rows))
+	for _, h := range rows {
+		item := mirrorHealthJSON{MirrorHealth: h, Label: "ServerInfo.downloadUrl", Uptime: uptimeByURL[h.URL], Recent: recentByURL[h.URL]}
+		if l, ok := byURL[h.URL]; ok {
+			id := l.ID
+			item.LinkID, item.Label = &id, l.Label
+		}
+		if item.Recent == nil {
+			item.Recent = []models.MirrorCheck{}
+		}
+		resp = append(resp, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CheckMirrorsNow starts mirror check in background
+// POST /api/admin/download-links/check
+func CheckMirrorsNow(w http.ResponseWriter, r *http.Request) {
+	go mirrors.Run()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"ok": "started"})
+}
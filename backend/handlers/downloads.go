@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/geoip"
+	"rust-legacy-site/pkg/mirrors"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+const downloadClicksKeepDays = 365
+
+// pickDownloadLink chooses mirror by weight among the healthiest visible ones
+// (ok, затем unknown, затем degraded). Links with weight 0 are never picked automatically.
+func pickDownloadLink(links []downloadLinkJSON) *downloadLinkJSON {
+	best := -1
+	for _, l := range links {
+		if l.Weight > 0 && l.URL != "" && (best < 0 || mirrors.Rank(l.Status) < best) {
+			best = mirrors.Rank(l.Status)
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	var group []downloadLinkJSON
+	total := 0
+	for _, l := range links {
+		if l.Weight > 0 && l.URL != "" && mirrors.Rank(l.Status) == best {
+			group = append(group, l)
+			total += l.Weight
+		}
+	}
+	n := rand.Intn(total)
+	for i := range group {
+		if n < group[i].Weight {
+			return &group[i]
+		}
+		n -= group[i].Weight
+	}
+	return &group[len(group)-1]
+}
+
+// downloadTarget resolves where /api/download/{linkId} leads: the requested mirror if it is healthy,
+// otherwise weighted pick among server mirrors, otherwise ServerInfo.DownloadURL.
+func downloadTarget(requested models.DownloadLink, serverID uint) (string, models.DownloadLink) {
+	if requested.ID != 0 {
+		serverID = requested.ServerInfoID
+		if l := withMirrorHealth([]models.DownloadLink{requested}, false); len(l) == 1 && l[0].URL != "" {
+			return requested.URL, requested
+		}
+	}
+
+	var links []models.DownloadLink
+	query := database.DB.Order("\"order\" ASC")
+	if serverID != 0 {
+		query = query.Where("server_info_id = ?", serverID)
+	}
+	query.Find(&links)
+	if l := pickDownloadLink(withMirrorHealth(links, false)); l != nil {
+		return l.URL, l.DownloadLink
+	}
+
+	var srv models.ServerInfo
+	query = database.DB.Order("id ASC")
+	if serverID != 0 {
+		query = query.Where("id = ?", serverID)
+	}
+	if query.First(&srv).Error == nil && srv.DownloadURL != "" {
+		return srv.DownloadURL, models.DownloadLink{ServerInfoID: srv.ID}
+	}
+	return "", models.DownloadLink{}
+}
+
+// referrerDomain keeps only host of Referer header (без пути и параметров)
+func referrerDomain(r *http.Request) string {
+	u, err := url.Parse(r.Header.Get("Referer"))
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	return host
+}
+
+// isBotRequest - превью ссылок в мессенджерах и краулеры не считаем скачиваниями
+func isBotRequest(r *http.Request) bool {
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return true
+	}
+	for _, s := range []string{"bot", "crawler", "spider", "preview"} {
+		if strings.Contains(ua, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadRedirect redirects to the client archive and records anonymized click
+// (время, зеркало, домен реферера, страна). IP and User-Agent are not stored.
+// GET /api/download/{linkId} — конкретное зеркало (если оно недоступно, выбирается другое)
+// GET /api/download/auto?serverId=1 — зеркало по весам
+func DownloadRedirect(w http.ResponseWriter, r *http.Request) {
+	var requested models.DownloadLink
+	var serverID uint
+	if id, err := strconv.Atoi(mux.Vars(r)["linkId"]); err == nil {
+		if database.DB.First(&requested, id).Error != nil {
+			requested = models.DownloadLink{}
+		}
+	}
+	if id, err := strconv.Atoi(r.URL.Query().Get("serverId")); err == nil && id > 0 {
+		serverID = uint(id)
+	}
+
+	target, link := downloadTarget(requested, serverID)
+	if target == "" {
+		http.Redirect(w, r, SiteURL+"/how-to-start", http.StatusFound)
+		return
+	}
+
+	if !isBotRequest(r) {
+		click := models.DownloadClick{
+			LinkID:          link.ID,
+			RequestedLinkID: requested.ID,
+			ServerInfoID:    link.ServerInfoID,
+			Referrer:        referrerDomain(r),
+			Country:         geoip.Country(getClientIP(r)),
+		}
+		go func() {
+			if err := database.DB.Create(&click).Error; err != nil {
+				log.Printf("[Downloads] failed to record click: %v", err)
+			}
+		}()
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+type downloadDayStat struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+type downloadGroupStat struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+type downloadLinkStat struct {
+	LinkID uint   `json:"linkId"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+	Count  int64  `json:"count"`
+}
+
+// GetDownloadStats returns download clicks aggregated by day, mirror, country and referrer
+// GET /api/admin/downloads/stats?days=30&serverId=1
+func GetDownloadStats(w http.ResponseWriter, r *http.Request) {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 || days > downloadClicksKeepDays {
+		days = 30
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	base := func() *gorm.DB {
+		q := database.DB.Model(&models.DownloadClick{}).Where("created_at >= ?", since)
+		if id, err := strconv.Atoi(r.URL.Query().Get("serverId")); err == nil && id > 0 {
+			q = q.Where("server_info_id = ?", id)
+		}
+		return q
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	daily := []downloadDayStat{}
+	base().Select("to_char(date_trunc('day', created_at), 'YYYY-MM-DD') AS day, COUNT(*) AS count").
+		Group("day").Order("day ASC").Scan(&daily)
+	countries := []downloadGroupStat{}
+	base().Select("COALESCE(NULLIF(country, ''), '??') AS key, COUNT(*) AS count").
+		Group("key").Order("count DESC").Limit(50).Scan(&countries)
+	referrers := []downloadGroupStat{}
+	base().Select("COALESCE(NULLIF(referrer, ''), '(direct)') AS key, COUNT(*) AS count").
+		Group("key").Order("count DESC").Limit(50).Scan(&referrers)
+
+	var byLink []struct {
+		LinkID uint
+		Count  int64
+	}
+	base().Select("link_id, COUNT(*) AS count").Group("link_id").Order("count DESC").Scan(&byLink)
+	var links []models.DownloadLink
+	database.DB.Find(&links)
+	linkByID := make(map[uint]models.DownloadLink, len(links))
+	for _, l := range links {
+		linkByID[l.ID] = l
+	}
+	mirrorsStat := make([]downloadLinkStat, 0, len(byLink))
+	for _, row := range byLink {
+		item := downloadLinkStat{LinkID: row.LinkID, Label: "ServerInfo.downloadUrl", Count: row.Count}
+		if l, ok := linkByID[row.LinkID]; ok {
+			item.Label, item.URL = l.Label, l.URL
+		} else if row.LinkID != 0 {
+			item.Label = "deleted #" + strconv.Itoa(int(row.LinkID))
+		}
+		mirrorsStat = append(mirrorsStat, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":      days,
+		"total":     total,
+		"daily":     daily,
+		"mirrors":   mirrorsStat,
+		"countries": countries,
+		"referrers": referrers,
+	})
+}
+
+// PruneDownloadClicks deletes clicks older than a year. Called daily from main.
+func PruneDownloadClicks() {
+	res := database.DB.Where("created_at < ?", time.Now().AddDate(0, 0, -downloadClicksKeepDays)).Delete(&models.DownloadClick{})
+	if res.Error != nil {
+		log.Printf("[Downloads] prune: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("[Downloads] pruned %d old clicks", res.RowsAffected)
+	}
+}
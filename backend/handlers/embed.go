@@ -1,13 +1,24 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"html/template"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+
+	"github.com/gorilla/mux"
 )
 
 func init() {
@@ -19,87 +30,394 @@ func init() {
 // SiteURL is the canonical site URL for OG tags (override via SITE_URL env)
 var SiteURL = "https://rustlegacy.online"
 
-// EmbedPreview returns HTML with dynamic Open Graph meta for Discord, VK, etc.
-// Shows current Classic online + download link. Formatted for clean Discord/VK preview.
-func EmbedPreview(w http.ResponseWriter, r *http.Request) {
-	online := getClassicOnlineCount()
-	downloadURL := getPrimaryDownloadURL()
+// Embed page size for oEmbed iframe
+const (
+	embedWidth  = 600
+	embedHeight = 220
+)
 
-	// Short description for Discord (truncates ~200 chars)
-	descShort := fmt.Sprintf("Онлайн Classic: %d | Скачать: %s", online, downloadURL)
-	if online == 0 {
-		descShort = fmt.Sprintf("Rust Legacy сервер. Скачать: %s", downloadURL)
-	}
-	if len(descShort) > 180 {
-		descShort = descShort[:177] + "..."
-	}
+const embedTemplatesKey = "embed_templates"
+
+var embedLanguages = []string{"ru", "en"}
+
+// embedData - values available in embed templates
+type embedData struct {
+	Lang        string
+	Locale      string // ru_RU | en_US
+	AltLocale   string
+	SiteURL     string
+	PageURL     string
+	ImageURL    string
+	OEmbedURL   string
+	Title       string
+	Description string
+	ServerName  string
+	ServerType  string
+	Online      int
+	MaxPlayers  int
+	IsOnline    bool
+	DownloadURL string
+	News        *embedNews
+	T           embedStrings
+}
+
+type embedNews struct {
+	Title       string
+	URL         string
+	PublishedAt time.Time
+}
 
-	// Escape for HTML
-	descEsc := strings.ReplaceAll(descShort, "&", "&amp;")
-	descEsc = strings.ReplaceAll(descEsc, "<", "&lt;")
-	descEsc = strings.ReplaceAll(descEsc, ">", "&gt;")
-	descEsc = strings.ReplaceAll(descEsc, "\"", "&quot;")
+// embedStrings - localized texts of the embed page and its description (в шаблоне: {{.T.Online}} и т.д.)
+type embedStrings struct {
+	Online         string
+	Offline        string
+	News           string
+	Download       string
+	DownloadClient string
+	ServerDesc     string // описание, когда сервер офлайн
+}
 
-	html := fmt.Sprintf(`<!DOCTYPE html>
-<html lang="ru">
+var embedTexts = map[string]embedStrings{
+	"ru": {Online: "Онлайн", Offline: "Сервер недоступен", News: "Новости", Download: "Скачать", DownloadClient: "Скачать клиент", ServerDesc: "Rust Legacy сервер"},
+	"en": {Online: "Online", Offline: "Server is offline", News: "News", Download: "Download", DownloadClient: "Download client", ServerDesc: "Rust Legacy server"},
+}
+
+// defaultEmbedTemplate - one template for all languages, texts come from embedData.T
+const defaultEmbedTemplate = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
 <head>
 <meta charset="utf-8">
 <meta name="viewport" content="width=device-width, initial-scale=1">
 <meta name="theme-color" content="#e67e22">
-<title>Rust Legacy Online — Онлайн: %d | Скачать</title>
-<meta name="description" content="%s">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<link rel="alternate" type="application/json+oembed" href="{{.OEmbedURL}}" title="{{.Title}}">
 
 <meta property="og:type" content="website">
 <meta property="og:site_name" content="Rust Legacy">
-<meta property="og:title" content="Rust Legacy Online">
-<meta property="og:description" content="%s">
-<meta property="og:url" content="%s/">
-<meta property="og:image" content="%s/og-image.png">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.PageURL}}">
+<meta property="og:image" content="{{.ImageURL}}">
 <meta property="og:image:width" content="1200">
 <meta property="og:image:height" content="630">
-<meta property="og:locale" content="ru_RU">
-<meta property="og:locale:alternate" content="en_US">
+<meta property="og:locale" content="{{.Locale}}">
+<meta property="og:locale:alternate" content="{{.AltLocale}}">
 
 <meta name="twitter:card" content="summary_large_image">
-<meta name="twitter:title" content="Rust Legacy Online">
-<meta name="twitter:description" content="%s">
-<meta name="twitter:image" content="%s/og-image.png">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
 </head>
 <body style="margin:0;padding:2rem;font-family:system-ui,sans-serif;background:#0f0c09;color:#f5f0eb;">
 <div style="max-width:600px;">
-<h1 style="margin:0 0 0.5rem;font-size:1.8rem;color:#e67e22;">Rust Legacy</h1>
-<h2 style="margin:0 0 1rem;font-size:1.2rem;font-weight:500;color:#d4cfc9;">Rust Legacy Online</h2>
-<p style="margin:0 0 1rem;color:#a89f96;font-size:1rem;">Онлайн Classic: %d</p>
-<p style="margin:0;"><a href="%s" style="color:#e67e22;">Скачать клиент</a></p>
+<h1 style="margin:0 0 0.5rem;font-size:1.8rem;color:#e67e22;">{{.ServerName}}</h1>
+<p style="margin:0 0 1rem;color:#a89f96;font-size:1rem;">{{if .IsOnline}}{{.T.Online}}: {{.Online}} / {{.MaxPlayers}}{{else}}{{.T.Offline}}{{end}}</p>
+{{with .News}}<p style="margin:0 0 1rem;font-size:1rem;">{{$.T.News}}: <a href="{{.URL}}" style="color:#d4cfc9;">{{.Title}}</a></p>
+{{end}}<p style="margin:0;"><a href="{{.DownloadURL}}" style="color:#e67e22;">{{.T.DownloadClient}}</a></p>
 </div>
 </body>
-</html>`, online, descEsc, descEsc, SiteURL, SiteURL, descEsc, SiteURL, online, downloadURL)
+</html>`
+
+// defaultEmbedTemplates - default per language (one template), so admin can override each language separately
+var defaultEmbedTemplates = map[string]string{
+	"ru": defaultEmbedTemplate,
+	"en": defaultEmbedTemplate,
+}
+
+// Parsed templates cache; reset on admin update
+var (
+	embedTemplates   map[string]*template.Template
+	embedTemplatesMu sync.Mutex
+)
 
+// EmbedPreview returns HTML with dynamic Open Graph meta for Discord, VK, etc.
+// GET /api/embed - Classic server (nginx rewrites bot requests to "/" here)
+func EmbedPreview(w http.ResponseWriter, r *http.Request) {
+	renderEmbed(w, r, "classic")
+}
+
+// EmbedServerPreview returns embed page for the server type.
+// GET /api/embed/{serverType}?lang=en|ru
+func EmbedServerPreview(w http.ResponseWriter, r *http.Request) {
+	renderEmbed(w, r, mux.Vars(r)["serverType"])
+}
+
+func renderEmbed(w http.ResponseWriter, r *http.Request, serverType string) {
+	lang := embedLang(r.URL.Query().Get("lang"))
+	data, err := buildEmbedData(serverType, lang)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	tpl, err := embedTemplate(lang)
+	if err != nil {
+		log.Printf("[Embed] template %s: %v", lang, err)
+		http.Error(w, "embed template error", http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		log.Printf("[Embed] template %s: %v", lang, err)
+		http.Error(w, "embed template error", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(html))
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Write(buf.Bytes())
+}
+
+func embedLang(lang string) string {
+	if lang == "en" {
+		return "en"
+	}
+	return "ru"
+}
+
+func buildEmbedData(serverType, lang string) (*embedData, error) {
+	var srv models.ServerInfo
+	if err := database.DB.Where("type = ?", serverType).Order("sort_order ASC, id ASC").First(&srv).Error; err != nil {
+		return nil, fmt.Errorf("server %q not found", serverType)
+	}
+	online, maxPlayers, isOnline := getServerOnline(serverType)
+	if maxPlayers == 0 {
+		maxPlayers = srv.MaxPlayers
+	}
+
+	q := url.Values{"lang": {lang}}
+	d := &embedData{
+		Lang:        lang,
+		Locale:      "ru_RU",
+		AltLocale:   "en_US",
+		SiteURL:     SiteURL,
+		PageURL:     SiteURL + "/",
+		ImageURL:    SiteURL + "/api/embed/image.png?" + q.Encode(),
+		ServerName:  srv.Name,
+		ServerType:  serverType,
+		Online:      online,
+		MaxPlayers:  maxPlayers,
+		IsOnline:    isOnline,
+		DownloadURL: getPrimaryDownloadURL(),
+		T:           embedTexts[lang],
+	}
+	if lang == "en" {
+		d.Locale, d.AltLocale = "en_US", "ru_RU"
+	}
+	pageURL := SiteURL + "/api/embed/" + url.PathEscape(serverType) + "?" + q.Encode()
+	d.OEmbedURL = SiteURL + "/api/oembed?" + url.Values{"url": {pageURL}, "format": {"json"}}.Encode()
+
+	var news models.News
+	if visibleNews(database.DB).Where("language = ?", lang).Order("published_at DESC").First(&news).Error == nil {
+		d.News = &embedNews{Title: news.Title, URL: newsURL(news), PublishedAt: news.PublishedAt}
+	}
+
+	d.Title = srv.Name + " — Rust Legacy Online"
+	desc := fmt.Sprintf("%s: %d/%d | %s: %s", d.T.Online, online, maxPlayers, d.T.Download, d.DownloadURL)
+	if !isOnline {
+		desc = fmt.Sprintf("%s. %s: %s", d.T.ServerDesc, d.T.Download, d.DownloadURL)
+	}
+	if d.News != nil {
+		desc += " | " + d.T.News + ": " + d.News.Title
+	}
+	// Discord обрезает описание ~200 символов
+	if runes := []rune(desc); len(runes) > 180 {
+		desc = string(runes[:177]) + "..."
+	}
+	d.Description = desc
+	return d, nil
+}
+
+// embedTemplate returns parsed template for lang (custom from settings or default)
+func embedTemplate(lang string) (*template.Template, error) {
+	embedTemplatesMu.Lock()
+	defer embedTemplatesMu.Unlock()
+	if embedTemplates == nil {
+		embedTemplates = make(map[string]*template.Template)
+	}
+	if tpl, ok := embedTemplates[lang]; ok {
+		return tpl, nil
+	}
+	src := loadEmbedTemplates()[lang]
+	tpl, err := template.New("embed_" + lang).Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	embedTemplates[lang] = tpl
+	return tpl, nil
 }
 
-func getClassicOnlineCount() int {
+// loadEmbedTemplates returns templates per language: custom from settings, default otherwise
+func loadEmbedTemplates() map[string]string {
+	custom := make(map[string]string)
+	var setting models.Setting
+	if database.DB.Where("key = ?", embedTemplatesKey).First(&setting).Error == nil {
+		json.Unmarshal([]byte(setting.Value), &custom)
+	}
+	res := make(map[string]string, len(embedLanguages))
+	for _, lang := range embedLanguages {
+		if tpl := strings.TrimSpace(custom[lang]); tpl != "" {
+			res[lang] = custom[lang]
+		} else {
+			res[lang] = defaultEmbedTemplates[lang]
+		}
+	}
+	return res
+}
+
+// GetEmbedTemplates returns current and default embed templates (admin only)
+// GET /api/admin/embed/templates
+func GetEmbedTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": loadEmbedTemplates(),
+		"defaults":  defaultEmbedTemplates,
+	})
+}
+
+// UpdateEmbedTemplates saves custom embed templates (admin only). Empty template resets language to default.
+// PUT /api/admin/embed/templates - body: { "ru": "<!DOCTYPE html>...", "en": "..." }
+func UpdateEmbedTemplates(w http.ResponseWriter, r *http.Request) {
+	var input map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sample := &embedData{
+		Lang: "en", SiteURL: SiteURL, ServerName: "Rust Legacy Classic", ServerType: "classic",
+		Online: 10, MaxPlayers: 100, IsOnline: true,
+		News: &embedNews{Title: "News", URL: SiteURL + "/news", PublishedAt: time.Now()},
+		T:    embedTexts["en"],
+	}
+	custom := make(map[string]string)
+	for lang, src := range input {
+		if embedLang(lang) != lang {
+			http.Error(w, fmt.Sprintf("unsupported language %q", lang), http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(src) == "" {
+			continue
+		}
+		tpl, err := template.New("embed_" + lang).Parse(src)
+		if err == nil {
+			err = tpl.Execute(io.Discard, sample)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("template %s: %v", lang, err), http.StatusBadRequest)
+			return
+		}
+		custom[lang] = src
+	}
+	raw, err := json.Marshal(custom)
+	if err != nil {
+		http.Error(w, "Marshal error", http.StatusInternalServerError)
+		return
+	}
+	var s models.Setting
+	if database.DB.Where("key = ?", embedTemplatesKey).First(&s).Error != nil {
+		s = models.Setting{Key: embedTemplatesKey, Value: string(raw)}
+		if database.DB.Create(&s).Error != nil {
+			http.Error(w, "Failed to save", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		s.Value = string(raw)
+		if database.DB.Save(&s).Error != nil {
+			http.Error(w, "Failed to save", http.StatusInternalServerError)
+			return
+		}
+	}
+	embedTemplatesMu.Lock()
+	embedTemplates = nil
+	embedTemplatesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"ok": "saved"})
+}
+
+// OEmbed implements oEmbed (https://oembed.com) for site and embed page URLs.
+// GET /api/oembed?url=https://rustlegacy.online/api/embed/classic?lang=en&format=json&maxwidth=500
+func OEmbed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if f := q.Get("format"); f != "" && f != "json" {
+		http.Error(w, "only json format is supported", http.StatusNotImplemented)
+		return
+	}
+	target, err := url.Parse(q.Get("url"))
+	site, _ := url.Parse(SiteURL)
+	if err != nil || target.Host == "" || site == nil || !strings.EqualFold(strings.TrimPrefix(target.Host, "www."), strings.TrimPrefix(site.Host, "www.")) {
+		http.Error(w, "url is not on this site", http.StatusNotFound)
+		return
+	}
+	serverType := "classic"
+	if rest, ok := strings.CutPrefix(target.Path, "/api/embed/"); ok && rest != "" && !strings.Contains(rest, "/") {
+		serverType = rest
+	}
+	lang := embedLang(target.Query().Get("lang"))
+	data, err := buildEmbedData(serverType, lang)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	width, height := embedWidth, embedHeight
+	if mw := parsePositiveInt(q.Get("maxwidth")); mw > 0 && mw < width {
+		width = mw
+	}
+	if mh := parsePositiveInt(q.Get("maxheight")); mh > 0 && mh < height {
+		height = mh
+	}
+	frameURL := SiteURL + "/api/embed/" + url.PathEscape(serverType) + "?" + url.Values{"lang": {lang}}.Encode()
+	html := fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0" scrolling="no" title="%s"></iframe>`,
+		template.HTMLEscapeString(frameURL), width, height, template.HTMLEscapeString(data.Title))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version":          "1.0",
+		"type":             "rich",
+		"title":            data.Title,
+		"provider_name":    "Rust Legacy",
+		"provider_url":     SiteURL,
+		"cache_age":        60,
+		"html":             html,
+		"width":            width,
+		"height":           height,
+		"thumbnail_url":    data.ImageURL,
+		"thumbnail_width":  1200,
+		"thumbnail_height": 630,
+	})
+}
+
+func parsePositiveInt(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// getServerOnline sums current/max players of servers of the type from statusCache
+func getServerOnline(serverType string) (online, maxPlayers int, isOnline bool) {
 	InitServerStatusCache()
 	statusCacheMu.RLock()
-	statuses := statusCache["classic"]
+	statuses := statusCache["all"]
 	statusCacheMu.RUnlock()
 
-	total := 0
 	for _, s := range statuses {
-		total += s.CurrentPlayers
+		if s.ServerType != serverType {
+			continue
+		}
+		online += s.CurrentPlayers
+		maxPlayers += s.MaxPlayers
+		isOnline = isOnline || s.IsOnline
 	}
-	return total
+	return online, maxPlayers, isOnline
 }
 
+// getPrimaryDownloadURL - tracked link, mirror is chosen by weights on click
 func getPrimaryDownloadURL() string {
-	var link models.DownloadLink
-	if err := database.DB.Order("\"order\" ASC").First(&link).Error; err == nil && link.URL != "" {
-		return link.URL
-	}
-	var srv models.ServerInfo
-	if err := database.DB.First(&srv).Error; err == nil && srv.DownloadURL != "" {
-		return srv.DownloadURL
+	if target, _ := downloadTarget(models.DownloadLink{}, 0); target != "" {
+		return SiteURL + "/api/download/auto"
 	}
 	return SiteURL + "/how-to-start"
 }
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/ogimage"
+)
+
+// ogImageTTL - картинку перерисовываем не чаще раза в минуту (онлайн в statusCache обновляется раз в 10 сек)
+const ogImageTTL = time.Minute
+
+type ogImageEntry struct {
+	data       []byte
+	etag       string
+	renderedAt time.Time
+}
+
+var (
+	ogImageCache   = make(map[string]ogImageEntry) // by lang
+	ogImageCacheMu sync.Mutex
+)
+
+// EmbedImage returns dynamic Open Graph PNG: servers online, next wipe countdown, theme colours.
+// GET /api/embed/image.png?lang=ru|en
+func EmbedImage(w http.ResponseWriter, r *http.Request) {
+	lang := r.URL.Query().Get("lang")
+	if lang != "en" {
+		lang = "ru"
+	}
+
+	ogImageCacheMu.Lock()
+	entry, ok := ogImageCache[lang]
+	if !ok || time.Since(entry.renderedAt) > ogImageTTL {
+		data, err := renderOgImage(lang)
+		if err != nil {
+			ogImageCacheMu.Unlock()
+			log.Printf("[Embed] og image render failed: %v", err)
+			http.Error(w, "render failed", http.StatusInternalServerError)
+			return
+		}
+		sum := sha1.Sum(data)
+		entry = ogImageEntry{data: data, etag: `"` + hex.EncodeToString(sum[:8]) + `"`, renderedAt: time.Now()}
+		ogImageCache[lang] = entry
+	}
+	ogImageCacheMu.Unlock()
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ogImageTTL.Seconds())))
+	w.Header().Set("ETag", entry.etag)
+	if r.Header.Get("If-None-Match") == entry.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(entry.data)
+}
+
+func renderOgImage(lang string) ([]byte, error) {
+	InitServerStatusCache()
+	statusCacheMu.RLock()
+	statuses := statusCache["all"]
+	statusCacheMu.RUnlock()
+
+	servers := make([]ogimage.Server, 0, len(statuses))
+	for _, s := range statuses {
+		servers = append(servers, ogimage.Server{
+			Name:    s.ServerName,
+			Current: s.CurrentPlayers,
+			Max:     s.MaxPlayers,
+			Online:  s.IsOnline,
+		})
+	}
+
+	var palette ogimage.Palette
+	var theme models.Theme
+	if database.DB.Where("is_active = ?", true).First(&theme).Error == nil {
+		palette = ogimage.Palette{
+			Background:    theme.BackgroundColor,
+			Card:          theme.CardBackground,
+			Primary:       theme.PrimaryColor,
+			Accent:        theme.AccentColor,
+			Text:          theme.TextPrimary,
+			TextSecondary: theme.TextSecondary,
+			Border:        theme.BorderColor,
+		}
+	}
+
+	next, full := loadSiteConfig().nextWipe(time.Now())
+	return ogimage.Render(ogimage.Data{
+		Title:    "Rust Legacy Online",
+		Subtitle: strings.TrimPrefix(strings.TrimPrefix(SiteURL, "https://"), "http://"),
+		Servers:  servers,
+		Footer:   wipeCountdownText(time.Until(next), full, lang),
+		Colors:   palette,
+	})
+}
+
+// wipeCountdownText: "Full wipe in 2d 4h 15m" / "Вайп через 2д 4ч 15м"
+func wipeCountdownText(d time.Duration, full bool, lang string) string {
+	if d < 0 {
+		d = 0
+	}
+	mins := int(d.Minutes())
+	days, hours, m := mins/(24*60), mins/60%24, mins%60
+	units := [3]string{"d", "h", "m"}
+	label := "Wipe in"
+	if full {
+		label = "Full wipe in"
+	}
+	if lang == "ru" {
+		units = [3]string{"д", "ч", "м"}
+		label = "Вайп через"
+		if full {
+			label = "Глобальный вайп через"
+		}
+	}
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%s %d%s %d%s %d%s", label, days, units[0], hours, units[1], m, units[2])
+	case hours > 0:
+		return fmt.Sprintf("%s %d%s %d%s", label, hours, units[1], m, units[2])
+	default:
+		return fmt.Sprintf("%s %d%s", label, m, units[2])
+	}
+}
@@ -7,23 +7,25 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
 
 	"github.com/gorilla/mux"
 )
 
 func GetFeatures(w http.ResponseWriter, r *http.Request) {
 	var features []models.Feature
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 
 	query := database.DB.Order("\"order\" ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 
 	if err := query.Find(&features).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	features = i18n.Resolve(features, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(features)
@@ -36,6 +38,7 @@ func CreateFeature(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ensureTranslationGroup(&feature.TranslationGroup)
 	if err := database.DB.Create(&feature).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -61,6 +64,7 @@ func UpdateFeature(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ensureTranslationGroup(&feature.TranslationGroup)
 	if err := database.DB.Save(&feature).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -7,23 +7,26 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
+	"rust-legacy-site/pkg/sanitize"
 
 	"github.com/gorilla/mux"
 )
 
 func GetHowToStartSteps(w http.ResponseWriter, r *http.Request) {
 	var steps []models.HowToStartStep
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 
 	query := database.DB.Order("step_number ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 
 	if err := query.Find(&steps).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	steps = i18n.Resolve(steps, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(steps)
@@ -36,6 +39,8 @@ func CreateHowToStartStep(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	step.Content = sanitize.HTML(step.Content)
+	ensureTranslationGroup(&step.TranslationGroup)
 	if err := database.DB.Create(&step).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -61,6 +66,8 @@ func UpdateHowToStartStep(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	step.Content = sanitize.HTML(step.Content)
+	ensureTranslationGroup(&step.TranslationGroup)
 	if err := database.DB.Save(&step).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
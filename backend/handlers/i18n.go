@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"rust-legacy-site/database"
+	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/i18n"
+)
+
+// contentLang returns language for content lists: ?lang= (all = every language), otherwise
+// all languages for admin requests (панель редактирует все версии) and Accept-Language for visitors.
+// "" means no language filter.
+func contentLang(w http.ResponseWriter, r *http.Request) string {
+	w.Header().Add("Vary", "Accept-Language")
+	lang := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("lang")))
+	if lang == "all" {
+		return ""
+	}
+	if lang == "" {
+		if authpkg.IsAdminRequest(r) {
+			return ""
+		}
+		lang = i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+	} else {
+		lang = i18n.Normalize(lang)
+	}
+	w.Header().Set("Content-Language", lang)
+	return lang
+}
+
+// ensureTranslationGroup gives a new row its own group unless it was linked to an existing one
+func ensureTranslationGroup(group *string) {
+	*group = strings.TrimSpace(*group)
+	if *group == "" {
+		*group = i18n.NewGroup()
+	}
+}
+
+// translationTables - content types for the missing translations report
+var translationTables = []struct {
+	Type   string
+	Table  string
+	Column string // заголовок записи
+}{
+	{"news", "news", "title"},
+	{"features", "features", "title"},
+	{"rules", "rules", "title"},
+	{"how-to-start", "how_to_start_steps", "title"},
+	{"server-details", "server_details", "title"},
+	{"plugins", "plugins", "name"},
+	{"shop-items", "shop_items", "name"},
+	{"shop-categories", "shop_categories", "name"},
+	{"legal-documents", "legal_documents", "title"},
+}
+
+type missingTranslation struct {
+	Type             string   `json:"type"`
+	TranslationGroup string   `json:"translationGroup"`
+	ID               uint     `json:"id"` // любая существующая версия
+	Title            string   `json:"title"`
+	Languages        []string `json:"languages"`
+	Missing          []string `json:"missing"`
+}
+
+// GetMissingTranslations lists translation groups that lack some site language (admin only)
+// GET /api/admin/translations/missing?type=news
+func GetMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	languages := i18n.Languages()
+	filter := r.URL.Query().Get("type")
+	items := []missingTranslation{}
+	summary := make(map[string]int)
+
+	for _, t := range translationTables {
+		if filter != "" && filter != t.Type {
+			continue
+		}
+		var rows []struct {
+			TranslationGroup string
+			ID               uint
+			Title            string
+			Languages        string
+		}
+		err := database.DB.Raw(fmt.Sprintf(`SELECT translation_group, MIN(id) AS id,
+			(array_agg(%[2]s ORDER BY id))[1] AS title,
+			string_agg(DISTINCT language, ',') AS languages
+			FROM %[1]s GROUP BY translation_group ORDER BY MIN(id)`, t.Table, t.Column)).Scan(&rows).Error
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, row := range rows {
+			have := strings.Split(row.Languages, ",")
+			var missing []string
+			for _, l := range languages {
+				if !containsString(have, l) {
+					missing = append(missing, l)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			sort.Strings(have)
+			items = append(items, missingTranslation{
+				Type:             t.Type,
+				TranslationGroup: row.TranslationGroup,
+				ID:               row.ID,
+				Title:            row.Title,
+				Languages:        have,
+				Missing:          missing,
+			})
+			summary[t.Type]++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"languages":       languages,
+		"defaultLanguage": i18n.Default(),
+		"summary":         summary,
+		"items":           items,
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
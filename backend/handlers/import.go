@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"io"
+	"log"
 	"net/http"
 
 	"rust-legacy-site/database"
@@ -27,6 +28,10 @@ func ImportClans(w http.ResponseWriter, r *http.Request) {
 		if clan == nil {
 			break
 		}
+		if clan.LeaderSteamID != "" && !checkSteamID(r, "import/clans", clan.LeaderSteamID) {
+			clan.LeaderSteamID = ""
+		}
+		members = filterClanMembers(r, members)
 
 		var existing models.Clan
 		if err := database.DB.Where("hex_id = ?", clan.HexID).First(&existing).Error; err == nil {
@@ -60,6 +65,8 @@ func ImportClans(w http.ResponseWriter, r *http.Request) {
 		imported++
 	}
 
+	statsUpdated()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]int{"imported": imported})
 }
@@ -74,16 +81,24 @@ func ImportPlayers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	scanner := parser.NewPlayerScanner(string(body))
-	var imported int
+	var imported, rejected int
+	var nameChanges []database.NameChange
 
 	for {
 		player := scanner.Next()
 		if player == nil {
 			break
 		}
+		if !checkSteamID(r, "import/players", player.SteamID) {
+			rejected++
+			continue
+		}
 
 		var existing models.Player
 		if err := database.DB.Where("steam_id = ?", player.SteamID).First(&existing).Error; err == nil {
+			if existing.Username != player.Username {
+				nameChanges = append(nameChanges, database.NameChange{SteamID: player.SteamID, OldName: existing.Username, NewName: player.Username, Since: existing.FirstConnectDate})
+			}
 			existing.Username = player.Username
 			existing.Rank = player.Rank
 			existing.Language = player.Language
@@ -101,8 +116,13 @@ func ImportPlayers(w http.ResponseWriter, r *http.Request) {
 		imported++
 	}
 
+	if err := database.RecordNameChanges(nameChanges); err != nil {
+		log.Printf("[Import] name history error: %v", err)
+	}
+	statsUpdated()
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"imported": imported})
+	json.NewEncoder(w).Encode(map[string]int{"imported": imported, "rejected": rejected})
 }
 
 // ImportStats parses extended stats JSON and upserts PlayerStats
@@ -120,10 +140,29 @@ func ImportStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var imported, rejected int
 	for _, s := range stats {
+		if !checkSteamID(r, "import/stats", s.SteamID) {
+			rejected++
+			continue
+		}
 		database.DB.Save(&s)
+		imported++
 	}
 
+	statsUpdated()
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"imported": len(stats)})
+	json.NewEncoder(w).Encode(map[string]int{"imported": imported, "rejected": rejected})
+}
+
+// filterClanMembers drops members with invalid SteamID
+func filterClanMembers(r *http.Request, members []models.ClanMember) []models.ClanMember {
+	valid := members[:0]
+	for _, m := range members {
+		if checkSteamID(r, "import/clans", m.SteamID) {
+			valid = append(valid, m)
+		}
+	}
+	return valid
 }
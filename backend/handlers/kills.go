@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/steamid"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	killBatchMax     = 1000
+	killFeedMax      = 100
+	killNameMaxLen   = 64
+	killWeaponMaxLen = 64
+)
+
+// killTime accepts unix seconds (number) or RFC3339 string
+type killTime struct{ time.Time }
+
+func (t *killTime) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" || s == "0" {
+		return nil
+	}
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		t.Time = time.Unix(int64(sec), 0)
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("timestamp: expected unix seconds or RFC3339, got %q", s)
+	}
+	t.Time = parsed
+	return nil
+}
+
+type killPayloadItem struct {
+	Killer     string   `json:"killer"`
+	KillerName string   `json:"killerName"`
+	Victim     string   `json:"victim"`
+	VictimName string   `json:"victimName"`
+	Weapon     string   `json:"weapon"`
+	Distance   float64  `json:"distance"`
+	Timestamp  killTime `json:"timestamp"`
+	ServerID   uint     `json:"serverId"`
+}
+
+type killPayload struct {
+	ServerID   uint              `json:"serverId"`
+	ServerType string            `json:"serverType"` // classic / deathmatch, если serverId не задан
+	Kills      []killPayloadItem `json:"kills"`
+}
+
+func truncateRunes(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) > max {
+		s = string([]rune(s)[:max])
+	}
+	return s
+}
+
+// ReceiveKillEvents accepts a batch of PvP kills from the game plugin (GAME_API_KEY).
+// Повторная отправка того же события (сервер, убийца, жертва, время) игнорируется.
+// POST /api/events/kills
+// body: { "serverId": 1, "kills": [{ "killer": "7656...", "victim": "7656...", "weapon": "M4", "distance": 57.3, "timestamp": 1735689600 }] }
+func ReceiveKillEvents(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 2<<20)
+	var p killPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(p.Kills) > killBatchMax {
+		http.Error(w, fmt.Sprintf("too many kills in batch (max %d)", killBatchMax), http.StatusRequestEntityTooLarge)
+		return
+	}
+	serverID := p.ServerID
+	if serverID == 0 && p.ServerType != "" {
+		var srv models.ServerInfo
+		if err := database.DB.Where("type = ?", p.ServerType).First(&srv).Error; err == nil {
+			serverID = srv.ID
+		}
+	}
+
+	now := time.Now()
+	rows := make([]models.KillEvent, 0, len(p.Kills))
+	invalid, suicides := 0, 0
+	for _, k := range p.Kills {
+		// Только PvP: обе стороны — игроки (NPC и животные приходят без SteamID), самоубийства не считаем.
+		// Плагин доверенный (ключ), поэтому не событие безопасности — только счётчик
+		if !steamid.Valid(k.Killer) || !steamid.Valid(k.Victim) {
+			invalid++
+			continue
+		}
+		if k.Killer == k.Victim {
+			suicides++
+			continue
+		}
+		at := k.Timestamp.Time
+		if at.IsZero() || at.After(now.Add(5*time.Minute)) {
+			at = now
+		}
+		dist := k.Distance
+		if math.IsNaN(dist) || math.IsInf(dist, 0) || dist < 0 {
+			dist = 0
+		}
+		sid := k.ServerID
+		if sid == 0 {
+			sid = serverID
+		}
+		rows = append(rows, models.KillEvent{
+			ServerID:      sid,
+			KillerSteamID: k.Killer,
+			KillerName:    truncateRunes(k.KillerName, killNameMaxLen),
+			VictimSteamID: k.Victim,
+			VictimName:    truncateRunes(k.VictimName, killNameMaxLen),
+			Weapon:        truncateRunes(k.Weapon, killWeaponMaxLen),
+			Distance:      math.Round(dist*10) / 10,
+			KilledAt:      at.UTC().Truncate(time.Second),
+		})
+	}
+	if invalid > 0 {
+		log.Printf("[Kills] server %d: skipped %d of %d kills without valid SteamIDs", serverID, invalid, len(p.Kills))
+	}
+	var inserted int64
+	if len(rows) > 0 {
+		res := database.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500)
+		if res.Error != nil {
+			log.Printf("[Kills] insert failed: %v", res.Error)
+			http.Error(w, res.Error.Error(), http.StatusInternalServerError)
+			return
+		}
+		inserted = res.RowsAffected
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":         true,
+		"inserted":   inserted,
+		"duplicates": int64(len(rows)) - inserted,
+		"skipped":    invalid + suicides,
+		"invalid":    invalid,
+		"suicides":   suicides,
+	})
+}
+
+// GetKillFeed returns latest kills (newest first). For live updates poll with ?after=<last id>.
+// GET /api/kills/feed?serverId=1&after=123&limit=50&steamId=7656...
+func GetKillFeed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > killFeedMax {
+		limit = 50
+	}
+	query := database.DB.Model(&models.KillEvent{})
+	if sid, _ := strconv.Atoi(q.Get("serverId")); sid > 0 {
+		query = query.Where("server_id = ?", sid)
+	}
+	if after, _ := strconv.Atoi(q.Get("after")); after > 0 {
+		query = query.Where("id > ?", after)
+	}
+	if steamID := q.Get("steamId"); steamID != "" {
+		if !checkSteamID(r, "kills/feed", steamID) {
+			http.Error(w, "invalid steamId", http.StatusBadRequest)
+			return
+		}
+		query = query.Where("killer_steam_id = ? OR victim_steam_id = ?", steamID, steamID)
+	}
+	kills := []models.KillEvent{}
+	if err := query.Order("id DESC").Limit(limit).Find(&kills).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(kills)
+}
+
+type weaponStat struct {
+	Weapon      string  `json:"weapon"`
+	Kills       int64   `json:"kills"`
+	Killers     int64   `json:"killers"` // сколько разных игроков убивали этим оружием
+	AvgDistance float64 `json:"avgDistance"`
+	MaxDistance float64 `json:"maxDistance"`
+}
+
+// killDays parses ?days= (default 30, max 365, 0 = за всё время)
+func killDays(r *http.Request) int {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		if d, err := strconv.Atoi(v); err == nil && d >= 0 {
+			days = d
+		}
+	}
+	if days > 365 {
+		days = 365
+	}
+	return days
+}
+
+// weaponStats aggregates kills by weapon; steamID limits to kills made by the player
+func weaponStats(days int, serverID int, steamID string, limit int) ([]weaponStat, error) {
+	query := database.DB.Model(&models.KillEvent{}).
+		Select("COALESCE(NULLIF(weapon, ''), 'unknown') AS weapon, COUNT(*) AS kills, COUNT(DISTINCT killer_steam_id) AS killers, " +
+			"ROUND(AVG(distance)::numeric, 1) AS avg_distance, MAX(distance) AS max_distance").
+		Group("1").Order("kills DESC, weapon ASC").Limit(limit)
+	if days > 0 {
+		query = query.Where("killed_at >= ?", time.Now().AddDate(0, 0, -days))
+	}
+	if serverID > 0 {
+		query = query.Where("server_id = ?", serverID)
+	}
+	if steamID != "" {
+		query = query.Where("killer_steam_id = ?", steamID)
+	}
+	stats := []weaponStat{}
+	err := query.Scan(&stats).Error
+	return stats, err
+}
+
+// GetWeaponStats - GET /api/kills/weapons?days=30&serverId=1
+func GetWeaponStats(w http.ResponseWriter, r *http.Request) {
+	serverID, _ := strconv.Atoi(r.URL.Query().Get("serverId"))
+	stats, err := weaponStats(killDays(r), serverID, "", 50)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+type killOpponent struct {
+	SteamID  string    `json:"steamId"`
+	Username string    `json:"username"`
+	Kills    int64     `json:"kills"` // сколько раз убил (nemeses) / был убит (victims)
+	LastAt   time.Time `json:"lastAt"`
+}
+
+type playerKillsResponse struct {
+	SteamID string             `json:"steamId"`
+	Days    int                `json:"days"`
+	Kills   int64              `json:"kills"`
+	Deaths  int64              `json:"deaths"`
+	Nemeses []killOpponent     `json:"nemeses"` // кто чаще всего убивал игрока
+	Victims []killOpponent     `json:"victims"` // кого чаще всего убивал игрок
+	Weapons []weaponStat       `json:"weapons"`
+	Recent  []models.KillEvent `json:"recent"`
+}
+
+// killOpponents groups kills by the other side; self is "killer" or "victim" column prefix of the player
+func killOpponents(steamID, self string, days int) ([]killOpponent, error) {
+	other := "killer"
+	if self == "killer" {
+		other = "victim"
+	}
+	query := database.DB.Model(&models.KillEvent{}).
+		Select(other+"_steam_id AS steam_id, (array_agg("+other+"_name ORDER BY killed_at DESC))[1] AS username, "+
+			"COUNT(*) AS kills, MAX(killed_at) AS last_at").
+		Where(self+"_steam_id = ?", steamID).
+		Group(other + "_steam_id").Order("kills DESC, last_at DESC").Limit(10)
+	if days > 0 {
+		query = query.Where("killed_at >= ?", time.Now().AddDate(0, 0, -days))
+	}
+	list := []killOpponent{}
+	err := query.Scan(&list).Error
+	return list, err
+}
+
+// GetPlayerKills returns head-to-head stats of the player: nemeses, victims, weapons, recent kills/deaths
+// GET /api/players/{steamid}/kills?days=30
+func GetPlayerKills(w http.ResponseWriter, r *http.Request) {
+	steamID := mux.Vars(r)["steamid"]
+	if !checkSteamID(r, "players/kills", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
+	days := killDays(r)
+	resp := playerKillsResponse{SteamID: steamID, Days: days}
+	var err error
+	if resp.Nemeses, err = killOpponents(steamID, "victim", days); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if resp.Victims, err = killOpponents(steamID, "killer", days); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if resp.Weapons, err = weaponStats(days, 0, steamID, 10); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	since := time.Time{}
+	if days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+	database.DB.Model(&models.KillEvent{}).Where("killer_steam_id = ? AND killed_at >= ?", steamID, since).Count(&resp.Kills)
+	database.DB.Model(&models.KillEvent{}).Where("victim_steam_id = ? AND killed_at >= ?", steamID, since).Count(&resp.Deaths)
+	resp.Recent = []models.KillEvent{}
+	database.DB.Where("killer_steam_id = ? OR victim_steam_id = ?", steamID, steamID).Order("id DESC").Limit(20).Find(&resp.Recent)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// killEventsKeepDays - KILL_EVENTS_DAYS (default 365)
+func killEventsKeepDays() int {
+	if d, err := strconv.Atoi(os.Getenv("KILL_EVENTS_DAYS")); err == nil && d > 0 {
+		return d
+	}
+	return 365
+}
+
+// PruneKillEvents deletes kill events older than KILL_EVENTS_DAYS
+func PruneKillEvents() {
+	res := database.DB.Where("killed_at < ?", time.Now().AddDate(0, 0, -killEventsKeepDays())).Delete(&models.KillEvent{})
+	if res.Error != nil {
+		log.Printf("[Kills] prune: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("[Kills] pruned %d old kill events", res.RowsAffected)
+	}
+}
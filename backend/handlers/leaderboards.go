@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	leaderboardPageMax  = 100
+	leaderboardCacheMax = 500 // страниц на метрику, дальше кэш метрики сбрасывается
+)
+
+// leaderboardMetrics - SQL expression of each metric over players p LEFT JOIN player_stats s
+var leaderboardMetrics = map[string]string{
+	"kills":    "p.killed_players",
+	"kd":       "CASE WHEN p.deaths = 0 THEN p.killed_players::float8 ELSE p.killed_players::float8 / p.deaths END",
+	"playtime": "p.play_time",
+	"raid":     "COALESCE(s.raid_objects, 0)",
+	"farm":     "COALESCE(s.wood, 0) + COALESCE(s.metal, 0) + COALESCE(s.sulfur, 0)",
+	"wood":     "COALESCE(s.wood, 0)",
+	"metal":    "COALESCE(s.metal, 0)",
+	"sulfur":   "COALESCE(s.sulfur, 0)",
+	"animals":  "p.killed_animals",
+	"mutants":  "p.killed_mutants",
+}
+
+type leaderboardEntry struct {
+	Rank       int     `json:"rank"` // одинаковое значение = одинаковое место (1, 1, 3)
+	SteamID    string  `json:"steamId"`
+	Username   string  `json:"username"`
+	ClanID     *uint   `json:"clanId,omitempty"`
+	ClanAbbrev string  `json:"clanAbbrev,omitempty"`
+	IsOnline   bool    `json:"isOnline"`
+	PlayTime   int     `json:"playTime"`
+	Value      float64 `json:"value"`
+}
+
+type leaderboardPage struct {
+	Metric     string             `json:"metric"`
+	Total      int64              `json:"total"`
+	Entries    []leaderboardEntry `json:"entries"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+type leaderboardCacheEntry struct {
+	page     leaderboardPage
+	cachedAt time.Time
+}
+
+var (
+	leaderboardCache   = make(map[string]map[string]leaderboardCacheEntry) // metric -> params -> page
+	leaderboardCacheMu sync.Mutex
+)
+
+func leaderboardTTL() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("LEADERBOARD_CACHE_SEC")); err == nil && v >= 0 {
+		return time.Duration(v) * time.Second
+	}
+	return time.Minute
+}
+
+// invalidateLeaderboards drops cached pages
+func invalidateLeaderboards() {
+	leaderboardCacheMu.Lock()
+	leaderboardCache = make(map[string]map[string]leaderboardCacheEntry)
+	leaderboardCacheMu.Unlock()
+}
+
+// statsUpdated - после синхронизации/импорта игроков и кланов: пересчёт агрегатов кланов и сброс кэша
+func statsUpdated() {
+	if err := database.RefreshClanStats(); err != nil {
+		log.Printf("[Stats] clan stats refresh failed: %v", err)
+	}
+	invalidateLeaderboards()
+	go runAchievements()
+}
+
+// Cursor - base64 of "value|steamId" of the last entry (keyset pagination: стабильна при равных значениях)
+func encodeLeaderboardCursor(value float64, steamID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatFloat(value, 'g', -1, 64) + "|" + steamID))
+}
+
+func decodeLeaderboardCursor(s string) (float64, string, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, "", false
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return 0, "", false
+	}
+	v, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return v, parts[1], true
+}
+
+// GetLeaderboard returns players ranked by metric.
+// GET /api/leaderboards/{metric}?limit=50&cursor=...&minPlaytime=60
+// metric: kills, kd, playtime, raid, farm, wood, metal, sulfur, animals, mutants. minPlaytime — минуты.
+func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	metric := mux.Vars(r)["metric"]
+	expr, ok := leaderboardMetrics[metric]
+	if !ok {
+		http.Error(w, "unknown metric", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > leaderboardPageMax {
+		limit = 50
+	}
+	minPlaytime, _ := strconv.Atoi(q.Get("minPlaytime"))
+	if minPlaytime < 0 {
+		minPlaytime = 0
+	}
+	cursor := q.Get("cursor")
+	afterValue, afterSteamID, hasCursor := 0.0, "", false
+	if cursor != "" {
+		if afterValue, afterSteamID, hasCursor = decodeLeaderboardCursor(cursor); !hasCursor {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	key := strconv.Itoa(limit) + ":" + strconv.Itoa(minPlaytime) + ":" + cursor
+	ttl := leaderboardTTL()
+	leaderboardCacheMu.Lock()
+	entry, cached := leaderboardCache[metric][key]
+	leaderboardCacheMu.Unlock()
+	if cached && time.Since(entry.cachedAt) < ttl {
+		writeLeaderboard(w, entry.page)
+		return
+	}
+
+	// Место считается по всему отфильтрованному списку (RANK), курсор — по (value, steam_id)
+	ranked := `WITH ranked AS (
+		SELECT p.steam_id, p.username, p.clan_id, c.abbrev AS clan_abbrev, p.is_online, p.play_time,
+			(` + expr + `)::float8 AS value,
+			RANK() OVER (ORDER BY (` + expr + `) DESC) AS rank
+		FROM players p
+		LEFT JOIN player_stats s ON s.steam_id = p.steam_id
+		LEFT JOIN clans c ON c.id = p.clan_id
+		WHERE p.play_time >= ?
+	)`
+	page := leaderboardPage{Metric: metric, Entries: []leaderboardEntry{}}
+	if err := database.DB.Raw(ranked+` SELECT COUNT(*) FROM ranked`, minPlaytime).Scan(&page.Total).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	args := []interface{}{minPlaytime}
+	where := ""
+	if hasCursor {
+		where = ` WHERE value < ? OR (value = ? AND steam_id > ?)`
+		args = append(args, afterValue, afterValue, afterSteamID)
+	}
+	args = append(args, limit+1)
+	if err := database.DB.Raw(ranked+` SELECT * FROM ranked`+where+` ORDER BY value DESC, steam_id ASC LIMIT ?`, args...).
+		Scan(&page.Entries).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[:limit]
+		last := page.Entries[limit-1]
+		page.NextCursor = encodeLeaderboardCursor(last.Value, last.SteamID)
+	}
+	if metric == "kd" {
+		for i := range page.Entries {
+			page.Entries[i].Value = math.Round(page.Entries[i].Value*100) / 100
+		}
+	}
+
+	leaderboardCacheMu.Lock()
+	if leaderboardCache[metric] == nil || len(leaderboardCache[metric]) >= leaderboardCacheMax {
+		leaderboardCache[metric] = make(map[string]leaderboardCacheEntry)
+	}
+	leaderboardCache[metric][key] = leaderboardCacheEntry{page: page, cachedAt: time.Now()}
+	leaderboardCacheMu.Unlock()
+
+	writeLeaderboard(w, page)
+}
+
+func writeLeaderboard(w http.ResponseWriter, page leaderboardPage) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+)
+
+func TestLeaderboardCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		steamID string
+	}{
+		{"zero", 0, "76561198000000001"},
+		{"integer", 1500, "76561198000000002"},
+		{"fraction", 2.3333333333333335, "76561198000000003"},
+		{"negative", -3, "76561198000000004"},
+		{"large", 1e21, "76561198000000005"},
+		{"max float", math.MaxFloat64, "76561198000000006"},
+		{"pipe in id", 7, "a|b"},
+		{"empty id", 7, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := encodeLeaderboardCursor(tt.value, tt.steamID)
+			value, steamID, ok := decodeLeaderboardCursor(cursor)
+			if !ok || value != tt.value || steamID != tt.steamID {
+				t.Errorf("decode(encode(%v, %q)) = %v, %q, %v", tt.value, tt.steamID, value, steamID, ok)
+			}
+		})
+	}
+}
+
+func TestDecodeLeaderboardCursorInvalid(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "!!!"},
+		{"no separator", enc("76561198000000001")},
+		{"value not a number", enc("abc|76561198000000001")},
+		{"empty value", enc("|76561198000000001")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, id, ok := decodeLeaderboardCursor(tt.cursor); ok {
+				t.Errorf("decodeLeaderboardCursor(%q) = %v, %q, true; want false", tt.cursor, v, id)
+			}
+		})
+	}
+}
@@ -7,18 +7,20 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
+	"rust-legacy-site/pkg/sanitize"
 
 	"github.com/gorilla/mux"
 )
 
 func GetLegalDocuments(w http.ResponseWriter, r *http.Request) {
 	var docs []models.LegalDocument
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 	docType := r.URL.Query().Get("type")
 
 	query := database.DB
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 	if docType != "" {
 		query = query.Where("type = ?", docType)
@@ -28,6 +30,7 @@ func GetLegalDocuments(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	docs = i18n.Resolve(docs, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(docs)
@@ -40,7 +43,10 @@ func CreateLegalDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := database.DB.Create(&doc).Error; err != nil {
+	doc.ID = 0
+	doc.Content = sanitize.HTML(doc.Content)
+	ensureTranslationGroup(&doc.TranslationGroup)
+	if err := saveLegalDocument(r, &doc, ""); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -60,12 +66,22 @@ func UpdateLegalDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	prev := doc
 	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	if err := database.DB.Save(&doc).Error; err != nil {
+	doc.ID, doc.Version = prev.ID, prev.Version
+
+	doc.Content = sanitize.HTML(doc.Content)
+	ensureTranslationGroup(&doc.TranslationGroup)
+	var err error
+	if doc.Language == prev.Language && doc.Type == prev.Type && doc.Title == prev.Title && doc.Content == prev.Content {
+		err = database.DB.Save(&doc).Error // текст не менялся — новая ревизия не нужна
+	} else {
+		err = saveLegalDocument(r, &doc, "")
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
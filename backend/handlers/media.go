@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/media"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	mediaThumbSize      = 400
+	mediaPerPageMax     = 200
+	mediaGCGraceDefault = 24 * time.Hour // свежие загрузки ещё могут быть не сохранены в контенте
+)
+
+var (
+	mediaStorage     media.Storage
+	mediaStorageOnce sync.Once
+)
+
+func getMediaStorage() media.Storage {
+	mediaStorageOnce.Do(func() { mediaStorage = media.FromEnv() })
+	return mediaStorage
+}
+
+// mediaLimitMB reads size limit from env (MB)
+func mediaLimitMB(key string, def int64) int64 {
+	if v, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil && v > 0 {
+		return v << 20
+	}
+	return def << 20
+}
+
+func mediaImageMaxSize() int {
+	if v, err := strconv.Atoi(os.Getenv("MEDIA_IMAGE_MAX_SIZE")); err == nil && v >= mediaThumbSize {
+		return v
+	}
+	return 1920
+}
+
+// newMediaKey returns storage key like 2026/10/3f9c0a1b2c3d4e5f
+func newMediaKey() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return time.Now().Format("2006/01") + "/" + hex.EncodeToString(b)
+}
+
+// mediaJSON - MediaFile with usage flag for media library
+type mediaJSON struct {
+	models.MediaFile
+	Used bool `json:"used"`
+}
+
+// UploadMedia uploads image or video (multipart field "file").
+// Type is detected by content; images are resized (MEDIA_IMAGE_MAX_SIZE) and get thumbnail.
+// POST /api/admin/media
+func UploadMedia(w http.ResponseWriter, r *http.Request) {
+	maxImage := mediaLimitMB("MEDIA_MAX_IMAGE_MB", 10)
+	maxVideo := mediaLimitMB("MEDIA_MAX_VIDEO_MB", 200)
+	r.Body = http.MaxBytesReader(w, r.Body, maxVideo+1<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, "file too large or invalid form: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType, ext, err := media.Sniff(head[:n])
+	if err != nil {
+		http.Error(w, err.Error()+", got "+contentType, http.StatusUnsupportedMediaType)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	storage := getMediaStorage()
+	key := newMediaKey()
+	item := models.MediaFile{
+		Storage:      storage.Name(),
+		OriginalName: path.Base(strings.ReplaceAll(header.Filename, "\\", "/")),
+		ContentType:  contentType,
+	}
+	if claims := getClaims(r); claims != nil {
+		item.UploadedBy = claims.Username
+	}
+
+	if media.IsImage(contentType) {
+		if header.Size > maxImage {
+			http.Error(w, "image is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxImage+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p, err := media.ProcessImage(data, contentType, mediaImageMaxSize(), mediaThumbSize)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		item.Key, item.ContentType, item.Size = key+p.Ext, p.ContentType, int64(len(p.Data))
+		item.Width, item.Height = p.Width, p.Height
+		item.ThumbKey = key + "_thumb" + p.ThumbExt
+		if err := storage.Put(item.Key, bytes.NewReader(p.Data), item.Size, item.ContentType); err != nil {
+			log.Printf("[Media] put %s: %v", item.Key, err)
+			http.Error(w, "storage error", http.StatusBadGateway)
+			return
+		}
+		thumbType := "image/jpeg"
+		if p.ThumbExt == ".png" {
+			thumbType = "image/png"
+		}
+		if err := storage.Put(item.ThumbKey, bytes.NewReader(p.Thumb), int64(len(p.Thumb)), thumbType); err != nil {
+			log.Printf("[Media] put %s: %v", item.ThumbKey, err)
+			storage.Delete(item.Key)
+			http.Error(w, "storage error", http.StatusBadGateway)
+			return
+		}
+		item.ThumbURL = storage.URL(item.ThumbKey)
+	} else {
+		if header.Size > maxVideo {
+			http.Error(w, "video is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		item.Key, item.Size = key+ext, header.Size
+		if err := storage.Put(item.Key, file, item.Size, contentType); err != nil {
+			log.Printf("[Media] put %s: %v", item.Key, err)
+			http.Error(w, "storage error", http.StatusBadGateway)
+			return
+		}
+	}
+	item.URL = storage.URL(item.Key)
+
+	if err := database.DB.Create(&item).Error; err != nil {
+		deleteMediaFiles(item)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[Media] uploaded %s (%s, %d bytes) by %s", item.Key, item.ContentType, item.Size, item.UploadedBy)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// GetMedia returns media library (newest first) with "used" flag.
+// GET /api/admin/media?type=image|video&q=name&page=1&limit=50 (total count in X-Total-Count)
+func GetMedia(w http.ResponseWriter, r *http.Request) {
+	query := database.DB.Model(&models.MediaFile{})
+	switch r.URL.Query().Get("type") {
+	case "image":
+		query = query.Where("content_type LIKE ?", "image/%")
+	case "video":
+		query = query.Where("content_type LIKE ?", "video/%")
+	}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		query = query.Where("original_name ILIKE ? ESCAPE '\\'", "%"+escapeLike(q)+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > mediaPerPageMax {
+		limit = 50
+	}
+
+	var files []models.MediaFile
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&files).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ids := make([]uint, 0, len(files))
+	for _, f := range files {
+		ids = append(ids, f.ID)
+	}
+	used, err := database.UsedMediaIDs(ids)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]mediaJSON, 0, len(files))
+	for _, f := range files {
+		resp = append(resp, mediaJSON{MediaFile: f, Used: used[f.ID]})
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DeleteMedia deletes file from storage; file used in content is deleted only with ?force=true
+// DELETE /api/admin/media/{id}
+func DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var item models.MediaFile
+	if err := database.DB.First(&item, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("force") != "true" {
+		used, err := database.UsedMediaIDs([]uint{item.ID})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if used[item.ID] {
+			http.Error(w, "file is used in content (use ?force=true)", http.StatusConflict)
+			return
+		}
+	}
+	if err := deleteMediaFiles(item); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	database.DB.Delete(&item)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CollectMediaGarbage runs media garbage collection now.
+// POST /api/admin/media/gc?dryRun=true
+func CollectMediaGarbage(w http.ResponseWriter, r *http.Request) {
+	files, freed, err := CollectUnusedMedia(r.URL.Query().Get("dryRun") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deleted": len(files),
+		"freed":   freed,
+		"files":   files,
+	})
+}
+
+// CollectUnusedMedia deletes media files not referenced by any content and older than MEDIA_GC_GRACE_HOURS.
+// Called daily from main.
+func CollectUnusedMedia(dryRun bool) ([]models.MediaFile, int64, error) {
+	grace := mediaGCGraceDefault
+	if h, err := strconv.Atoi(os.Getenv("MEDIA_GC_GRACE_HOURS")); err == nil && h >= 0 {
+		grace = time.Duration(h) * time.Hour
+	}
+	files, err := database.UnusedMedia(time.Now().Add(-grace))
+	if err != nil || dryRun {
+		return files, 0, err
+	}
+
+	var deleted []models.MediaFile
+	var freed int64
+	for _, f := range files {
+		if err := deleteMediaFiles(f); err != nil {
+			log.Printf("[Media] gc %s: %v", f.Key, err)
+			continue
+		}
+		database.DB.Delete(&f)
+		deleted = append(deleted, f)
+		freed += f.Size
+	}
+	if len(deleted) > 0 {
+		log.Printf("[Media] gc: deleted %d unused files, %d bytes", len(deleted), freed)
+	}
+	return deleted, freed, nil
+}
+
+func deleteMediaFiles(f models.MediaFile) error {
+	storage := getMediaStorage()
+	if f.Storage != "" && f.Storage != storage.Name() {
+		log.Printf("[Media] %s is in %s storage, current is %s — only DB record removed", f.Key, f.Storage, storage.Name())
+		return nil
+	}
+	if f.ThumbKey != "" {
+		if err := storage.Delete(f.ThumbKey); err != nil {
+			return err
+		}
+	}
+	return storage.Delete(f.Key)
+}
+
+// ServeMediaFile serves files of local media storage
+// GET /api/media/files/{key}
+func ServeMediaFile(w http.ResponseWriter, r *http.Request) {
+	local, ok := getMediaStorage().(*media.LocalStorage)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := local.Path(strings.TrimPrefix(r.URL.Path, "/api/media/files/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if fi, err := os.Stat(p); err != nil || fi.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	// Имена файлов случайные и не переиспользуются
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(w, r, p)
+}
@@ -2,35 +2,150 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/events"
+	"rust-legacy-site/pkg/i18n"
+	"rust-legacy-site/pkg/sanitize"
+	"rust-legacy-site/pkg/slug"
 
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
+const (
+	newsPerPageDefault = 20
+	newsPerPageMax     = 100
+	newsMaxTags        = 20
+)
+
+// newsJSON - News with tags as array (в БД хранятся через запятую)
+type newsJSON struct {
+	models.News
+	Tags []string `json:"tags"`
+}
+
+func toNewsJSON(n models.News) newsJSON {
+	return newsJSON{News: n, Tags: splitTags(n.Tags)}
+}
+
+func splitTags(s string) []string {
+	tags := []string{}
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// joinTags normalizes tags (lower case, no commas, unique) for storage
+func joinTags(tags []string) string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(t, ",", " ")))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+		if len(out) == newsMaxTags {
+			break
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// visibleNews - published and PublishedAt already came (отложенные посты скрыты до даты)
+func visibleNews(query *gorm.DB) *gorm.DB {
+	return query.Where("published = ? AND published_at <= ?", true, time.Now())
+}
+
+func newsVisible(n models.News) bool {
+	return n.Published && !n.PublishedAt.After(time.Now())
+}
+
+// newsURL - ссылка на новость на сайте
+func newsURL(n models.News) string {
+	return SiteURL + "/news?slug=" + url.QueryEscape(n.Slug)
+}
+
+// GetNews returns news list. Visitors get only visible posts; admin gets drafts and scheduled too.
+// GET /api/news?lang=ru&tag=wipe&page=1&limit=20 (total count in X-Total-Count)
 func GetNews(w http.ResponseWriter, r *http.Request) {
 	var news []models.News
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 	publishedOnly := r.URL.Query().Get("published") == "true"
+	tag := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag")))
 
-	query := database.DB.Order("published_at DESC")
+	query := database.DB.Model(&models.News{})
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
-	if publishedOnly {
+	if !authpkg.IsAdminRequest(r) {
+		query = visibleNews(query)
+	} else if publishedOnly {
 		query = query.Where("published = ?", true)
 	}
+	if tag != "" {
+		query = query.Where("(',' || tags || ',') LIKE ?", "%,"+tag+",%")
+	}
+	if lang != "" {
+		query = resolveNewsLang(query, lang)
+	}
+	query = query.Session(&gorm.Session{})
 
-	if err := query.Find(&news).Error; err != nil {
+	// Пагинация в SQL по уже выбранным языковым версиям, чтобы страницы не «плыли» из-за fallback
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	paged := query.Order("published_at DESC, id DESC")
+	var total int64
+	if page > 0 || limit > 0 {
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > newsPerPageMax {
+			limit = newsPerPageDefault
+		}
+		if err := query.Count(&total).Error; err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		paged = paged.Offset((page - 1) * limit).Limit(limit)
+	}
+	if err := paged.Find(&news).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if page == 0 && limit == 0 {
+		total = int64(len(news))
+	}
 
+	resp := make([]newsJSON, 0, len(news))
+	for _, n := range news {
+		resp = append(resp, toNewsJSON(n))
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(news)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// resolveNewsLang - SQL-аналог i18n.Resolve: одна строка на translation group (версия lang, иначе язык по умолчанию),
+// новости без группы — только на языке lang
+func resolveNewsLang(query *gorm.DB, lang string) *gorm.DB {
+	ranked := query.
+		Select(`news.*, ROW_NUMBER() OVER (PARTITION BY COALESCE(NULLIF(translation_group, ''), 'id:' || id) ORDER BY (language = ?) DESC, id DESC) AS lang_rank`, lang).
+		Where("COALESCE(translation_group, '') <> '' OR language = ?", lang)
+	return database.DB.Table("(?) AS news", ranked).Where("lang_rank = 1")
 }
 
 func GetNewsItem(w http.ResponseWriter, r *http.Request) {
@@ -42,26 +157,59 @@ func GetNewsItem(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	writeNewsItem(w, r, newsItem)
+}
+
+// GetNewsBySlug returns news by slug
+// GET /api/news/by-slug/{slug}
+func GetNewsBySlug(w http.ResponseWriter, r *http.Request) {
+	var newsItem models.News
+	if err := database.DB.Where("slug = ?", mux.Vars(r)["slug"]).First(&newsItem).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeNewsItem(w, r, newsItem)
+}
 
+// writeNewsItem hides drafts and scheduled posts from everyone except admin
+func writeNewsItem(w http.ResponseWriter, r *http.Request, n models.News) {
+	if !newsVisible(n) && !authpkg.IsAdminRequest(r) {
+		http.Error(w, "record not found", http.StatusNotFound)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(newsItem)
+	json.NewEncoder(w).Encode(toNewsJSON(n))
 }
 
 func CreateNews(w http.ResponseWriter, r *http.Request) {
-	var newsItem models.News
-	if err := json.NewDecoder(r.Body).Decode(&newsItem); err != nil {
+	var input newsJSON
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	newsItem := input.News
+	newsItem.ID = 0
+	newsItem.AnnouncedAt = nil
+	newsItem.Tags = joinTags(input.Tags)
+	if newsItem.Published && newsItem.PublishedAt.IsZero() {
+		newsItem.PublishedAt = time.Now()
+	}
+	if status, err := assignNewsSlug(&newsItem); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
 
+	newsItem.Content = sanitize.HTML(newsItem.Content)
+	ensureTranslationGroup(&newsItem.TranslationGroup)
 	if err := database.DB.Create(&newsItem).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	AnnounceDueNews()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(newsItem)
+	json.NewEncoder(w).Encode(toNewsJSON(newsItem))
 }
 
 func UpdateNews(w http.ResponseWriter, r *http.Request) {
@@ -74,18 +222,56 @@ func UpdateNews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&newsItem); err != nil {
+	input := toNewsJSON(newsItem)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	announcedAt := newsItem.AnnouncedAt
+	newsItem = input.News
+	newsItem.ID = uint(id)
+	newsItem.AnnouncedAt = announcedAt // событие публикации отправляется один раз
+	newsItem.Tags = joinTags(input.Tags)
+	if newsItem.Published && newsItem.PublishedAt.IsZero() {
+		newsItem.PublishedAt = time.Now()
+	}
+	if status, err := assignNewsSlug(&newsItem); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
 
+	newsItem.Content = sanitize.HTML(newsItem.Content)
+	ensureTranslationGroup(&newsItem.TranslationGroup)
 	if err := database.DB.Save(&newsItem).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	AnnounceDueNews()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(newsItem)
+	json.NewEncoder(w).Encode(toNewsJSON(newsItem))
+}
+
+type newsError string
+
+func (e newsError) Error() string { return string(e) }
+
+// assignNewsSlug validates slug set by editor or generates one from title
+func assignNewsSlug(n *models.News) (int, error) {
+	n.Slug = strings.TrimSpace(n.Slug)
+	if n.Slug == "" {
+		n.Slug = database.UniqueNewsSlug(n.Title, n.ID)
+		return 0, nil
+	}
+	if !slug.Valid(n.Slug) {
+		return http.StatusBadRequest, newsError("slug may contain only a-z, 0-9 and dashes")
+	}
+	var count int64
+	database.DB.Model(&models.News{}).Where("slug = ? AND id <> ?", n.Slug, n.ID).Count(&count)
+	if count > 0 {
+		return http.StatusConflict, newsError("slug is already used by another news")
+	}
+	return 0, nil
 }
 
 func DeleteNews(w http.ResponseWriter, r *http.Request) {
@@ -99,3 +285,43 @@ func DeleteNews(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// newsPublishedEvent - payload of news.published event
+type newsPublishedEvent struct {
+	ID          uint      `json:"id"`
+	Slug        string    `json:"slug"`
+	Language    string    `json:"language"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	ImageURL    string    `json:"imageUrl,omitempty"`
+	Tags        []string  `json:"tags"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// AnnounceDueNews emits news.published for posts that became visible (сразу или по расписанию).
+// Called after create/update and every minute from main.
+func AnnounceDueNews() {
+	var due []models.News
+	if err := visibleNews(database.DB).Where("announced_at IS NULL").Order("published_at ASC").Find(&due).Error; err != nil {
+		log.Printf("[News] announce: %v", err)
+		return
+	}
+	for _, n := range due {
+		// Помечаем атомарно, чтобы параллельный вызов не отправил событие дважды
+		res := database.DB.Model(&models.News{}).Where("id = ? AND announced_at IS NULL", n.ID).Update("announced_at", time.Now())
+		if res.Error != nil || res.RowsAffected == 0 {
+			continue
+		}
+		events.Publish(events.NewsPublished, newsPublishedEvent{
+			ID:          n.ID,
+			Slug:        n.Slug,
+			Language:    n.Language,
+			Title:       n.Title,
+			URL:         newsURL(n),
+			ImageURL:    n.ImageURL,
+			Tags:        splitTags(n.Tags),
+			PublishedAt: n.PublishedAt,
+		})
+		log.Printf("[News] published: %d %q", n.ID, n.Title)
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
+)
+
+const newsFeedSize = 20
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"xml:lang,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GetNewsFeed returns RSS 2.0 (default) or Atom feed of visible news for a language.
+// GET /api/news/feed.xml?lang=en&format=atom
+func GetNewsFeed(w http.ResponseWriter, r *http.Request) {
+	lang := i18n.Normalize(r.URL.Query().Get("lang"))
+	if r.URL.Query().Get("lang") == "" {
+		lang = i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+	var news []models.News
+	if err := visibleNews(database.DB).Where("language = ?", lang).
+		Order("published_at DESC, id DESC").Limit(newsFeedSize).Find(&news).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	title := "Rust Legacy — News"
+	if lang == "ru" {
+		title = "Rust Legacy — Новости"
+	}
+	selfURL := SiteURL + "/api/news/feed.xml?lang=" + lang
+	updated := time.Now()
+	if len(news) > 0 {
+		updated = news[0].UpdatedAt
+	}
+
+	var out interface{}
+	contentType := "application/rss+xml; charset=utf-8"
+	if strings.EqualFold(r.URL.Query().Get("format"), "atom") {
+		contentType = "application/atom+xml; charset=utf-8"
+		selfURL += "&format=atom"
+		feed := atomFeed{
+			Lang:    lang,
+			ID:      selfURL,
+			Title:   title,
+			Updated: updated.UTC().Format(time.RFC3339),
+			Links: []atomLink{
+				{Href: SiteURL + "/news", Rel: "alternate", Type: "text/html"},
+				{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			},
+		}
+		for _, n := range news {
+			entry := atomEntry{
+				ID:        newsURL(n),
+				Title:     n.Title,
+				Link:      atomLink{Href: newsURL(n), Rel: "alternate", Type: "text/html"},
+				Published: n.PublishedAt.UTC().Format(time.RFC3339),
+				Updated:   n.UpdatedAt.UTC().Format(time.RFC3339),
+				Content:   atomContent{Type: "html", Value: n.Content},
+			}
+			for _, t := range splitTags(n.Tags) {
+				entry.Categories = append(entry.Categories, atomCategory{Term: t})
+			}
+			feed.Entries = append(feed.Entries, entry)
+		}
+		out = feed
+	} else {
+		feed := rssFeed{
+			Version: "2.0",
+			Atom:    "http://www.w3.org/2005/Atom",
+			Channel: rssChannel{
+				Title:         title,
+				Link:          SiteURL + "/news",
+				Description:   title,
+				Language:      lang,
+				LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+				Self:          rssLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+			},
+		}
+		for _, n := range news {
+			feed.Channel.Items = append(feed.Channel.Items, rssItem{
+				Title:       n.Title,
+				Link:        newsURL(n),
+				GUID:        rssGUID{Value: newsURL(n), IsPermaLink: true},
+				PubDate:     n.PublishedAt.UTC().Format(time.RFC1123Z),
+				Categories:  splitTags(n.Tags),
+				Description: n.Content,
+			})
+		}
+		out = feed
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(out)
+}
@@ -2,33 +2,138 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/onlinehistory"
 )
 
-func GetOnlineHistory(w http.ResponseWriter, r *http.Request) {
-	hoursStr := r.URL.Query().Get("hours")
+const onlineHistoryMaxDays = 730
+
+// onlineHistoryPoint - aggregated bucket; players = max, чтобы график совпадал с сырыми точками
+type onlineHistoryPoint struct {
+	ServerID   uint      `json:"serverId"`
+	ServerType string    `json:"serverType"`
+	RecordedAt time.Time `json:"recordedAt"` // начало бакета
+	Players    int       `json:"players"`
+	Min        int       `json:"min"`
+	Avg        float64   `json:"avg"`
+	Max        int       `json:"max"`
+	PeakAt     time.Time `json:"peakAt"`
+	Samples    int       `json:"samples"`
+}
+
+// parseOnlineRange parses ?range= (24h, 7d, 90d, 1y) or legacy ?hours=
+func parseOnlineRange(r *http.Request) (time.Duration, error) {
+	q := r.URL.Query()
+	if v := q.Get("range"); v != "" {
+		n, err := strconv.Atoi(v[:len(v)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid range %q (examples: 24h, 7d, 90d, 1y)", v)
+		}
+		var d time.Duration
+		switch v[len(v)-1] {
+		case 'h':
+			d = time.Duration(n) * time.Hour
+		case 'd':
+			d = time.Duration(n) * 24 * time.Hour
+		case 'y':
+			d = time.Duration(n) * 365 * 24 * time.Hour
+		default:
+			return 0, fmt.Errorf("invalid range %q (examples: 24h, 7d, 90d, 1y)", v)
+		}
+		if d > onlineHistoryMaxDays*24*time.Hour {
+			d = onlineHistoryMaxDays * 24 * time.Hour
+		}
+		return d, nil
+	}
 	hours := 24
-	if h, err := strconv.Atoi(hoursStr); err == nil && h > 0 && h <= 168 {
+	if h, err := strconv.Atoi(q.Get("hours")); err == nil && h > 0 && h <= onlineHistoryMaxDays*24 {
 		hours = h
 	}
+	return time.Duration(hours) * time.Hour, nil
+}
+
+// GetOnlineHistory returns online history of servers.
+// bucket=raw — сырые точки (хранятся ONLINE_HISTORY_RAW_DAYS); hour / day / week / month — агрегаты min/avg/max.
+// Without bucket: raw up to 7 days, hourly up to 31 days, daily for longer ranges.
+// GET /api/server-status/history?range=90d&bucket=day&type=classic (legacy: ?hours=24)
+func GetOnlineHistory(w http.ResponseWriter, r *http.Request) {
+	span, err := parseOnlineRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	serverType := r.URL.Query().Get("type")
+	since := time.Now().Add(-span)
 
-	since := time.Now().Add(-time.Duration(hours) * time.Hour)
-	var records []models.OnlineHistory
-	q := database.DB.Where("recorded_at >= ?", since)
+	bucket := strings.ToLower(r.URL.Query().Get("bucket"))
+	if bucket == "" || bucket == "auto" {
+		switch {
+		case span <= 7*24*time.Hour:
+			bucket = "raw"
+		case span <= 31*24*time.Hour:
+			bucket = "hour"
+		default:
+			bucket = "day"
+		}
+	}
+
+	if bucket == "raw" {
+		if span > time.Duration(onlinehistory.RawDays())*24*time.Hour {
+			http.Error(w, fmt.Sprintf("raw points are kept for %d days, use bucket=hour or bucket=day", onlinehistory.RawDays()), http.StatusBadRequest)
+			return
+		}
+		var records []models.OnlineHistory
+		q := database.DB.Where("recorded_at >= ?", since)
+		if serverType != "" {
+			q = q.Where("server_type = ?", serverType)
+		}
+		if err := q.Order("recorded_at ASC").Find(&records).Error; err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(records)
+		return
+	}
+
+	resolution := onlinehistory.ResolutionDay
+	switch bucket {
+	case "hour":
+		resolution = onlinehistory.ResolutionHour
+		if span > time.Duration(onlinehistory.HourlyDays())*24*time.Hour {
+			http.Error(w, fmt.Sprintf("hourly data is kept for %d days, use bucket=day", onlinehistory.HourlyDays()), http.StatusBadRequest)
+			return
+		}
+	case "day", "week", "month":
+	default:
+		http.Error(w, "bucket must be raw, hour, day, week or month", http.StatusBadRequest)
+		return
+	}
+
+	// bucket — значение из списка выше, в SQL подставляется как литерал date_trunc
+	q := database.DB.Model(&models.OnlineHistoryRollup{}).
+		Select(`server_id, MAX(server_type) AS server_type, date_trunc('`+bucket+`', bucket) AS recorded_at,
+			MAX(max_players) AS players, MIN(min_players) AS min,
+			ROUND((SUM(avg_players * samples) / NULLIF(SUM(samples), 0))::numeric, 2) AS avg, MAX(max_players) AS max,
+			(array_agg(peak_at ORDER BY max_players DESC, peak_at ASC))[1] AS peak_at, SUM(samples) AS samples`).
+		Where("resolution = ? AND bucket >= date_trunc(?, ?::timestamptz)", resolution, bucket, since).
+		Group("server_id, 3").
+		Order("recorded_at ASC, server_id ASC")
 	if serverType != "" {
 		q = q.Where("server_type = ?", serverType)
 	}
-	if err := q.Order("recorded_at ASC").Find(&records).Error; err != nil {
+	points := []onlineHistoryPoint{}
+	if err := q.Scan(&points).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(records)
+	json.NewEncoder(w).Encode(points)
 }
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/pkg/playerhistory"
+
+	"github.com/gorilla/mux"
+)
+
+type historyPoint struct {
+	T     time.Time `json:"t"`
+	Value int64     `json:"value"`
+}
+
+// parseHistoryRange parses "24h", "7d", "30d", "1y"
+func parseHistoryRange(s string) (time.Duration, bool) {
+	if len(s) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	switch s[len(s)-1] {
+	case 'h':
+		return time.Duration(n) * time.Hour, true
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	case 'y':
+		return time.Duration(n) * 365 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// GetPlayerHistory returns bucketed series of player counter growth.
+// GET /api/players/{steamid}/history?metric=killedPlayers&range=30d&bucket=day
+// bucket: hour (только в пределах PLAYER_HISTORY_HOURLY_DAYS), day, week; по умолчанию выбирается по range.
+func GetPlayerHistory(w http.ResponseWriter, r *http.Request) {
+	steamID := mux.Vars(r)["steamid"]
+	if !checkSteamID(r, "players/history", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	metric := q.Get("metric")
+	if metric == "" {
+		metric = "killedPlayers"
+	}
+	col, ok := playerhistory.Metrics[metric]
+	if !ok {
+		http.Error(w, "unknown metric", http.StatusBadRequest)
+		return
+	}
+	rangeStr := strings.ToLower(q.Get("range"))
+	if rangeStr == "" {
+		rangeStr = "30d"
+	}
+	span, ok := parseHistoryRange(rangeStr)
+	if !ok {
+		http.Error(w, "invalid range (examples: 24h, 7d, 30d, 1y)", http.StatusBadRequest)
+		return
+	}
+	if maxSpan := time.Duration(playerhistory.RetentionDays()) * 24 * time.Hour; span > maxSpan {
+		span = maxSpan
+	}
+
+	hourlySpan := time.Duration(playerhistory.HourlyDays()) * 24 * time.Hour
+	bucket := q.Get("bucket")
+	switch bucket {
+	case "":
+		bucket = "day"
+		if span <= 48*time.Hour {
+			bucket = "hour"
+		} else if span > 180*24*time.Hour {
+			bucket = "week"
+		}
+	case "hour":
+		if span > hourlySpan {
+			http.Error(w, "hourly buckets are kept for "+strconv.Itoa(playerhistory.HourlyDays())+" days only", http.StatusBadRequest)
+			return
+		}
+	case "day", "week":
+	default:
+		http.Error(w, "bucket must be hour, day or week", http.StatusBadRequest)
+		return
+	}
+
+	// generate_series даёт пустые бакеты с нулём, чтобы график был непрерывным
+	var points []historyPoint
+	err := database.DB.Raw(`SELECT g.t AS t, COALESCE(SUM(d.`+col+`), 0) AS value
+		FROM generate_series(date_trunc(?, ?::timestamptz), now(), ('1 ' || ?)::interval) AS g(t)
+		LEFT JOIN player_stat_deltas d ON d.steam_id = ? AND d.bucket >= g.t AND d.bucket < g.t + ('1 ' || ?)::interval
+		GROUP BY g.t ORDER BY g.t`,
+		bucket, time.Now().Add(-span), bucket, steamID, bucket).Scan(&points).Error
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var total int64
+	for _, p := range points {
+		total += p.Value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"steamId": steamID,
+		"metric":  metric,
+		"range":   rangeStr,
+		"bucket":  bucket,
+		"total":   total,
+		"points":  points,
+	})
+}
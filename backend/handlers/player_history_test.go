@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHistoryRange(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"24h", 24 * time.Hour, true},
+		{"7d", 7 * 24 * time.Hour, true},
+		{"30d", 30 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"1y", 365 * 24 * time.Hour, true},
+		{"", 0, false},
+		{"d", 0, false},
+		{"0d", 0, false},
+		{"-1d", 0, false},
+		{"10m", 0, false},
+		{"1.5d", 0, false},
+		{"7D", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseHistoryRange(tt.s)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseHistoryRange(%q) = %v, %v; want %v, %v", tt.s, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+
+	"github.com/gorilla/mux"
+)
+
+const playerSearchMax = 50
+
+type playerSearchResult struct {
+	SteamID       string  `json:"steamId"`
+	Username      string  `json:"username"`
+	MatchedName   string  `json:"matchedName"`
+	IsCurrent     bool    `json:"isCurrent"` // совпал текущий ник, а не один из прошлых
+	Score         float64 `json:"score"`
+	ClanID        *uint   `json:"clanId,omitempty"`
+	IsOnline      bool    `json:"isOnline"`
+	KilledPlayers int     `json:"killedPlayers"`
+}
+
+// escapeLike escapes LIKE wildcards (ESCAPE '\')
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SearchPlayers finds players by current and past names: case-insensitive prefix first,
+// then trigram similarity (pg_trgm; without it — substring match).
+// GET /api/players/search?q=name&limit=20
+func SearchPlayers(w http.ResponseWriter, r *http.Request) {
+	q := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+	if utf8.RuneCountInString(q) < 2 {
+		http.Error(w, "q must be at least 2 characters", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(q) > 64 {
+		q = string([]rune(q)[:64])
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > playerSearchMax {
+		limit = 20
+	}
+
+	args := map[string]interface{}{
+		"q":        q,
+		"prefix":   escapeLike(q) + "%",
+		"contains": "%" + escapeLike(q) + "%",
+		"limit":    limit,
+	}
+	match, score := `lower(name) LIKE @contains ESCAPE '\'`, `0`
+	if database.TrigramAvailable() {
+		match, score = `lower(name) % @q`, `similarity(lower(name), @q)`
+	}
+	// Лучшее совпадение на игрока: префикс важнее похожести, текущий ник важнее прошлого
+	sql := `WITH names AS (
+			SELECT steam_id, username AS name, true AS is_current FROM players
+			UNION ALL
+			SELECT steam_id, name, false FROM player_names
+		), matched AS (
+			SELECT DISTINCT ON (steam_id) steam_id, name, is_current,
+				(CASE WHEN lower(name) LIKE @prefix ESCAPE '\' THEN 1 ELSE 0 END) + ` + score + ` AS score
+			FROM names
+			WHERE lower(name) LIKE @prefix ESCAPE '\' OR ` + match + `
+			ORDER BY steam_id, score DESC, is_current DESC
+		)
+		SELECT m.steam_id, COALESCE(p.username, m.name) AS username, m.name AS matched_name, m.is_current, m.score,
+			p.clan_id, COALESCE(p.is_online, false) AS is_online, COALESCE(p.killed_players, 0) AS killed_players
+		FROM matched m
+		LEFT JOIN players p ON p.steam_id = m.steam_id
+		ORDER BY m.score DESC, m.is_current DESC, m.steam_id ASC
+		LIMIT @limit`
+
+	results := []playerSearchResult{}
+	if err := database.DB.Raw(sql, args).Scan(&results).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetPlayerNames returns name history of the player (newest first)
+// GET /api/players/{steamid}/names
+func GetPlayerNames(w http.ResponseWriter, r *http.Request) {
+	steamID := mux.Vars(r)["steamid"]
+	if !checkSteamID(r, "players/names", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
+	names := []models.PlayerName{}
+	if err := database.DB.Where("steam_id = ?", steamID).Order("seen_at DESC, id DESC").Find(&names).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var player models.Player
+	hasPlayer := database.DB.Where("steam_id = ?", steamID).First(&player).Error == nil
+	if !hasPlayer && len(names) == 0 {
+		http.Error(w, "player not found", http.StatusNotFound)
+		return
+	}
+	// Ник не менялся с момента появления истории — показываем текущий
+	if hasPlayer && (len(names) == 0 || names[0].Name != player.Username) {
+		names = append([]models.PlayerName{{SteamID: steamID, Name: player.Username, SeenAt: player.FirstConnectDate}}, names...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(names)
+}
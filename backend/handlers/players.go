@@ -16,7 +16,7 @@ func GetPlayers(w http.ResponseWriter, r *http.Request) {
 	clanID := r.URL.Query().Get("clanId")
 	withStats := r.URL.Query().Get("stats") == "true"
 
-	query := database.DB.Order("killed_players DESC").Preload("Clan")
+	query := database.DB.Order("killed_players DESC, steam_id ASC").Preload("Clan")
 	if onlineOnly {
 		query = query.Where("is_online = ?", true)
 	}
@@ -29,13 +29,34 @@ func GetPlayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Место в общем рейтинге по убийствам (одинаковые значения — одно место), без запроса на каждого игрока
+	var ranks []struct {
+		SteamID string
+		Rank    int
+	}
+	database.DB.Raw("SELECT steam_id, RANK() OVER (ORDER BY killed_players DESC) AS rank FROM players").Scan(&ranks)
+	rankBySteamID := make(map[string]int, len(ranks))
+	for _, rk := range ranks {
+		rankBySteamID[rk.SteamID] = rk.Rank
+	}
+
+	statsBySteamID := make(map[string]models.PlayerStats)
+	if withStats && len(players) > 0 {
+		steamIDs := make([]string, 0, len(players))
+		for _, p := range players {
+			steamIDs = append(steamIDs, p.SteamID)
+		}
+		var stats []models.PlayerStats
+		database.DB.Where("steam_id IN ?", steamIDs).Find(&stats)
+		for _, st := range stats {
+			statsBySteamID[st.SteamID] = st
+		}
+	}
+
 	for i := range players {
-		players[i].RankPosition = i + 1
-		if withStats {
-			var stats models.PlayerStats
-			if err := database.DB.Where("steam_id = ?", players[i].SteamID).First(&stats).Error; err == nil {
-				players[i].Stats = &stats
-			}
+		players[i].RankPosition = rankBySteamID[players[i].SteamID]
+		if st, ok := statsBySteamID[players[i].SteamID]; ok {
+			players[i].Stats = &st
 		}
 	}
 
@@ -46,6 +67,10 @@ func GetPlayers(w http.ResponseWriter, r *http.Request) {
 func GetPlayer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	steamID := vars["steamid"]
+	if !checkSteamID(r, "players", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
 
 	var player models.Player
 	if err := database.DB.Preload("Clan").Where("steam_id = ?", steamID).First(&player).Error; err != nil {
@@ -62,6 +87,7 @@ func GetPlayer(w http.ResponseWriter, r *http.Request) {
 	if err := database.DB.Where("steam_id = ?", steamID).First(&stats).Error; err == nil {
 		player.Stats = &stats
 	}
+	player.Achievements = playerAchievements(steamID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(player)
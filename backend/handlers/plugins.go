@@ -7,18 +7,19 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
 
 	"github.com/gorilla/mux"
 )
 
 func GetPlugins(w http.ResponseWriter, r *http.Request) {
 	var plugins []models.Plugin
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 	serverIDStr := r.URL.Query().Get("serverId")
 
 	query := database.DB.Preload("Commands").Order("\"order\" ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 	if serverIDStr != "" {
 		if serverID, err := strconv.ParseUint(serverIDStr, 10, 32); err == nil && serverID > 0 {
@@ -30,6 +31,7 @@ func GetPlugins(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	plugins = i18n.Resolve(plugins, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(plugins)
@@ -42,6 +44,7 @@ func CreatePlugin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ensureTranslationGroup(&plugin.TranslationGroup)
 	if err := database.DB.Create(&plugin).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -67,6 +70,7 @@ func UpdatePlugin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ensureTranslationGroup(&plugin.TranslationGroup)
 	if err := database.DB.Save(&plugin).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/rcon"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// GetRconAllowlist returns console commands allowed in shop templates (admin only)
+// GET /api/admin/rcon/allowlist
+func GetRconAllowlist(w http.ResponseWriter, r *http.Request) {
+	var cmds []models.RconAllowedCommand
+	if err := database.DB.Order("command ASC").Find(&cmds).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cmds)
+}
+
+func CreateRconAllowedCommand(w http.ResponseWriter, r *http.Request) {
+	var cmd models.RconAllowedCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cmd.ID = 0
+	cmd.Command = rcon.CommandName(cmd.Command)
+	if cmd.Command == "" || strings.Contains(cmd.Command, "{{") {
+		http.Error(w, "command name required", http.StatusBadRequest)
+		return
+	}
+	var existing models.RconAllowedCommand
+	if database.DB.Where("command = ?", cmd.Command).First(&existing).Error == nil {
+		http.Error(w, "command already allowed", http.StatusConflict)
+		return
+	}
+	if err := database.DB.Create(&cmd).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cmd)
+}
+
+func DeleteRconAllowedCommand(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	if err := database.DB.Delete(&models.RconAllowedCommand{}, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewOrderCommands - dry run: shows rendered RCON commands of the order without executing them
+// GET /api/admin/orders/{id}/rcon-preview
+func PreviewOrderCommands(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	var order models.Order
+	if err := database.DB.First(&order, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	type previewLine struct {
+		Command string `json:"command"`
+		Name    string `json:"name"`
+		Allowed bool   `json:"allowed"`
+	}
+	resp := map[string]interface{}{
+		"orderId":      order.ID,
+		"template":     order.RconCommand,
+		"vars":         orderCommandVars(&order),
+		"rconExecuted": order.RconExecuted,
+		"rconStep":     order.RconStep, // выполнено строк (повтор продолжит со следующей)
+		"rconTries":    order.RconTries,
+		"rconError":    order.RconError,
+		"needsReview":  order.RconNeedsReview, // строка rconStep+1 могла выполниться, см. ReviewOrderDelivery
+	}
+	cmds, err := rcon.Render(rcon.LegacyTemplate(order.RconCommand), orderCommandVars(&order))
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	allowed := rconAllowlist()
+	lines := make([]previewLine, 0, len(cmds))
+	valid := err == nil
+	for _, c := range cmds {
+		name := rcon.CommandName(c)
+		lines = append(lines, previewLine{Command: c, Name: name, Allowed: allowed[name]})
+		if !allowed[name] {
+			valid = false
+		}
+	}
+	resp["commands"] = lines
+	resp["valid"] = valid
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetOrdersNeedingReview lists paid orders whose delivery stopped on a line with unknown outcome
+// GET /api/admin/orders/rcon-review
+func GetOrdersNeedingReview(w http.ResponseWriter, r *http.Request) {
+	orders := []models.Order{}
+	if err := database.DB.Where("rcon_needs_review = ? AND rcon_executed = ?", true, false).Order("id ASC").Find(&orders).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	type reviewOrder struct {
+		models.Order
+		RconStep  int    `json:"rconStep"`
+		RconError string `json:"rconError"`
+	}
+	list := make([]reviewOrder, 0, len(orders))
+	for _, o := range orders {
+		list = append(list, reviewOrder{Order: o, RconStep: o.RconStep, RconError: o.RconError})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// ReviewOrderDelivery resolves an order marked for review after the admin checked the server:
+// "retry" runs the line again (it was not executed), "skip" counts it as executed; delivery then continues
+// POST /api/admin/orders/{id}/rcon-review
+func ReviewOrderDelivery(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req struct {
+		Action string `json:"action"` // retry | skip
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	update := map[string]interface{}{"rcon_needs_review": false, "rcon_tries": 0, "rcon_error": ""}
+	switch req.Action {
+	case "retry":
+	case "skip":
+		update["rcon_step"] = gorm.Expr("rcon_step + 1")
+	default:
+		http.Error(w, "action must be retry or skip", http.StatusBadRequest)
+		return
+	}
+	res := database.DB.Model(&models.Order{}).Where("id = ? AND rcon_needs_review = ?", id, true).Updates(update)
+	if res.Error != nil {
+		http.Error(w, res.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if res.RowsAffected == 0 {
+		http.Error(w, "order does not need review", http.StatusConflict)
+		return
+	}
+	var order models.Order
+	if err := database.DB.First(&order, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deliverErr := deliverOrder(&order)
+	resp := map[string]interface{}{
+		"orderId":      order.ID,
+		"status":       order.Status,
+		"rconExecuted": order.RconExecuted,
+		"rconStep":     order.RconStep,
+		"needsReview":  order.RconNeedsReview,
+	}
+	if deliverErr != nil {
+		resp["error"] = deliverErr.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/rcon"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	consoleHistoryLimit  = 200 // команд на админа храним в БД
+	consoleHistorySent   = 50  // отдаём при подключении
+	consolePingInterval  = 30 * time.Second
+	consoleWriteTimeout  = 10 * time.Second
+	consoleTicketTTL     = 30 * time.Second
+	consoleTicketPurpose = "rcon-console"
+)
+
+var consoleUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     consoleOriginAllowed,
+}
+
+// consoleOriginAllowed - браузер всегда шлёт Origin: пускаем только SITE_URL и тот же хост, что у запроса
+func consoleOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true // не браузер; доступ всё равно по одноразовому тикету
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme+"://"+u.Host, SiteURL) || strings.EqualFold(u.Host, r.Host)
+}
+
+// CreateRconConsoleTicket issues a single-use ticket for opening the console WebSocket (JWT is not put into the URL)
+// POST /api/admin/rcon/console/ticket -> {ticket, expiresIn}
+func CreateRconConsoleTicket(w http.ResponseWriter, r *http.Request) {
+	claims := getClaims(r)
+	if claims == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	ticket, err := authpkg.IssueOnceToken(consoleTicketPurpose, *claims, consoleTicketTTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"ticket": ticket, "expiresIn": int(consoleTicketTTL.Seconds())})
+}
+
+// consoleMessage - сообщения консоли.
+// От клиента: command {command}, complete {prefix}.
+// От сервера: status, history, output (поток сервера), response, completions, error.
+type consoleMessage struct {
+	Type     string      `json:"type"`
+	Command  string      `json:"command,omitempty"`
+	Prefix   string      `json:"prefix,omitempty"`
+	Output   string      `json:"output,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Items    interface{} `json:"items,omitempty"`
+	ServerID uint        `json:"serverId,omitempty"`
+	At       time.Time   `json:"at"`
+}
+
+type consoleCompletion struct {
+	Command     string `json:"command"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+// RconConsole - admin-only WebSocket RCON console: streams server output and executes commands.
+// GET /api/admin/rcon/console?ticket=...&serverId=1 (ticket from CreateRconConsoleTicket; serverId optional, 0 = RCON из env)
+func RconConsole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := authpkg.RedeemOnceToken(consoleTicketPurpose, r.URL.Query().Get("ticket"))
+	if !ok {
+		http.Error(w, `{"error":"invalid or expired ticket"}`, http.StatusUnauthorized)
+		return
+	}
+	if !authpkg.IsAdmin(claims) {
+		http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+		return
+	}
+	serverID64, _ := strconv.ParseUint(r.URL.Query().Get("serverId"), 10, 32)
+	serverID := uint(serverID64)
+	target, err := rcon.ResolveTarget(serverID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	ws, err := consoleUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade уже ответил клиенту
+	}
+	defer ws.Close()
+
+	client := rcon.Get(target)
+	output, unsubscribe := client.Subscribe()
+	defer unsubscribe()
+
+	send := make(chan consoleMessage, 64)
+	done := make(chan struct{})
+	defer close(done)
+	go consoleWriter(ws, send, done)
+
+	push := func(m consoleMessage) {
+		m.At = time.Now()
+		select {
+		case send <- m:
+		case <-done:
+		}
+	}
+
+	// Поток вывода сервера (чат, убийства, заходы)
+	go func() {
+		for line := range output {
+			select {
+			case send <- consoleMessage{Type: "output", Output: line, At: time.Now()}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	status := consoleMessage{Type: "status", ServerID: serverID, Output: string(rcon.StateConnected)}
+	if err := client.Connect(); err != nil {
+		status.Output = string(rcon.StateDisconnected)
+		status.Error = err.Error()
+	}
+	push(status)
+	push(consoleMessage{Type: "history", Items: consoleHistory(claims.AdminID, consoleHistorySent)})
+
+	ws.SetReadLimit(64 * 1024)
+	ws.SetReadDeadline(time.Now().Add(2 * consolePingInterval))
+	ws.SetPongHandler(func(string) error {
+		ws.SetReadDeadline(time.Now().Add(2 * consolePingInterval))
+		return nil
+	})
+
+	for {
+		var in consoleMessage
+		if err := ws.ReadJSON(&in); err != nil {
+			return
+		}
+		switch in.Type {
+		case "command":
+			cmd := strings.TrimSpace(in.Command)
+			if cmd == "" {
+				continue
+			}
+			database.DB.Create(&models.RconConsoleCommand{AdminID: claims.AdminID, ServerID: serverID, Command: cmd})
+			trimConsoleHistory(claims.AdminID)
+			resp, err := client.Execute(cmd)
+			if err != nil {
+				log.Printf("[RconConsole] %s: %q failed: %v", claims.Username, cmd, err)
+				push(consoleMessage{Type: "error", Command: cmd, Error: err.Error()})
+				continue
+			}
+			push(consoleMessage{Type: "response", Command: cmd, Output: resp})
+		case "complete":
+			push(consoleMessage{Type: "completions", Prefix: in.Prefix, Items: consoleCompletions(in.Prefix)})
+		case "history":
+			push(consoleMessage{Type: "history", Items: consoleHistory(claims.AdminID, consoleHistorySent)})
+		default:
+			push(consoleMessage{Type: "error", Error: "unknown message type"})
+		}
+	}
+}
+
+// consoleWriter - единственный писатель в соединение (gorilla/websocket не допускает параллельную запись)
+func consoleWriter(ws *websocket.Conn, send <-chan consoleMessage, done <-chan struct{}) {
+	ticker := time.NewTicker(consolePingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case m := <-send:
+			ws.SetWriteDeadline(time.Now().Add(consoleWriteTimeout))
+			if err := ws.WriteJSON(m); err != nil {
+				ws.Close()
+				return
+			}
+		case <-ticker.C:
+			ws.SetWriteDeadline(time.Now().Add(consoleWriteTimeout))
+			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				ws.Close()
+				return
+			}
+		}
+	}
+}
+
+// consoleHistory returns last commands of the admin, oldest first (как в терминале)
+func consoleHistory(adminID uint, limit int) []models.RconConsoleCommand {
+	var cmds []models.RconConsoleCommand
+	database.DB.Where("admin_id = ?", adminID).Order("id DESC").Limit(limit).Find(&cmds)
+	for i, j := 0, len(cmds)-1; i < j; i, j = i+1, j-1 {
+		cmds[i], cmds[j] = cmds[j], cmds[i]
+	}
+	return cmds
+}
+
+func trimConsoleHistory(adminID uint) {
+	database.DB.Exec(`DELETE FROM rcon_console_commands WHERE admin_id = ? AND id NOT IN (
+		SELECT id FROM rcon_console_commands WHERE admin_id = ? ORDER BY id DESC LIMIT ?)`, adminID, adminID, consoleHistoryLimit)
+}
+
+// consoleCompletions - autocomplete from plugin commands (models.Command)
+func consoleCompletions(prefix string) []consoleCompletion {
+	prefix = strings.TrimLeft(strings.TrimSpace(prefix), "/")
+	var cmds []models.Command
+	query := database.DB.Order("command ASC").Limit(20)
+	if prefix != "" {
+		like := strings.NewReplacer("%", `\%`, "_", `\_`).Replace(prefix) + "%"
+		query = query.Where("LTRIM(command, '/') ILIKE ?", like)
+	}
+	query.Find(&cmds)
+	seen := make(map[string]bool)
+	items := make([]consoleCompletion, 0, len(cmds))
+	for _, c := range cmds {
+		if seen[c.Command] {
+			continue
+		}
+		seen[c.Command] = true
+		items = append(items, consoleCompletion{Command: c.Command, Usage: c.Usage, Description: c.Description})
+	}
+	return items
+}
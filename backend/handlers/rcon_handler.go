@@ -2,18 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"os"
-	"strconv"
+	"strings"
 
 	"rust-legacy-site/pkg/rcon"
 )
 
 type rconExecuteRequest struct {
-	Command string `json:"command"`
+	Command string `json:"command"` // шаблон: {{steamid}}, по команде на строку
 	SteamID string `json:"steamId,omitempty"`
 }
 
+// ExecuteRcon runs allowlisted commands on the env RCON target (admin only)
+// POST /api/rcon/execute
 func ExecuteRcon(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -29,35 +31,48 @@ func ExecuteRcon(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "command required", http.StatusBadRequest)
 		return
 	}
-
-	host := os.Getenv("RCON_HOST")
-	if host == "" {
-		host = "127.0.0.1"
-	}
-	port := 28016
-	if p := os.Getenv("RCON_PORT"); p != "" {
-		if v, err := strconv.Atoi(p); err == nil {
-			port = v
-		}
-	}
-	password := os.Getenv("RCON_PASSWORD")
-	if password == "" {
-		http.Error(w, "rcon not configured", http.StatusServiceUnavailable)
+	if req.SteamID != "" && !checkSteamID(r, "rcon/execute", req.SteamID) {
+		http.Error(w, "invalid steamId", http.StatusBadRequest)
 		return
 	}
 
-	var resp string
-	var err error
+	target := rcon.EnvTarget()
+	if !target.Configured() {
+		http.Error(w, errRconNotConfigured.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	// Команда — шаблон, как у товаров: {{steamid}} подставляется в кавычках, команды только из allowlist
+	vars := rcon.Vars{}
 	if req.SteamID != "" {
-		resp, err = rcon.Execute(host, port, password, req.Command, req.SteamID)
-	} else {
-		resp, err = rcon.Execute(host, port, password, req.Command, "")
+		vars["steamid"] = req.SteamID
+	}
+	cmds, err := rcon.Render(req.Command, vars)
+	if err == nil {
+		err = rcon.CheckAllowed(cmds, rconAllowlist())
 	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadGateway)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	var out []string
+	for _, cmd := range cmds {
+		resp, err := target.Execute(cmd)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%q: %v", cmd, err), http.StatusBadGateway)
+			return
+		}
+		out = append(out, resp)
+	}
+	resp := strings.Join(out, "\n")
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"response": resp})
 }
+
+// GetRconMetrics returns state and latency of persistent RCON connections (admin only)
+// GET /api/admin/rcon/metrics
+func GetRconMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rcon.AllMetrics())
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/rconjobs"
+
+	"github.com/gorilla/mux"
+)
+
+// GetRconJobs returns all scheduled RCON jobs (admin only)
+// GET /api/admin/jobs
+func GetRconJobs(w http.ResponseWriter, r *http.Request) {
+	var jobs []models.RconJob
+	if err := database.DB.Order("id ASC").Find(&jobs).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+func GetRconJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	var job models.RconJob
+	if err := database.DB.First(&job, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func CreateRconJob(w http.ResponseWriter, r *http.Request) {
+	var job models.RconJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	job.ID = 0
+	if err := prepareRconJob(&job); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := database.DB.Create(&job).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
+
+func UpdateRconJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	var job models.RconJob
+	if err := database.DB.First(&job, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var input models.RconJob
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	job.Name = input.Name
+	job.Schedule = input.Schedule
+	job.ServerID = input.ServerID
+	job.Commands = input.Commands
+	job.Enabled = input.Enabled
+	if err := prepareRconJob(&job); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := database.DB.Save(&job).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func DeleteRconJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	database.DB.Where("job_id = ?", id).Delete(&models.RconJobRun{})
+	if err := database.DB.Delete(&models.RconJob{}, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunRconJobNow executes job immediately, regardless of schedule and enabled flag
+// POST /api/admin/jobs/{id}/run
+func RunRconJobNow(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	var job models.RconJob
+	if err := database.DB.First(&job, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	run, err := rconjobs.Run(job, "manual")
+	w.Header().Set("Content-Type", "application/json")
+	if errors.Is(err, rconjobs.ErrAlreadyRunning) {
+		w.WriteHeader(http.StatusConflict)
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetRconJobRuns returns run history of the job, newest first. ?limit= (default 50)
+// GET /api/admin/jobs/{id}/runs
+func GetRconJobRuns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	var runs []models.RconJobRun
+	if err := database.DB.Where("job_id = ?", id).Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// prepareRconJob validates job and computes NextRunAt
+func prepareRconJob(job *models.RconJob) error {
+	job.Name = strings.TrimSpace(job.Name)
+	job.Schedule = strings.TrimSpace(job.Schedule)
+	if job.Name == "" {
+		return errors.New("name required")
+	}
+	if len(rconjobs.Commands(job.Commands)) == 0 {
+		return errors.New("at least one command required")
+	}
+	if job.ServerID != 0 {
+		var srv models.ServerInfo
+		if database.DB.First(&srv, job.ServerID).Error != nil {
+			return errors.New("server not found")
+		}
+	}
+	next, err := rconjobs.NextRun(job.Schedule, time.Now())
+	if err != nil {
+		return err
+	}
+	job.NextRunAt = nil
+	if job.Enabled {
+		job.NextRunAt = next
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/diff"
+	"rust-legacy-site/pkg/i18n"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+const (
+	revisionLegalDocument = "legal_document"
+	revisionRule          = "rule"
+)
+
+// revisionAuthor returns admin who made the change (from JWT claims set by AdminMiddleware)
+func revisionAuthor(r *http.Request) (*uint, string) {
+	claims := getClaims(r)
+	if claims == nil || claims.AdminID == 0 {
+		return nil, "system"
+	}
+	id := claims.AdminID
+	return &id, claims.Username
+}
+
+// nextRevisionVersion returns version number for the next revision of entity
+func nextRevisionVersion(tx *gorm.DB, entityType string, entityID uint) int {
+	var v int
+	tx.Model(&models.ContentRevision{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(version), 0)").Scan(&v)
+	return v + 1
+}
+
+func legalDocumentRevision(r *http.Request, doc models.LegalDocument, comment string) *models.ContentRevision {
+	authorID, authorName := revisionAuthor(r)
+	return &models.ContentRevision{
+		EntityType: revisionLegalDocument,
+		EntityID:   doc.ID,
+		Version:    doc.Version,
+		Language:   doc.Language,
+		Type:       doc.Type,
+		Title:      doc.Title,
+		Content:    doc.Content,
+		AuthorID:   authorID,
+		AuthorName: authorName,
+		Comment:    comment,
+	}
+}
+
+func ruleRevision(r *http.Request, rule models.Rule, comment string) *models.ContentRevision {
+	authorID, authorName := revisionAuthor(r)
+	return &models.ContentRevision{
+		EntityType: revisionRule,
+		EntityID:   rule.ID,
+		Version:    rule.Version,
+		Language:   rule.Language,
+		Title:      rule.Title,
+		Content:    rule.Content,
+		Order:      rule.Order,
+		AuthorID:   authorID,
+		AuthorName: authorName,
+		Comment:    comment,
+	}
+}
+
+// saveLegalDocument saves document and its new revision in one transaction
+func saveLegalDocument(r *http.Request, doc *models.LegalDocument, comment string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if doc.ID == 0 {
+			doc.Version = 1
+			if err := tx.Create(doc).Error; err != nil {
+				return err
+			}
+		} else {
+			doc.Version = nextRevisionVersion(tx, revisionLegalDocument, doc.ID)
+			if err := tx.Save(doc).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(legalDocumentRevision(r, *doc, comment)).Error
+	})
+}
+
+// saveRule saves rule and its new revision in one transaction
+func saveRule(r *http.Request, rule *models.Rule, comment string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if rule.ID == 0 {
+			rule.Version = 1
+			if err := tx.Create(rule).Error; err != nil {
+				return err
+			}
+		} else {
+			rule.Version = nextRevisionVersion(tx, revisionRule, rule.ID)
+			if err := tx.Save(rule).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(ruleRevision(r, *rule, comment)).Error
+	})
+}
+
+// currentLegalRevisionIDs returns IDs of legal document revisions in force for the language (через запятую, для Order)
+func currentLegalRevisionIDs(lang string) string {
+	var docs []models.LegalDocument
+	if err := database.DB.Where("language IN ?", i18n.Candidates(lang)).Find(&docs).Error; err != nil {
+		return ""
+	}
+	docs = i18n.Resolve(docs, lang)
+	var ids []string
+	for _, doc := range docs {
+		var rev models.ContentRevision
+		if database.DB.Select("id").Where("entity_type = ? AND entity_id = ? AND version = ?", revisionLegalDocument, doc.ID, doc.Version).
+			First(&rev).Error == nil {
+			ids = append(ids, strconv.FormatUint(uint64(rev.ID), 10))
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+// GetRevisions returns revision list (without content) of legal document or rule, newest first
+// GET /api/admin/revisions?entityType=legal_document&entityId=3
+func GetRevisions(w http.ResponseWriter, r *http.Request) {
+	entityType := r.URL.Query().Get("entityType")
+	entityID, _ := strconv.Atoi(r.URL.Query().Get("entityId"))
+	if entityType != revisionLegalDocument && entityType != revisionRule {
+		http.Error(w, "entityType must be legal_document or rule", http.StatusBadRequest)
+		return
+	}
+
+	var revs []models.ContentRevision
+	query := database.DB.Omit("content").Where("entity_type = ?", entityType).Order("entity_id ASC, version DESC")
+	if entityID > 0 {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if err := query.Find(&revs).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revs)
+}
+
+// GetRevision returns full revision (admin)
+// GET /api/admin/revisions/{id}
+func GetRevision(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var rev models.ContentRevision
+	if err := database.DB.First(&rev, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rev)
+}
+
+// GetLegalDocumentRevision returns legal document text as it was in the revision.
+// Public: покупатель может посмотреть условия, принятые при заказе (Order.LegalRevisionIDs).
+// GET /api/legal-documents/revisions/{id}
+func GetLegalDocumentRevision(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var rev models.ContentRevision
+	if err := database.DB.Where("entity_type = ?", revisionLegalDocument).First(&rev, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	rev.AuthorID, rev.AuthorName = nil, ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rev)
+}
+
+// blockEndRe - closing block tags; HTML is stored in one line, so diff splits it into lines after them
+var blockEndRe = regexp.MustCompile(`(?i)(</(p|li|ul|ol|h[1-6]|div|table|tr|blockquote|pre)>|<br\s*/?>)`)
+
+func revisionDiffText(html string) string {
+	return blockEndRe.ReplaceAllString(html, "$1\n")
+}
+
+// GetRevisionDiff returns line diff between revision and another one (default: previous version)
+// GET /api/admin/revisions/{id}/diff?against=12
+func GetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var to models.ContentRevision
+	if err := database.DB.First(&to, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var from models.ContentRevision
+	if againstID, _ := strconv.Atoi(r.URL.Query().Get("against")); againstID > 0 {
+		if err := database.DB.First(&from, againstID).Error; err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if from.EntityType != to.EntityType || from.EntityID != to.EntityID {
+			http.Error(w, "revisions belong to different documents", http.StatusBadRequest)
+			return
+		}
+	} else {
+		// Первая версия сравнивается с пустым документом
+		database.DB.Where("entity_type = ? AND entity_id = ? AND version < ?", to.EntityType, to.EntityID, to.Version).
+			Order("version DESC").First(&from)
+	}
+
+	lines := diff.Lines(revisionDiffText(from.Content), revisionDiffText(to.Content))
+	added, removed := diff.Stats(lines)
+	fromName := "empty"
+	if from.ID != 0 {
+		fromName = fmt.Sprintf("v%d", from.Version)
+	}
+	toName := fmt.Sprintf("v%d", to.Version)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"fromVersion":  from.Version,
+		"toVersion":    to.Version,
+		"fromTitle":    from.Title,
+		"toTitle":      to.Title,
+		"titleChanged": from.Title != to.Title,
+		"added":        added,
+		"removed":      removed,
+		"lines":        lines,
+		"unified":      diff.Unified(lines, fromName, toName, 2),
+	})
+}
+
+// RollbackRevision restores legal document or rule to the revision; rollback itself is saved as new revision
+// POST /api/admin/revisions/{id}/rollback
+func RollbackRevision(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var rev models.ContentRevision
+	if err := database.DB.First(&rev, id).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	comment := fmt.Sprintf("rollback to v%d", rev.Version)
+
+	var result interface{}
+	switch rev.EntityType {
+	case revisionLegalDocument:
+		var doc models.LegalDocument
+		if err := database.DB.First(&doc, rev.EntityID).Error; err != nil {
+			http.Error(w, "document was deleted", http.StatusNotFound)
+			return
+		}
+		doc.Language, doc.Type, doc.Title, doc.Content = rev.Language, rev.Type, rev.Title, rev.Content
+		if err := saveLegalDocument(r, &doc, comment); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = doc
+	case revisionRule:
+		var rule models.Rule
+		if err := database.DB.First(&rule, rev.EntityID).Error; err != nil {
+			http.Error(w, "rule was deleted", http.StatusNotFound)
+			return
+		}
+		rule.Language, rule.Title, rule.Content, rule.Order = rev.Language, rev.Title, rev.Content, rev.Order
+		if err := saveRule(r, &rule, comment); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = rule
+	default:
+		http.Error(w, "unknown entity type", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -7,23 +7,26 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
+	"rust-legacy-site/pkg/sanitize"
 
 	"github.com/gorilla/mux"
 )
 
 func GetRules(w http.ResponseWriter, r *http.Request) {
 	var rules []models.Rule
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 
 	query := database.DB.Order("\"order\" ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 
 	if err := query.Find(&rules).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	rules = i18n.Resolve(rules, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(rules)
@@ -36,7 +39,10 @@ func CreateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := database.DB.Create(&rule).Error; err != nil {
+	rule.ID = 0
+	rule.Content = sanitize.HTML(rule.Content)
+	ensureTranslationGroup(&rule.TranslationGroup)
+	if err := saveRule(r, &rule, ""); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -56,12 +62,22 @@ func UpdateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	prev := rule
 	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	if err := database.DB.Save(&rule).Error; err != nil {
+	rule.ID, rule.Version = prev.ID, prev.Version
+
+	rule.Content = sanitize.HTML(rule.Content)
+	ensureTranslationGroup(&rule.TranslationGroup)
+	var err error
+	if rule.Language == prev.Language && rule.Title == prev.Title && rule.Content == prev.Content && rule.Order == prev.Order {
+		err = database.DB.Save(&rule).Error // текст не менялся — новая ревизия не нужна
+	} else {
+		err = saveRule(r, &rule, "")
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/security"
+	"rust-legacy-site/pkg/steamid"
+)
+
+// checkSteamID validates SteamID64 from request; invalid value is logged as security event
+func checkSteamID(r *http.Request, source, id string) bool {
+	if steamid.Valid(id) {
+		return true
+	}
+	security.Log(security.KindInvalidSteamID, source, getClientIP(r), id, "")
+	return false
+}
+
+// filterSteamIDs drops invalid SteamIDs (each logged)
+func filterSteamIDs(r *http.Request, source string, ids []string) []string {
+	valid := ids[:0]
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if checkSteamID(r, source, id) {
+			valid = append(valid, id)
+		}
+	}
+	return valid
+}
+
+// GetSecurityEvents returns latest rejected payloads (admin only)
+// GET /api/admin/security/events?kind=invalid_steamid&limit=100
+func GetSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	query := database.DB.Order("id DESC").Limit(limit)
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var events []models.SecurityEvent
+	if err := query.Find(&events).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
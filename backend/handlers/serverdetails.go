@@ -7,19 +7,21 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
+	"rust-legacy-site/pkg/sanitize"
 
 	"github.com/gorilla/mux"
 )
 
 func GetServerDetails(w http.ResponseWriter, r *http.Request) {
 	var details []models.ServerDetail
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 	section := r.URL.Query().Get("section")
 	serverIDStr := r.URL.Query().Get("serverId")
 
 	query := database.DB.Order("\"order\" ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 	if section != "" {
 		query = query.Where("section = ?", section)
@@ -34,6 +36,7 @@ func GetServerDetails(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	details = i18n.Resolve(details, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(details)
@@ -46,6 +49,8 @@ func CreateServerDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	detail.Content = sanitize.HTML(detail.Content)
+	ensureTranslationGroup(&detail.TranslationGroup)
 	if err := database.DB.Create(&detail).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -71,6 +76,8 @@ func UpdateServerDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	detail.Content = sanitize.HTML(detail.Content)
+	ensureTranslationGroup(&detail.TranslationGroup)
 	if err := database.DB.Save(&detail).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
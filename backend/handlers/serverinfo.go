@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
@@ -30,12 +33,13 @@ func GetServerInfo(w http.ResponseWriter, r *http.Request) {
 		"maxPlayers":     serverInfo.MaxPlayers,
 		"gameVersion":    serverInfo.GameVersion,
 		"downloadUrl":    serverInfo.DownloadURL,
-		"virusTotalUrl":  serverInfo.VirusTotalURL,
+		"virusTotalUrl":  virusTotalURL(serverInfo),
+		"clientSha256":   serverInfo.ClientSHA256,
 		"type":           serverInfo.Type,
 		"ip":             serverInfo.IP,
 		"port":           serverInfo.Port,
 		"descriptions":   descriptions,
-		"downloadLinks":  downloadLinks,
+		"downloadLinks":  withMirrorHealth(downloadLinks, false),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -60,6 +64,12 @@ func UpdateServerInfo(w http.ResponseWriter, r *http.Request) {
 	serverInfo.GameVersion = input.GameVersion
 	serverInfo.DownloadURL = input.DownloadURL
 	serverInfo.VirusTotalURL = input.VirusTotalURL
+	sha, err := normalizeSHA256(input.ClientSHA256)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	serverInfo.ClientSHA256 = sha
 	serverInfo.Type = input.Type
 	serverInfo.IP = input.IP
 	serverInfo.Port = input.Port
@@ -84,22 +94,36 @@ func GetAllServers(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(servers)
 }
 
+// serverInput - ServerInfo + RCON password (hidden in ServerInfo JSON)
+type serverInput struct {
+	models.ServerInfo
+	RconPassword string `json:"rconPassword"`
+}
+
 func CreateServer(w http.ResponseWriter, r *http.Request) {
-	var input models.ServerInfo
+	var input serverInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if input.Type == "" {
-		input.Type = "classic"
+	srv := input.ServerInfo
+	srv.RconPassword = input.RconPassword
+	sha, err := normalizeSHA256(srv.ClientSHA256)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	srv.ClientSHA256 = sha
+	if srv.Type == "" {
+		srv.Type = "classic"
 	}
-	if err := database.DB.Create(&input).Error; err != nil {
+	if err := database.DB.Create(&srv).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(input)
+	json.NewEncoder(w).Encode(srv)
 }
 
 func UpdateServer(w http.ResponseWriter, r *http.Request) {
@@ -110,7 +134,7 @@ func UpdateServer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	var input models.ServerInfo
+	var input serverInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -120,11 +144,21 @@ func UpdateServer(w http.ResponseWriter, r *http.Request) {
 	srv.GameVersion = input.GameVersion
 	srv.DownloadURL = input.DownloadURL
 	srv.VirusTotalURL = input.VirusTotalURL
+	sha, err := normalizeSHA256(input.ClientSHA256)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	srv.ClientSHA256 = sha
 	srv.Type = input.Type
 	srv.IP = input.IP
 	srv.Port = input.Port
 	srv.QueryPort = input.QueryPort
 	srv.Order = input.Order
+	srv.RconPort = input.RconPort
+	if input.RconPassword != "" { // пустой пароль = оставить текущий
+		srv.RconPassword = input.RconPassword
+	}
 	if err := database.DB.Save(&srv).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -142,3 +176,23 @@ func DeleteServer(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// normalizeSHA256 validates hex SHA-256 of client archive (empty = не проверять)
+func normalizeSHA256(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+	if _, err := hex.DecodeString(s); err != nil || len(s) != 64 {
+		return "", errors.New("clientSha256 must be 64 hex characters")
+	}
+	return s, nil
+}
+
+// virusTotalURL - ссылка на отчёт VirusTotal; вместо заглушки из seed строится по ClientSHA256
+func virusTotalURL(srv models.ServerInfo) string {
+	if srv.ClientSHA256 != "" && (srv.VirusTotalURL == "" || strings.Contains(srv.VirusTotalURL, "YOUR_FILE_HASH")) {
+		return "https://www.virustotal.com/gui/file/" + srv.ClientSHA256
+	}
+	return srv.VirusTotalURL
+}
@@ -21,6 +21,7 @@ var (
 // statusCache: обновляется раз в 10 сек, отдаём готовые данные
 var (
 	statusCache     = make(map[string][]models.ServerStatus) // "all" | "classic" | "deathmatch"
+	statusCacheAt   = make(map[string]time.Time)             // время последнего обновления (для ETag/Last-Modified)
 	statusCacheMu   sync.RWMutex
 	statusCacheInit sync.Once
 )
@@ -52,6 +53,7 @@ func refreshStatusCache(serverType string) {
 		key = serverType
 	}
 	statusCache[key] = statuses
+	statusCacheAt[key] = time.Now()
 	statusCacheMu.Unlock()
 }
 
@@ -6,34 +6,10 @@ import (
 	"sync"
 	"time"
 
-	"rust-legacy-site/database"
-	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/onlinehistory"
+	"rust-legacy-site/pkg/sessions"
 )
 
-var lastHistorySave = make(map[string]time.Time)
-var lastHistoryMu sync.Mutex
-
-func saveOnlineHistory(serverType string, players int, at time.Time) {
-	lastHistoryMu.Lock()
-	if last, ok := lastHistorySave[serverType]; ok && at.Sub(last) < 2*time.Minute {
-		lastHistoryMu.Unlock()
-		return
-	}
-	lastHistorySave[serverType] = at
-	lastHistoryMu.Unlock()
-
-	var srv models.ServerInfo
-	if err := database.DB.Where("type = ?", serverType).First(&srv).Error; err != nil {
-		return
-	}
-	database.DB.Create(&models.OnlineHistory{
-		ServerID:   srv.ID,
-		ServerType: serverType,
-		Players:    players,
-		RecordedAt: at,
-	})
-}
-
 // Reported online per server type (from TopSystem/plugin)
 var (
 	reportedOnline     = make(map[string]onlineReport)
@@ -72,23 +48,59 @@ func ReportServerOnline(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	now := time.Now()
+	for serverType, rep := range map[string]*onlineReport{"classic": p.Classic, "deathmatch": p.Deathmatch} {
+		if rep != nil {
+			sanitizeOnlineReport(r, rep)
+			if players, ok := rep.playerSet(); ok {
+				sessions.Observe(serverType, players, now)
+			}
+		}
+	}
 	reportedOnlineMu.Lock()
 	defer reportedOnlineMu.Unlock()
-	now := time.Now()
 	if p.Classic != nil {
 		p.Classic.ReportedAt = now
 		reportedOnline["classic"] = *p.Classic
-		saveOnlineHistory("classic", p.Classic.CurrentPlayers, now)
+		onlinehistory.Report("classic", p.Classic.CurrentPlayers, now)
 	}
 	if p.Deathmatch != nil {
 		p.Deathmatch.ReportedAt = now
 		reportedOnline["deathmatch"] = *p.Deathmatch
-		saveOnlineHistory("deathmatch", p.Deathmatch.CurrentPlayers, now)
+		onlinehistory.Report("deathmatch", p.Deathmatch.CurrentPlayers, now)
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"ok": "true"})
 }
 
+// sanitizeOnlineReport drops players with invalid SteamID
+func sanitizeOnlineReport(r *http.Request, rep *onlineReport) {
+	rep.SteamIDs = filterSteamIDs(r, "server/report", rep.SteamIDs)
+	players := rep.Players[:0]
+	for _, pl := range rep.Players {
+		if checkSteamID(r, "server/report", pl.SteamID) {
+			players = append(players, pl)
+		}
+	}
+	rep.Players = players
+}
+
+// playerSet returns steamID -> username of reported players. ok=false when the report has only
+// the counter (без списка нельзя понять, кто зашёл и вышел)
+func (rep *onlineReport) playerSet() (map[string]string, bool) {
+	if len(rep.Players) == 0 && len(rep.SteamIDs) == 0 && rep.CurrentPlayers > 0 {
+		return nil, false
+	}
+	players := make(map[string]string, len(rep.Players)+len(rep.SteamIDs))
+	for _, id := range rep.SteamIDs {
+		players[id] = ""
+	}
+	for _, pl := range rep.Players {
+		players[pl.SteamID] = pl.Username
+	}
+	return players, true
+}
+
 func getReportedOnline(serverType string) (players int, valid bool) {
 	reportedOnlineMu.RLock()
 	r, ok := reportedOnline[serverType]
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+
+	"github.com/gorilla/mux"
+)
+
+type playerSessionsResponse struct {
+	SteamID      string                 `json:"steamId"`
+	Days         int                    `json:"days"`
+	IsOnline     bool                   `json:"isOnline"`
+	LastSeen     *time.Time             `json:"lastSeen"`
+	PlaySeconds  int64                  `json:"playSeconds"` // за период, включая текущую сессию
+	SessionCount int64                  `json:"sessionCount"`
+	Sessions     []models.PlayerSession `json:"sessions"`
+}
+
+// sessionDays parses ?days= (default 30, max 365)
+func sessionDays(r *http.Request) int {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 {
+		days = 30
+	}
+	if days > 365 {
+		days = 365
+	}
+	return days
+}
+
+// GetPlayerSessions returns sessions of the player with total playtime and last seen
+// GET /api/players/{steamid}/sessions?days=30&limit=50
+func GetPlayerSessions(w http.ResponseWriter, r *http.Request) {
+	steamID := mux.Vars(r)["steamid"]
+	if !checkSteamID(r, "players/sessions", steamID) {
+		http.Error(w, "invalid steamid", http.StatusBadRequest)
+		return
+	}
+	days := sessionDays(r)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	resp := playerSessionsResponse{SteamID: steamID, Days: days, Sessions: []models.PlayerSession{}}
+
+	// Сессии, пересекающиеся с периодом; время считается только внутри периода
+	var agg struct {
+		PlaySeconds  int64
+		SessionCount int64
+	}
+	if err := database.DB.Raw(`SELECT
+			COALESCE(SUM(EXTRACT(EPOCH FROM (COALESCE(ended_at, now()) - GREATEST(started_at, ?))))::bigint, 0) AS play_seconds,
+			COUNT(*) AS session_count
+		FROM player_sessions
+		WHERE steam_id = ? AND COALESCE(ended_at, now()) > ?`, since, steamID, since).Scan(&agg).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.PlaySeconds, resp.SessionCount = agg.PlaySeconds, agg.SessionCount
+
+	database.DB.Where("steam_id = ? AND COALESCE(ended_at, now()) > ?", steamID, since).
+		Order("started_at DESC").Limit(limit).Find(&resp.Sessions)
+
+	var last models.PlayerSession
+	if database.DB.Where("steam_id = ?", steamID).Order("last_seen_at DESC").First(&last).Error == nil {
+		resp.IsOnline = last.EndedAt == nil
+		resp.LastSeen = &last.LastSeenAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+type activityDay struct {
+	Day           time.Time `json:"day"`
+	ActivePlayers int64     `json:"activePlayers"` // DAU: разные игроки за день
+	NewPlayers    int64     `json:"newPlayers"`    // первая сессия за всю историю
+	Sessions      int64     `json:"sessions"`      // начатые за день
+	PlaySeconds   int64     `json:"playSeconds"`
+	Peak          int64     `json:"peak"` // пиковый одновременный онлайн
+}
+
+// GetServerActivity returns daily active players, playtime and peak concurrency from sessions
+// GET /api/server-status/activity?days=30&type=classic
+func GetServerActivity(w http.ResponseWriter, r *http.Request) {
+	days := sessionDays(r)
+	serverType := r.URL.Query().Get("type")
+	now := time.Now().UTC()
+	from := now.Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+
+	args := map[string]interface{}{"from": from, "now": now, "type": serverType}
+	// День события: @from + N суток (совпадает с generate_series в days), всё считается группировкой за один проход
+	day := func(col string) string {
+		return "(CAST(@from AS timestamptz) + floor(EXTRACT(EPOCH FROM (" + col + " - CAST(@from AS timestamptz))) / 86400)::int * interval '1 day')"
+	}
+	sql := `WITH s AS (
+			SELECT steam_id, started_at, COALESCE(ended_at, @now) AS ended_at
+			FROM player_sessions
+			WHERE COALESCE(ended_at, @now) > @from AND (@type = '' OR server_type = @type)
+		), days AS (
+			SELECT generate_series(CAST(@from AS timestamptz), CAST(@now AS timestamptz), interval '1 day') AS day
+		), s_days AS (
+			-- сессия по каждому дню, который она пересекает
+			SELECT d.day, s.steam_id, s.started_at < d.day AS carried,
+				EXTRACT(EPOCH FROM (LEAST(s.ended_at, d.day + interval '1 day') - GREATEST(s.started_at, d.day))) AS secs
+			FROM s CROSS JOIN LATERAL generate_series(` + day("GREATEST(s.started_at, CAST(@from AS timestamptz))") + `, s.ended_at, interval '1 day') AS d(day)
+			WHERE s.ended_at > d.day
+		), overlap AS (
+			SELECT day, COUNT(DISTINCT steam_id) AS active_players, SUM(secs)::bigint AS play_seconds,
+				COUNT(*) FILTER (WHERE carried) AS carried
+			FROM s_days GROUP BY day
+		), started AS (
+			SELECT ` + day("started_at") + ` AS day, COUNT(*) AS sessions FROM s WHERE started_at >= @from GROUP BY 1
+		), first_seen AS (
+			SELECT MIN(started_at) AS first_at FROM player_sessions
+			WHERE (@type = '' OR server_type = @type) GROUP BY steam_id HAVING MIN(started_at) >= @from
+		), new_players AS (
+			SELECT ` + day("first_at") + ` AS day, COUNT(*) AS new_players FROM first_seen GROUP BY 1
+		), ev AS (
+			SELECT started_at AS t, 1 AS d FROM s
+			UNION ALL
+			SELECT ended_at, -1 FROM s WHERE ended_at < @now
+		), running AS (
+			-- +1 на входе, -1 на выходе, нарастающий итог (выходы раньше входов в одну секунду)
+			SELECT t, SUM(d) OVER (ORDER BY t, d ROWS UNBOUNDED PRECEDING) AS c FROM ev
+		), peaks AS (
+			SELECT ` + day("t") + ` AS day, MAX(c) AS peak FROM running WHERE t >= @from GROUP BY 1
+		)
+		SELECT d.day,
+			COALESCE(o.active_players, 0) AS active_players,
+			COALESCE(n.new_players, 0) AS new_players,
+			COALESCE(st.sessions, 0) AS sessions,
+			COALESCE(o.play_seconds, 0) AS play_seconds,
+			-- пик дня: максимум внутри дня или онлайн на его начало (сессии, перешедшие с прошлых суток)
+			GREATEST(COALESCE(p.peak, 0), COALESCE(o.carried, 0)) AS peak
+		FROM days d
+		LEFT JOIN overlap o ON o.day = d.day
+		LEFT JOIN started st ON st.day = d.day
+		LEFT JOIN new_players n ON n.day = d.day
+		LEFT JOIN peaks p ON p.day = d.day
+		ORDER BY d.day ASC`
+
+	result := []activityDay{}
+	if err := database.DB.Raw(sql, args).Scan(&result).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
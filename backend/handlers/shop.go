@@ -7,23 +7,25 @@ import (
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/i18n"
 
 	"github.com/gorilla/mux"
 )
 
 func GetShopCategories(w http.ResponseWriter, r *http.Request) {
 	var categories []models.ShopCategory
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 
 	query := database.DB.Order("\"order\" ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 
 	if err := query.Find(&categories).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	categories = i18n.Resolve(categories, lang)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(categories)
@@ -36,6 +38,7 @@ func CreateShopCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ensureTranslationGroup(&category.TranslationGroup)
 	if err := database.DB.Create(&category).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -61,6 +64,7 @@ func UpdateShopCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ensureTranslationGroup(&category.TranslationGroup)
 	if err := database.DB.Save(&category).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -84,22 +88,25 @@ func DeleteShopCategory(w http.ResponseWriter, r *http.Request) {
 
 func GetShopItems(w http.ResponseWriter, r *http.Request) {
 	var items []models.ShopItem
-	lang := r.URL.Query().Get("lang")
+	lang := contentLang(w, r)
 	categoryID := r.URL.Query().Get("categoryId")
 
 	query := database.DB.Order("\"order\" ASC")
 	if lang != "" {
-		query = query.Where("language = ?", lang)
+		query = query.Where("language IN ?", i18n.Candidates(lang))
 	}
 	if categoryID != "" {
+		// categoryId — категория на языке клиента; товары с фолбэка лежат в её языковой версии
 		catID, _ := strconv.Atoi(categoryID)
-		query = query.Where("category_id = ?", catID)
+		query = query.Where(`category_id IN (SELECT c.id FROM shop_categories c, shop_categories s
+			WHERE s.id = ? AND (c.id = s.id OR (s.translation_group <> '' AND c.translation_group = s.translation_group)))`, catID)
 	}
 
 	if err := query.Find(&items).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	items = i18n.Resolve(items, lang)
 
 	type ItemResponse struct {
 		models.ShopItem
@@ -130,6 +137,15 @@ func CreateShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateShopCommand(input.RconCommand); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateShopItemQuantity(input.ShopItem); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if len(input.Features) > 0 {
 		featuresJSON, _ := json.Marshal(input.Features)
 		input.ShopItem.Features = string(featuresJSON)
@@ -137,6 +153,7 @@ func CreateShopItem(w http.ResponseWriter, r *http.Request) {
 		input.ShopItem.Features = "[]"
 	}
 
+	ensureTranslationGroup(&input.ShopItem.TranslationGroup)
 	if err := database.DB.Create(&input.ShopItem).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -167,6 +184,15 @@ func UpdateShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateShopCommand(input.RconCommand); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateShopItemQuantity(input.ShopItem); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if len(input.Features) > 0 {
 		featuresJSON, _ := json.Marshal(input.Features)
 		input.ShopItem.Features = string(featuresJSON)
@@ -174,6 +200,9 @@ func UpdateShopItem(w http.ResponseWriter, r *http.Request) {
 		input.ShopItem.Features = "[]"
 	}
 
+	if input.TranslationGroup == "" {
+		input.TranslationGroup = item.TranslationGroup
+	}
 	item = input.ShopItem
 	item.ID = uint(id)
 
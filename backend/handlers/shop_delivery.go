@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/rcon"
+	"rust-legacy-site/pkg/security"
+	"rust-legacy-site/pkg/steamid"
+)
+
+var errRconNotConfigured = errors.New("rcon not configured")
+
+// rconAllowlist returns allowed console command names (lower case)
+func rconAllowlist() map[string]bool {
+	var names []string
+	database.DB.Model(&models.RconAllowedCommand{}).Pluck("command", &names)
+	allowed := make(map[string]bool, len(names))
+	for _, n := range names {
+		allowed[strings.ToLower(n)] = true
+	}
+	return allowed
+}
+
+// validateShopCommand checks item command template: known placeholders + allowlisted commands
+func validateShopCommand(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return nil
+	}
+	if err := rcon.ValidateTemplate(template); err != nil {
+		return err
+	}
+	return rcon.CheckAllowed(rcon.Lines(template), rconAllowlist())
+}
+
+// shopItemMaxQuantityLimit - верхняя граница MaxQuantity товара
+const shopItemMaxQuantityLimit = 100
+
+// validateShopItemQuantity checks MaxQuantity: more than one per order is only allowed
+// when the delivery command uses {{quantity}}, otherwise the buyer pays N× and gets one item
+func validateShopItemQuantity(item models.ShopItem) error {
+	if item.MaxQuantity < 0 || item.MaxQuantity > shopItemMaxQuantityLimit {
+		return fmt.Errorf("maxQuantity must be 0-%d", shopItemMaxQuantityLimit)
+	}
+	if item.MaxQuantity > 1 && !rcon.HasPlaceholder(item.RconCommand, "quantity") {
+		return fmt.Errorf("maxQuantity > 1 requires {{quantity}} in rconCommand")
+	}
+	return nil
+}
+
+// shopItemMaxQuantity returns how many units of item can be bought in one order
+func shopItemMaxQuantity(item models.ShopItem) int {
+	if item.MaxQuantity <= 1 || !rcon.HasPlaceholder(item.RconCommand, "quantity") {
+		return 1
+	}
+	return item.MaxQuantity
+}
+
+// orderCommandVars returns placeholder values for the order
+func orderCommandVars(order *models.Order) rcon.Vars {
+	var username string
+	var player models.Player
+	if database.DB.Select("username").Where("steam_id = ?", order.SteamID).First(&player).Error == nil {
+		username = player.Username
+	}
+	qty := order.Quantity
+	if qty < 1 {
+		qty = 1
+	}
+	return rcon.Vars{
+		"steamid":  order.SteamID,
+		"username": username,
+		"quantity": strconv.Itoa(qty),
+		"order_id": strconv.Itoa(int(order.ID)),
+	}
+}
+
+// renderOrderCommands renders order command template and checks it against the allowlist
+func renderOrderCommands(order *models.Order) ([]string, error) {
+	if !steamid.Valid(order.SteamID) {
+		security.Log(security.KindInvalidSteamID, "order", "", order.SteamID, fmt.Sprintf("order %d", order.ID))
+		return nil, fmt.Errorf("order %d: invalid steamId", order.ID)
+	}
+	vars := orderCommandVars(order)
+	for name, v := range vars {
+		if rcon.Suspicious(v) {
+			// значение всё равно экранируется и берётся в кавычки, но фиксируем попытку
+			security.Log(security.KindRconInjection, "order", "", v, fmt.Sprintf("order %d {{%s}}", order.ID, name))
+		}
+	}
+	cmds, err := rcon.Render(rcon.LegacyTemplate(order.RconCommand), vars)
+	if err != nil {
+		return nil, err
+	}
+	if err := rcon.CheckAllowed(cmds, rconAllowlist()); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+// orderDeliveryMaxTries - после стольких ошибок заказ больше не повторяется автоматически
+const orderDeliveryMaxTries = 10
+
+var (
+	errOrderDelivering  = errors.New("order delivery is already in progress")
+	errOrderNeedsReview = errors.New("order delivery needs review")
+)
+
+// runCommandSteps executes cmds starting at line step and calls done after each executed line.
+// Returns how many lines are executed in total and the error of the failed line.
+func runCommandSteps(cmds []string, step int, exec func(string) error, done func(step int)) (int, error) {
+	for ; step < len(cmds); step++ {
+		if err := exec(cmds[step]); err != nil {
+			return step, fmt.Errorf("%q: %w", cmds[step], err)
+		}
+		done(step + 1)
+	}
+	return step, nil
+}
+
+// deliverOrder executes order commands via RCON starting at order.RconStep and marks order as delivered.
+// Progress is stored after each command, so a retry never repeats commands that already succeeded.
+// A line whose outcome is unknown (timeout or lost connection after sending) is not retried:
+// the order is marked for review, see ReviewOrderDelivery.
+func deliverOrder(order *models.Order) error {
+	if order.RconCommand == "" || order.SteamID == "" || order.RconExecuted {
+		return nil
+	}
+	target := rcon.EnvTarget()
+	if !target.Configured() {
+		return errRconNotConfigured
+	}
+	// Захват заказа: вебхук, оплата балансом и повтор не должны выдавать одно и то же параллельно
+	res := database.DB.Model(&models.Order{}).
+		Where("id = ? AND rcon_executed = ? AND rcon_delivering = ? AND rcon_needs_review = ?", order.ID, false, false, false).
+		Update("rcon_delivering", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var n int64
+		database.DB.Model(&models.Order{}).Where("id = ? AND rcon_needs_review = ?", order.ID, true).Count(&n)
+		if n > 0 {
+			return errOrderNeedsReview
+		}
+		return errOrderDelivering
+	}
+	var current models.Order
+	if err := database.DB.Select("rcon_step", "rcon_tries").First(&current, order.ID).Error; err != nil {
+		database.DB.Model(order).Update("rcon_delivering", false)
+		return err
+	}
+	order.RconStep, order.RconTries = current.RconStep, current.RconTries
+
+	cmds, err := renderOrderCommands(order)
+	if err == nil {
+		order.RconStep, err = runCommandSteps(cmds, order.RconStep, func(cmd string) error {
+			_, err := target.Execute(cmd)
+			return err
+		}, func(step int) {
+			database.DB.Model(order).Update("rcon_step", step)
+		})
+	}
+	if err != nil {
+		order.RconTries++
+		order.RconError = err.Error()
+		order.RconNeedsReview = rcon.OutcomeUnknown(err)
+		database.DB.Model(order).Updates(map[string]interface{}{
+			"rcon_delivering":   false,
+			"rcon_tries":        order.RconTries,
+			"rcon_error":        order.RconError,
+			"rcon_needs_review": order.RconNeedsReview,
+		})
+		if order.RconNeedsReview {
+			log.Printf("[Shop] order %d: line %d may have been executed, needs review: %v", order.ID, order.RconStep+1, err)
+		}
+		return err
+	}
+	order.RconExecuted, order.RconError = true, ""
+	if order.Status == "paid" {
+		order.Status = "delivered"
+	}
+	return database.DB.Model(order).Updates(map[string]interface{}{
+		"rcon_executed":   true,
+		"rcon_delivering": false,
+		"rcon_error":      "",
+		"status":          order.Status,
+	}).Error
+}
+
+// RetryOrderDeliveries re-delivers paid shop orders whose RCON delivery failed before the command was sent.
+// Orders marked for review are skipped. Called from main.
+func RetryOrderDeliveries() {
+	if !rcon.EnvTarget().Configured() {
+		return
+	}
+	var orders []models.Order
+	database.DB.Where("order_type = ? AND status = ? AND rcon_executed = ? AND rcon_delivering = ? AND rcon_needs_review = ? AND rcon_command <> '' AND rcon_tries < ?",
+		"shop", "paid", false, false, false, orderDeliveryMaxTries).
+		Order("id ASC").Find(&orders)
+	for i := range orders {
+		if err := deliverOrder(&orders[i]); err != nil && err != errOrderDelivering && err != errOrderNeedsReview {
+			log.Printf("[Shop] retry delivery of order %d (try %d/%d): %v", orders[i].ID, orders[i].RconTries, orderDeliveryMaxTries, err)
+		} else if err == nil {
+			log.Printf("[Shop] order %d delivered on retry", orders[i].ID)
+		}
+	}
+}
+
+// ReleaseOrderDeliveries clears delivery claims left by a restart during delivery (продолжится с RconStep).
+// Called once on startup.
+func ReleaseOrderDeliveries() {
+	database.DB.Model(&models.Order{}).Where("rcon_delivering = ?", true).Update("rcon_delivering", false)
+}
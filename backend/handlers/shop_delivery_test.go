@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"rust-legacy-site/pkg/rcon"
+)
+
+func TestRunCommandSteps(t *testing.T) {
+	errDial := fmt.Errorf("rcon connect: %w", errors.New("connection refused"))
+	cmds := []string{"a", "b", "c"}
+	tests := []struct {
+		name        string
+		step        int
+		fail        map[string]error
+		wantStep    int
+		wantRun     []string
+		wantErr     bool
+		wantUnknown bool
+	}{
+		{name: "all", step: 0, wantStep: 3, wantRun: []string{"a", "b", "c"}},
+		{name: "resume from saved step", step: 2, wantStep: 3, wantRun: []string{"c"}},
+		{name: "already done", step: 3, wantStep: 3},
+		{name: "dial error is retryable", step: 0, fail: map[string]error{"b": errDial}, wantStep: 1, wantRun: []string{"a", "b"}, wantErr: true},
+		{name: "timeout needs review", step: 1, fail: map[string]error{"b": rcon.ErrTimeout}, wantStep: 1, wantRun: []string{"b"}, wantErr: true, wantUnknown: true},
+		{name: "disconnect needs review", step: 0, fail: map[string]error{"c": rcon.ErrDisconnected}, wantStep: 2, wantRun: []string{"a", "b", "c"}, wantErr: true, wantUnknown: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var run []string
+			var saved []int
+			step, err := runCommandSteps(cmds, tt.step, func(cmd string) error {
+				run = append(run, cmd)
+				return tt.fail[cmd]
+			}, func(s int) {
+				saved = append(saved, s)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if step != tt.wantStep {
+				t.Errorf("step = %d, want %d", step, tt.wantStep)
+			}
+			if !reflect.DeepEqual(run, tt.wantRun) {
+				t.Errorf("executed %v, want %v", run, tt.wantRun)
+			}
+			// прогресс сохраняется после каждой выполненной строки
+			for i, s := range saved {
+				if s != tt.step+i+1 {
+					t.Errorf("saved steps %v, want consecutive from %d", saved, tt.step+1)
+					break
+				}
+			}
+			if len(saved) != tt.wantStep-tt.step {
+				t.Errorf("saved %d steps, want %d", len(saved), tt.wantStep-tt.step)
+			}
+			if got := rcon.OutcomeUnknown(err); got != tt.wantUnknown {
+				t.Errorf("OutcomeUnknown(%v) = %v, want %v", err, got, tt.wantUnknown)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
@@ -36,17 +37,19 @@ var defaultSiteConfig = SiteConfig{
 
 // GetSiteConfig returns site config (public). Used for floating social links and wipe countdown.
 func GetSiteConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loadSiteConfig())
+}
+
+// loadSiteConfig reads config from settings merged with defaults
+func loadSiteConfig() SiteConfig {
 	var setting models.Setting
 	if err := database.DB.Where("key = ?", siteConfigKey).First(&setting).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(defaultSiteConfig)
-		return
+		return defaultSiteConfig
 	}
 	var cfg SiteConfig
 	if err := json.Unmarshal([]byte(setting.Value), &cfg); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(defaultSiteConfig)
-		return
+		return defaultSiteConfig
 	}
 	// Merge with defaults for missing fields
 	if cfg.VKUrl == "" {
@@ -64,8 +67,27 @@ func GetSiteConfig(w http.ResponseWriter, r *http.Request) {
 	if cfg.PartialWipe.Weekday == 0 && cfg.PartialWipe.Hour == 0 && cfg.PartialWipe.Minute == 0 {
 		cfg.PartialWipe = defaultSiteConfig.PartialWipe
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cfg)
+	return cfg
+}
+
+// Next returns the next wipe moment after now (UTC)
+func (ws WipeSchedule) Next(now time.Time) time.Time {
+	now = now.UTC()
+	t := time.Date(now.Year(), now.Month(), now.Day(), ws.Hour, ws.Minute, 0, 0, time.UTC)
+	t = t.AddDate(0, 0, (ws.Weekday-int(now.Weekday())+7)%7)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 7)
+	}
+	return t
+}
+
+// nextWipe returns the nearest of full/partial wipe and whether it is the full one
+func (cfg SiteConfig) nextWipe(now time.Time) (time.Time, bool) {
+	full, partial := cfg.FullWipe.Next(now), cfg.PartialWipe.Next(now)
+	if !partial.Before(full) {
+		return full, true
+	}
+	return partial, false
 }
 
 // UpdateSiteConfig saves site config (admin only).
@@ -90,6 +90,15 @@ func ReceiveStatsSync(w http.ResponseWriter, r *http.Request) {
 		return s
 	}
 
+	// Текущие ники — чтобы записать смену ника после пересоздания игроков
+	var prevPlayers []models.Player
+	database.DB.Select("steam_id", "username", "first_connect_date").Find(&prevPlayers)
+	prevByID := make(map[string]models.Player, len(prevPlayers))
+	for _, p := range prevPlayers {
+		prevByID[p.SteamID] = p
+	}
+	var nameChanges []database.NameChange
+
 	// 1. Полное удаление: clans, clan_members, players, player_stats
 	// Порядок из-за foreign key: stats -> players (unlink clan) -> clan_members -> clans
 	database.DB.Exec("DELETE FROM player_stats")
@@ -105,11 +114,15 @@ func ReceiveStatsSync(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 		hexID := normalizeHexID(c.HexID)
+		leader := c.LeaderSteamID
+		if leader != "" && !checkSteamID(r, "stats/sync", leader) {
+			leader = ""
+		}
 		clan := models.Clan{
 			HexID:         hexID,
 			Name:          c.Name,
 			Abbrev:        c.Abbrev,
-			LeaderSteamID: c.LeaderSteamID,
+			LeaderSteamID: leader,
 			Created:       now,
 			Level:         c.Level,
 			Experience:    c.Experience,
@@ -122,17 +135,15 @@ func ReceiveStatsSync(w http.ResponseWriter, r *http.Request) {
 		hexToClanID[c.HexID] = clan.ID
 		hexToClanID[hexID] = clan.ID
 
-		for _, steamID := range c.MemberIDs {
-			if steamID != "" {
-				database.DB.Create(&models.ClanMember{ClanID: clan.ID, SteamID: steamID, Permissions: ""})
-			}
+		for _, steamID := range filterSteamIDs(r, "stats/sync", c.MemberIDs) {
+			database.DB.Create(&models.ClanMember{ClanID: clan.ID, SteamID: steamID, Permissions: ""})
 		}
 	}
 
 	// 4. Создаём игроков заново
 	playersImported := 0
 	for _, p := range payload.Players {
-		if p.SteamID == "" {
+		if p.SteamID == "" || !checkSteamID(r, "stats/sync", p.SteamID) {
 			continue
 		}
 		var clanID *uint
@@ -164,6 +175,9 @@ func ReceiveStatsSync(w http.ResponseWriter, r *http.Request) {
 			LastConnectDate:  now,
 		})
 		playersImported++
+		if prev, ok := prevByID[p.SteamID]; ok && prev.Username != p.Username {
+			nameChanges = append(nameChanges, database.NameChange{SteamID: p.SteamID, OldName: prev.Username, NewName: p.Username, Since: prev.FirstConnectDate})
+		}
 
 		if p.Stats != nil {
 			database.DB.Create(&models.PlayerStats{
@@ -178,6 +192,11 @@ func ReceiveStatsSync(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if err := database.RecordNameChanges(nameChanges); err != nil {
+		log.Printf("[StatsSync] name history error: %v", err)
+	}
+	statsUpdated()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"ok":      true,
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	authpkg "rust-legacy-site/pkg/auth"
+	"rust-legacy-site/pkg/steamid"
+)
+
+// Steam OpenID 2.0 — подтверждение владения SteamID (нужно, например, для апелляции своего бана)
+const (
+	steamOpenIDLogin   = "https://steamcommunity.com/openid/login"
+	steamOpenIDNS      = "http://specs.openid.net/auth/2.0"
+	steamOpenIDSelect  = "http://specs.openid.net/auth/2.0/identifier_select"
+	steamClaimedPrefix = "https://steamcommunity.com/openid/id/"
+	steamLinkPurpose   = "steam-link"
+	steamLinkTTL       = 10 * time.Minute
+)
+
+var steamOpenIDClient = &http.Client{Timeout: 10 * time.Second}
+
+func steamLinkReturnTo(state string) string {
+	return SiteURL + "/api/auth/steam/callback?state=" + url.QueryEscape(state)
+}
+
+// StartSteamLink returns Steam login URL; after login Steam redirects the browser to SteamLinkCallback
+// POST /api/auth/steam/link -> {url}
+func StartSteamLink(w http.ResponseWriter, r *http.Request) {
+	claims := getClaims(r)
+	if claims == nil || claims.UserID == 0 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	state, err := authpkg.IssueOnceToken(steamLinkPurpose, authpkg.Claims{UserID: claims.UserID, Username: claims.Username, Role: "user"}, steamLinkTTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q := url.Values{
+		"openid.ns":         {steamOpenIDNS},
+		"openid.mode":       {"checkid_setup"},
+		"openid.return_to":  {steamLinkReturnTo(state)},
+		"openid.realm":      {SiteURL},
+		"openid.identity":   {steamOpenIDSelect},
+		"openid.claimed_id": {steamOpenIDSelect},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"url": steamOpenIDLogin + "?" + q.Encode()})
+}
+
+// verifySteamAssertion checks the positive assertion with Steam (check_authentication) and returns SteamID64
+func verifySteamAssertion(q url.Values, returnTo string) (string, error) {
+	if q.Get("openid.mode") != "id_res" {
+		return "", fmt.Errorf("login cancelled")
+	}
+	if q.Get("openid.op_endpoint") != steamOpenIDLogin || q.Get("openid.return_to") != returnTo {
+		return "", fmt.Errorf("assertion is not for this site")
+	}
+	id := strings.TrimPrefix(q.Get("openid.claimed_id"), steamClaimedPrefix)
+	if id == q.Get("openid.claimed_id") || !steamid.Valid(id) {
+		return "", fmt.Errorf("invalid claimed_id")
+	}
+
+	form := url.Values{}
+	for k, v := range q {
+		if strings.HasPrefix(k, "openid.") {
+			form[k] = v
+		}
+	}
+	form.Set("openid.mode", "check_authentication")
+	resp, err := steamOpenIDClient.PostForm(steamOpenIDLogin, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if !strings.Contains(string(body), "is_valid:true") {
+		return "", fmt.Errorf("steam rejected the assertion")
+	}
+	return id, nil
+}
+
+// SteamLinkCallback verifies Steam login and links SteamID to the user, then redirects to /tickets?steam=linked|error
+// GET /api/auth/steam/callback?state=...&openid.*
+func SteamLinkCallback(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	result := "error"
+	defer func() {
+		http.Redirect(w, r, SiteURL+"/tickets?steam="+result, http.StatusFound)
+	}()
+
+	claims, ok := authpkg.RedeemOnceToken(steamLinkPurpose, state)
+	if !ok {
+		result = "expired"
+		return
+	}
+	id, err := verifySteamAssertion(r.URL.Query(), steamLinkReturnTo(state))
+	if err != nil {
+		log.Printf("[SteamLink] user %d: %v", claims.UserID, err)
+		return
+	}
+	var taken int64
+	database.DB.Model(&models.User{}).Where("steam_id = ? AND id <> ?", id, claims.UserID).Count(&taken)
+	if taken > 0 {
+		result = "taken"
+		return
+	}
+	if err := database.DB.Model(&models.User{}).Where("id = ?", claims.UserID).Update("steam_id", id).Error; err != nil {
+		log.Printf("[SteamLink] user %d: %v", claims.UserID, err)
+		return
+	}
+	log.Printf("[SteamLink] user %d linked %s", claims.UserID, id)
+	result = "linked"
+}
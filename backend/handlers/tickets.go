@@ -0,0 +1,535 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/bans"
+	"rust-legacy-site/pkg/email"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// Ticket types, statuses and message authors
+const (
+	ticketReport = "report"
+	ticketAppeal = "appeal"
+
+	ticketOpen       = "open"
+	ticketInProgress = "in_progress"
+	ticketWaiting    = "waiting" // ждём ответа пользователя
+	ticketResolved   = "resolved"
+	ticketClosed     = "closed"
+
+	ticketAuthorUser  = "user"
+	ticketAuthorStaff = "staff"
+)
+
+const (
+	ticketSubjectMax  = 200
+	ticketMessageMax  = 5000
+	ticketOpenPerUser = 5
+	ticketsPerPageMax = 100
+)
+
+// ticketCategories - категории жалоб на игрока
+var ticketCategories = map[string]bool{
+	"cheating": true,
+	"abuse":    true,
+	"griefing": true,
+	"scam":     true,
+	"exploit":  true,
+	"other":    true,
+}
+
+var ticketStatuses = map[string]bool{
+	ticketOpen:       true,
+	ticketInProgress: true,
+	ticketWaiting:    true,
+	ticketResolved:   true,
+	ticketClosed:     true,
+}
+
+// ticketMessageBody trims message and checks length
+func ticketMessageBody(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("message is required")
+	}
+	if utf8.RuneCountInString(s) > ticketMessageMax {
+		return "", fmt.Errorf("message is too long (max %d characters)", ticketMessageMax)
+	}
+	return s, nil
+}
+
+// accountEmail returns the account login if it is an email address (отдельного email у аккаунта нет)
+func accountEmail(login string) string {
+	if addr, err := mail.ParseAddress(login); err != nil || addr.Address != login {
+		return ""
+	}
+	return login
+}
+
+// notifyTicket sends email in background; staff=true — письмо персоналу (ссылка в админку)
+func notifyTicket(to string, t models.Ticket, subject, text string, staff bool) {
+	cfg := email.LoadFromEnv()
+	if !cfg.Enabled {
+		return
+	}
+	if staff {
+		to = supportRecipient(cfg)
+	} else if to == "" {
+		to = accountEmail(t.UserLogin) // старые тикеты без email
+	}
+	if to == "" {
+		return
+	}
+	link := fmt.Sprintf("%s/tickets/%d", SiteURL, t.ID)
+	if staff {
+		link = fmt.Sprintf("%s/admin/tickets/%d", SiteURL, t.ID)
+	}
+	go func() {
+		if err := email.SendTicketUpdate(cfg, to, t.ID, subject, text, link); err != nil {
+			log.Printf("[Tickets] notify #%d: %v", t.ID, err)
+		}
+	}()
+}
+
+// CreateTicket files a report against a SteamID or a ban appeal
+// POST /api/tickets {type: "report", category, targetSteamId, subject, message, evidence, email}
+// POST /api/tickets {type: "appeal", banId, subject, message, evidence, email}
+// email is required unless the account login is an email address
+func CreateTicket(w http.ResponseWriter, r *http.Request) {
+	claims := getClaims(r)
+	if claims == nil || claims.UserID == 0 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Type          string   `json:"type"`
+		Category      string   `json:"category"`
+		TargetSteamID string   `json:"targetSteamId"`
+		BanID         uint     `json:"banId"`
+		Subject       string   `json:"subject"`
+		Message       string   `json:"message"`
+		Evidence      []string `json:"evidence"`
+		Email         string   `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := ticketMessageBody(req.Message)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	evidence, err := normalizeEvidence(req.Evidence)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email != "" {
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil || addr.Address != req.Email || len(req.Email) > 254 {
+			http.Error(w, "invalid email", http.StatusBadRequest)
+			return
+		}
+	}
+	// Тема уходит в заголовок письма — без переводов строк
+	req.Subject = email.HeaderValue(req.Subject)
+	if utf8.RuneCountInString(req.Subject) > ticketSubjectMax {
+		req.Subject = string([]rune(req.Subject)[:ticketSubjectMax])
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil {
+		http.Error(w, "user not found", http.StatusUnauthorized)
+		return
+	}
+	// Без email пользователь не узнает об ответе: берём логин, если это email, иначе email обязателен
+	if req.Email == "" {
+		req.Email = accountEmail(user.Login)
+	}
+	if req.Email == "" {
+		http.Error(w, "email is required for ticket notifications", http.StatusBadRequest)
+		return
+	}
+	var openCount int64
+	database.DB.Model(&models.Ticket{}).Where("user_id = ? AND status NOT IN ?", user.ID, []string{ticketResolved, ticketClosed}).Count(&openCount)
+	if openCount >= ticketOpenPerUser {
+		http.Error(w, fmt.Sprintf("too many open tickets (max %d)", ticketOpenPerUser), http.StatusTooManyRequests)
+		return
+	}
+
+	now := time.Now()
+	t := models.Ticket{
+		Type:        req.Type,
+		Status:      ticketOpen,
+		Subject:     req.Subject,
+		UserID:      user.ID,
+		UserLogin:   user.Login,
+		Email:       req.Email,
+		Evidence:    evidence,
+		LastReplyAt: now,
+		LastReplyBy: ticketAuthorUser,
+	}
+	switch req.Type {
+	case ticketReport:
+		req.TargetSteamID = strings.TrimSpace(req.TargetSteamID)
+		if !checkSteamID(r, "tickets", req.TargetSteamID) {
+			http.Error(w, "invalid targetSteamId", http.StatusBadRequest)
+			return
+		}
+		if !ticketCategories[req.Category] {
+			http.Error(w, "invalid category (cheating, abuse, griefing, scam, exploit, other)", http.StatusBadRequest)
+			return
+		}
+		t.Category, t.TargetSteamID = req.Category, req.TargetSteamID
+		if t.Subject == "" {
+			t.Subject = "Жалоба на " + req.TargetSteamID
+		}
+	case ticketAppeal:
+		var ban models.Ban
+		if err := database.DB.First(&ban, req.BanID).Error; err != nil || !ban.Active {
+			http.Error(w, "active ban not found", http.StatusBadRequest)
+			return
+		}
+		// Обжаловать можно только свой бан: SteamID подтверждается входом через Steam (/api/auth/steam/link)
+		if user.SteamID == "" {
+			http.Error(w, "link your Steam account to appeal a ban", http.StatusForbidden)
+			return
+		}
+		if user.SteamID != ban.SteamID {
+			http.Error(w, "you can only appeal your own ban", http.StatusForbidden)
+			return
+		}
+		var existing int64
+		database.DB.Model(&models.Ticket{}).Where("user_id = ? AND ban_id = ? AND status NOT IN ?", user.ID, ban.ID, []string{ticketResolved, ticketClosed}).Count(&existing)
+		if existing > 0 {
+			http.Error(w, "you already have an open appeal for this ban", http.StatusConflict)
+			return
+		}
+		t.BanID, t.TargetSteamID = &ban.ID, ban.SteamID
+		if t.Subject == "" {
+			t.Subject = fmt.Sprintf("Апелляция бана #%d", ban.ID)
+		}
+	default:
+		http.Error(w, "type must be report or appeal", http.StatusBadRequest)
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&t).Error; err != nil {
+			return err
+		}
+		msg := models.TicketMessage{TicketID: t.ID, AuthorType: ticketAuthorUser, AuthorID: user.ID, AuthorName: user.Login, Body: body}
+		if err := tx.Create(&msg).Error; err != nil {
+			return err
+		}
+		t.Messages = []models.TicketMessage{msg}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	notifyTicket("", t, "новый тикет — "+t.Subject, fmt.Sprintf("%s (%s) от %s:\n\n%s", t.Subject, t.Type, t.UserLogin, body), true)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// GetMyTickets - tickets of the logged-in user (newest activity first)
+// GET /api/tickets
+func GetMyTickets(w http.ResponseWriter, r *http.Request) {
+	claims := getClaims(r)
+	if claims == nil || claims.UserID == 0 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	list := []models.Ticket{}
+	if err := database.DB.Where("user_id = ?", claims.UserID).Order("last_reply_at DESC, id DESC").Find(&list).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// loadTicket loads ticket with messages; withInternal=false hides staff notes
+func loadTicket(id int, withInternal bool) (models.Ticket, error) {
+	var t models.Ticket
+	err := database.DB.Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		if !withInternal {
+			db = db.Where("internal = ?", false)
+		}
+		return db.Order("created_at ASC, id ASC")
+	}).First(&t, id).Error
+	return t, err
+}
+
+// GetMyTicket - GET /api/tickets/{id}
+func GetMyTicket(w http.ResponseWriter, r *http.Request) {
+	claims := getClaims(r)
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	t, err := loadTicket(id, false)
+	if err != nil || claims == nil || t.UserID != claims.UserID {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// ReplyMyTicket adds user reply; resolved/waiting ticket is reopened
+// POST /api/tickets/{id}/messages {message}
+func ReplyMyTicket(w http.ResponseWriter, r *http.Request) {
+	claims := getClaims(r)
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var t models.Ticket
+	if err := database.DB.First(&t, id).Error; err != nil || claims == nil || t.UserID != claims.UserID {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+		return
+	}
+	if t.Status == ticketClosed {
+		http.Error(w, "ticket is closed", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := ticketMessageBody(req.Message)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	msg := models.TicketMessage{TicketID: t.ID, AuthorType: ticketAuthorUser, AuthorID: claims.UserID, AuthorName: t.UserLogin, Body: body}
+	if err := database.DB.Create(&msg).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	update := map[string]interface{}{"last_reply_at": msg.CreatedAt, "last_reply_by": ticketAuthorUser}
+	if t.Status == ticketWaiting || t.Status == ticketResolved {
+		update["status"] = ticketOpen
+	}
+	database.DB.Model(&t).Updates(update)
+	notifyTicket("", t, "ответ пользователя — "+t.Subject, fmt.Sprintf("%s:\n\n%s", t.UserLogin, body), true)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(msg)
+}
+
+// GetAdminTickets - staff queue.
+// GET /api/admin/tickets?status=open,in_progress&type=report&assignee=me|none|<id>&q=steamid|login|subject&page=1&limit=50
+// (total count in X-Total-Count). Сначала тикеты, где последним писал пользователь.
+func GetAdminTickets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := database.DB.Model(&models.Ticket{})
+	if status := q.Get("status"); status != "" {
+		query = query.Where("status IN ?", strings.Split(status, ","))
+	}
+	if typ := q.Get("type"); typ != "" {
+		query = query.Where("type = ?", typ)
+	}
+	switch assignee := q.Get("assignee"); assignee {
+	case "":
+	case "none":
+		query = query.Where("assignee_id IS NULL")
+	case "me":
+		if claims := getClaims(r); claims != nil {
+			query = query.Where("assignee_id = ?", claims.AdminID)
+		}
+	default:
+		id, _ := strconv.Atoi(assignee)
+		query = query.Where("assignee_id = ?", id)
+	}
+	if s := strings.TrimSpace(q.Get("q")); s != "" {
+		like := "%" + escapeLike(s) + "%"
+		query = query.Where("target_steam_id = ? OR user_login ILIKE ? ESCAPE '\\' OR subject ILIKE ? ESCAPE '\\'", s, like, like)
+	}
+
+	var total int64
+	query.Count(&total)
+	page, _ := strconv.Atoi(q.Get("page"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > ticketsPerPageMax {
+		limit = 50
+	}
+	list := []models.Ticket{}
+	err := query.Order(fmt.Sprintf("CASE WHEN last_reply_by = '%s' THEN 0 ELSE 1 END, last_reply_at ASC, id ASC", ticketAuthorUser)).
+		Offset((page - 1) * limit).Limit(limit).Find(&list).Error
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetAdminTicket returns ticket with all messages (including internal notes) and the related ban
+// GET /api/admin/tickets/{id}
+func GetAdminTicket(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	t, err := loadTicket(id, true)
+	if err != nil {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+		return
+	}
+	resp := struct {
+		models.Ticket
+		Ban       *models.Ban `json:"ban,omitempty"`
+		ActiveBan *models.Ban `json:"activeBan,omitempty"` // текущий бан игрока, на которого жалоба
+	}{Ticket: t}
+	if t.BanID != nil {
+		var ban models.Ban
+		if database.DB.First(&ban, *t.BanID).Error == nil {
+			resp.Ban = &ban
+		}
+	}
+	if t.TargetSteamID != "" {
+		resp.ActiveBan = bans.Active(t.TargetSteamID)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// UpdateTicket changes status, assignee or category; user is notified about status change
+// PUT /api/admin/tickets/{id} {status, assigneeId (0 = снять), category}
+func UpdateTicket(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var t models.Ticket
+	if err := database.DB.First(&t, id).Error; err != nil {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Status     *string `json:"status"`
+		AssigneeID *uint   `json:"assigneeId"`
+		Category   *string `json:"category"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prevStatus := t.Status
+	if req.Status != nil {
+		if !ticketStatuses[*req.Status] {
+			http.Error(w, "status must be open, in_progress, waiting, resolved or closed", http.StatusBadRequest)
+			return
+		}
+		t.Status = *req.Status
+	}
+	if req.AssigneeID != nil {
+		if *req.AssigneeID == 0 {
+			t.AssigneeID, t.AssigneeName = nil, ""
+		} else {
+			var admin models.AdminUser
+			if err := database.DB.First(&admin, *req.AssigneeID).Error; err != nil {
+				http.Error(w, "admin not found", http.StatusBadRequest)
+				return
+			}
+			t.AssigneeID, t.AssigneeName = &admin.ID, admin.Username
+			if t.Status == ticketOpen {
+				t.Status = ticketInProgress
+			}
+		}
+	}
+	if req.Category != nil {
+		if t.Type != ticketReport || !ticketCategories[*req.Category] {
+			http.Error(w, "invalid category", http.StatusBadRequest)
+			return
+		}
+		t.Category = *req.Category
+	}
+	if err := database.DB.Save(&t).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if t.Status != prevStatus {
+		notifyTicket(t.Email, t, "статус изменён — "+t.Subject, fmt.Sprintf("Статус тикета «%s»: %s", t.Subject, t.Status), false)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// ReplyTicket adds staff reply or internal note. Reply sets status to waiting (unless status is given)
+// and notifies the user by email.
+// POST /api/admin/tickets/{id}/messages {message, internal, status}
+func ReplyTicket(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var t models.Ticket
+	if err := database.DB.First(&t, id).Error; err != nil {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Message  string `json:"message"`
+		Internal bool   `json:"internal"`
+		Status   string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := ticketMessageBody(req.Message)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Status != "" && !ticketStatuses[req.Status] {
+		http.Error(w, "status must be open, in_progress, waiting, resolved or closed", http.StatusBadRequest)
+		return
+	}
+	msg := models.TicketMessage{TicketID: t.ID, AuthorType: ticketAuthorStaff, Body: body, Internal: req.Internal}
+	if claims := getClaims(r); claims != nil {
+		msg.AuthorID, msg.AuthorName = claims.AdminID, claims.Username
+	}
+	if err := database.DB.Create(&msg).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	update := map[string]interface{}{}
+	if !req.Internal {
+		update["last_reply_at"], update["last_reply_by"] = msg.CreatedAt, ticketAuthorStaff
+		if req.Status == "" && (t.Status == ticketOpen || t.Status == ticketInProgress) {
+			req.Status = ticketWaiting
+		}
+	}
+	if req.Status != "" {
+		update["status"] = req.Status
+	}
+	if t.AssigneeID == nil && msg.AuthorID > 0 {
+		update["assignee_id"], update["assignee_name"] = msg.AuthorID, msg.AuthorName
+	}
+	if len(update) > 0 {
+		database.DB.Model(&t).Updates(update)
+	}
+	if !req.Internal {
+		notifyTicket(t.Email, t, "новый ответ — "+t.Subject, fmt.Sprintf("%s:\n\n%s", msg.AuthorName, body), false)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(msg)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import "testing"
+
+func TestAccountEmail(t *testing.T) {
+	tests := []struct {
+		login string
+		want  string
+	}{
+		{"player@example.com", "player@example.com"},
+		{"player", ""},
+		{"Player <player@example.com>", ""},
+		{"player@example.com\r\nBcc: x@example.com", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.login, func(t *testing.T) {
+			if got := accountEmail(tt.login); got != tt.want {
+				t.Errorf("accountEmail(%q) = %q, want %q", tt.login, got, tt.want)
+			}
+		})
+	}
+}
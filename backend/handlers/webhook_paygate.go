@@ -4,12 +4,10 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
 
 	"rust-legacy-site/database"
 	"rust-legacy-site/models"
-	"rust-legacy-site/pkg/rcon"
 )
 
 // PaygateWebhook — callback от PayGate.to при успешной оплате
@@ -83,23 +81,10 @@ func handlePaygateGET(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if order.OrderType == "shop" && order.RconCommand != "" && order.SteamID != "" {
-		host := os.Getenv("RCON_HOST")
-		port := 28016
-		if p := os.Getenv("RCON_PORT"); p != "" {
-			if v, err := strconv.Atoi(p); err == nil {
-				port = v
-			}
-		}
-		password := os.Getenv("RCON_PASSWORD")
-		if host != "" && password != "" {
-			if _, err := rcon.Execute(host, port, password, order.RconCommand, order.SteamID); err != nil {
-				log.Printf("[Webhook] RCON delivery failed: %v", err)
-			} else {
-				order.RconExecuted = true
-				order.Status = "delivered"
-				database.DB.Save(&order)
-			}
+	if order.OrderType == "shop" {
+		// при ошибке заказ остаётся paid и доставляется повторно (RetryOrderDeliveries)
+		if err := deliverOrder(&order); err != nil {
+			log.Printf("[Webhook] RCON delivery failed for order %d: %v", order.ID, err)
 		}
 	}
 
@@ -160,23 +145,10 @@ func handlePaygatePOST(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if order.OrderType == "shop" && order.RconCommand != "" && order.SteamID != "" {
-		host := os.Getenv("RCON_HOST")
-		port := 28016
-		if p := os.Getenv("RCON_PORT"); p != "" {
-			if v, err := strconv.Atoi(p); err == nil {
-				port = v
-			}
-		}
-		password := os.Getenv("RCON_PASSWORD")
-		if host != "" && password != "" {
-			if _, err := rcon.Execute(host, port, password, order.RconCommand, order.SteamID); err != nil {
-				log.Printf("[Webhook] RCON delivery failed: %v", err)
-			} else {
-				order.RconExecuted = true
-				order.Status = "delivered"
-				database.DB.Save(&order)
-			}
+	if order.OrderType == "shop" {
+		// при ошибке заказ остаётся paid и доставляется повторно (RetryOrderDeliveries)
+		if err := deliverOrder(&order); err != nil {
+			log.Printf("[Webhook] RCON delivery failed for order %d: %v", order.ID, err)
 		}
 	}
 
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// widgetMaxAge - виджеты хотлинкают с форумов, отдаём из statusCache (обновляется раз в 10 сек)
+const widgetMaxAge = 30
+
+var jsonpCallbackRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$.]{0,63}$`)
+
+// widgetStatus - compact status for widgets and third-party sites
+type widgetStatus struct {
+	Server     string    `json:"server"`
+	Name       string    `json:"name"`
+	Online     bool      `json:"online"`
+	Players    int       `json:"players"`
+	MaxPlayers int       `json:"maxPlayers"`
+	Map        string    `json:"map,omitempty"`
+	Address    string    `json:"address,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// getWidgetStatus returns status of server by type or ID (?server=classic | ?server=1); empty = all servers summed
+func getWidgetStatus(server string) (widgetStatus, bool) {
+	InitServerStatusCache()
+	statusCacheMu.RLock()
+	statuses := statusCache["all"]
+	updatedAt := statusCacheAt["all"]
+	statusCacheMu.RUnlock()
+
+	res := widgetStatus{Server: server, Name: "Rust Legacy", UpdatedAt: updatedAt.UTC().Truncate(time.Second)}
+	if server == "" {
+		for _, s := range statuses {
+			res.Players += s.CurrentPlayers
+			res.MaxPlayers += s.MaxPlayers
+			res.Online = res.Online || s.IsOnline
+		}
+		return res, len(statuses) > 0
+	}
+	id, _ := strconv.ParseUint(server, 10, 32)
+	for _, s := range statuses {
+		if s.ServerType != server && (id == 0 || s.ServerID != uint(id)) {
+			continue
+		}
+		res.Name = s.ServerName
+		res.Online = s.IsOnline
+		res.Players = s.CurrentPlayers
+		res.MaxPlayers = s.MaxPlayers
+		res.Map = s.Map
+		if s.IP != "" {
+			res.Address = fmt.Sprintf("%s:%d", s.IP, s.Port)
+		}
+		return res, true
+	}
+	return res, false
+}
+
+// widgetETag - weak ETag of the widget: status without UpdatedAt (кэш обновляется каждые 10 сек,
+// а онлайн меняется реже) plus query and content type that select the representation
+func widgetETag(r *http.Request, contentType string, status widgetStatus) string {
+	status.UpdatedAt = time.Time{}
+	key, _ := json.Marshal(status)
+	sum := sha1.Sum([]byte(contentType + "\n" + r.URL.RawQuery + "\n" + string(key)))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// writeWidget writes body with ETag/Cache-Control/CORS headers, answers 304 on matching If-None-Match
+func writeWidget(w http.ResponseWriter, r *http.Request, body []byte, contentType string, status widgetStatus) {
+	etag := widgetETag(r, contentType, status)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", widgetMaxAge))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if !status.UpdatedAt.IsZero() {
+		w.Header().Set("Last-Modified", status.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// GetStatusWidgetJSON returns compact status; with ?callback= returns JSON-P.
+// GET /api/widgets/status.json?server=classic&callback=onStatus
+func GetStatusWidgetJSON(w http.ResponseWriter, r *http.Request) {
+	status, ok := getWidgetStatus(r.URL.Query().Get("server"))
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	body, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	callback := r.URL.Query().Get("callback")
+	if callback == "" {
+		writeWidget(w, r, body, "application/json", status)
+		return
+	}
+	if !jsonpCallbackRe.MatchString(callback) {
+		http.Error(w, "invalid callback", http.StatusBadRequest)
+		return
+	}
+	js := []byte("/**/" + callback + "(" + string(body) + ");")
+	writeWidget(w, r, js, "application/javascript; charset=utf-8", status)
+}
+
+// GetStatusBadge returns shields-style SVG badge "label | 12/100".
+// GET /api/widgets/status.svg?server=classic&label=Classic
+func GetStatusBadge(w http.ResponseWriter, r *http.Request) {
+	status, ok := getWidgetStatus(r.URL.Query().Get("server"))
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		label = status.Name
+	}
+	if utf8.RuneCountInString(label) > 40 {
+		label = string([]rune(label)[:40])
+	}
+	value, color := "offline", "#e05d44"
+	if status.Online {
+		value, color = fmt.Sprintf("%d/%d", status.Players, status.MaxPlayers), "#4c1"
+	}
+	writeWidget(w, r, statusBadgeSVG(label, value, color), "image/svg+xml; charset=utf-8", status)
+}
+
+// statusBadgeSVG renders flat badge (layout of shields.io "flat" style)
+func statusBadgeSVG(label, value, color string) []byte {
+	lw, vw := badgeTextWidth(label)+10, badgeTextWidth(value)+10
+	total := lw + vw
+	label, value = html.EscapeString(label), html.EscapeString(value)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, total, label, value)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, value)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, total)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`, lw, lw, vw, color, total)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, lw/2, label, lw/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, lw+vw/2, value, lw+vw/2, value)
+	b.WriteString(`</g></svg>`)
+	return b.Bytes()
+}
+
+// badgeTextWidth - approximate text width in Verdana 11px
+func badgeTextWidth(s string) int {
+	var w float64
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == 'i' || r == 'l' || r == 'I' || r == '.' || r == ',' || r == ':' || r == '/' || r == '|':
+			w += 4
+		case r >= 'A' && r <= 'Z', r == 'm', r == 'w', r > 0x7f:
+			w += 8
+		default:
+			w += 7
+		}
+	}
+	return int(w + 0.5)
+}
+
+var statusWidgetTemplate = template.Must(template.New("widget").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>{{.Status.Name}}</title>
+<style>
+body{margin:0;font-family:system-ui,sans-serif;background:{{.Background}};color:{{.Text}};}
+a{color:inherit;text-decoration:none;display:block;padding:10px 14px;}
+.name{font-weight:600;font-size:14px;white-space:nowrap;overflow:hidden;text-overflow:ellipsis;}
+.dot{display:inline-block;width:8px;height:8px;border-radius:50%;margin-right:6px;background:{{if .Status.Online}}#2ecc71{{else}}#e74c3c{{end}};}
+.players{font-size:22px;font-weight:700;color:#e67e22;margin-top:4px;}
+.addr{font-size:12px;opacity:.7;}
+</style>
+</head>
+<body>
+<a href="{{.SiteURL}}" target="_blank" rel="noopener">
+<div class="name"><span class="dot"></span>{{.Status.Name}}</div>
+<div class="players">{{if .Status.Online}}{{.Status.Players}} / {{.Status.MaxPlayers}}{{else}}{{.Offline}}{{end}}</div>
+{{with .Status.Address}}<div class="addr">{{.}}</div>{{end}}
+</a>
+</body>
+</html>`))
+
+// GetStatusWidgetHTML returns small HTML widget for <iframe> (about 260x90).
+// GET /api/widgets/status.html?server=classic&theme=dark|light&lang=ru|en
+func GetStatusWidgetHTML(w http.ResponseWriter, r *http.Request) {
+	status, ok := getWidgetStatus(r.URL.Query().Get("server"))
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	lang := embedLang(r.URL.Query().Get("lang"))
+	data := map[string]interface{}{
+		"Lang":       lang,
+		"Status":     status,
+		"SiteURL":    SiteURL,
+		"Background": template.CSS("#0f0c09"),
+		"Text":       template.CSS("#f5f0eb"),
+		"Offline":    "offline",
+	}
+	if r.URL.Query().Get("theme") == "light" {
+		data["Background"], data["Text"] = template.CSS("#ffffff"), template.CSS("#222222")
+	}
+	if lang == "ru" {
+		data["Offline"] = "офлайн"
+	}
+	var buf bytes.Buffer
+	if err := statusWidgetTemplate.Execute(&buf, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeWidget(w, r, buf.Bytes(), "text/html; charset=utf-8", status)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWidgetETag(t *testing.T) {
+	base := widgetStatus{Server: "classic", Name: "Classic", Online: true, Players: 10, MaxPlayers: 50, UpdatedAt: time.Unix(1000, 0)}
+	req := httptest.NewRequest(http.MethodGet, "/api/widgets/status.json?server=classic", nil)
+	etag := widgetETag(req, "application/json", base)
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		change      func(s *widgetStatus)
+		wantSame    bool
+	}{
+		{name: "only update time changed", change: func(s *widgetStatus) { s.UpdatedAt = s.UpdatedAt.Add(10 * time.Second) }, wantSame: true},
+		{name: "players changed", change: func(s *widgetStatus) { s.Players++ }},
+		{name: "went offline", change: func(s *widgetStatus) { s.Online = false }},
+		{name: "other query", url: "/api/widgets/status.json?server=classic&callback=cb"},
+		{name: "other content type", contentType: "image/svg+xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := base
+			if tt.change != nil {
+				tt.change(&status)
+			}
+			r := req
+			if tt.url != "" {
+				r = httptest.NewRequest(http.MethodGet, tt.url, nil)
+			}
+			ct := "application/json"
+			if tt.contentType != "" {
+				ct = tt.contentType
+			}
+			if got := widgetETag(r, ct, status); (got == etag) != tt.wantSame {
+				t.Errorf("etag %s vs %s, wantSame %v", got, etag, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestWriteWidgetNotModified(t *testing.T) {
+	status := widgetStatus{Server: "classic", Players: 3, UpdatedAt: time.Unix(1000, 0)}
+	body := []byte(`{"players":3}`)
+
+	rec := httptest.NewRecorder()
+	writeWidget(rec, httptest.NewRequest(http.MethodGet, "/api/widgets/status.json", nil), body, "application/json", status)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != string(body) || etag == "" {
+		t.Fatalf("first response: code %d, body %q, etag %q", rec.Code, rec.Body.String(), etag)
+	}
+
+	// кэш статуса обновился, но онлайн тот же — клиент получает 304
+	status.UpdatedAt = status.UpdatedAt.Add(10 * time.Second)
+	r := httptest.NewRequest(http.MethodGet, "/api/widgets/status.json", nil)
+	r.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	writeWidget(rec, r, body, "application/json", status)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("revalidation: code %d, body %q, want 304 without body", rec.Code, rec.Body.String())
+	}
+}
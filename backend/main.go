@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"rust-legacy-site/database"
@@ -11,6 +12,14 @@ import (
 	"rust-legacy-site/routes"
 	"rust-legacy-site/pkg/statssync"
 	"rust-legacy-site/pkg/onlinehistory"
+	"rust-legacy-site/pkg/playerhistory"
+	"rust-legacy-site/pkg/rconjobs"
+	"rust-legacy-site/pkg/mirrors"
+	"rust-legacy-site/pkg/bans"
+	"rust-legacy-site/pkg/sessions"
+	"rust-legacy-site/pkg/achievements"
+	"rust-legacy-site/pkg/security"
+	authpkg "rust-legacy-site/pkg/auth"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -25,6 +34,21 @@ func main() {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	// ./main resanitize [--dry-run] — прогнать санитайзер по уже сохранённому HTML и выйти
+	if len(os.Args) > 1 && os.Args[1] == "resanitize" {
+		dryRun := len(os.Args) > 2 && os.Args[2] == "--dry-run"
+		n, err := database.ResanitizeContent(dryRun)
+		if err != nil {
+			log.Fatalf("Resanitize failed: %v", err)
+		}
+		log.Printf("Resanitize: %d rows changed (dry run: %v)", n, dryRun)
+		return
+	}
+
+	if err := authpkg.CheckGameAPIConfig(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	if err := database.Seed(); err != nil {
 		log.Printf("Seed warning: %v", err)
 	}
@@ -34,6 +58,8 @@ func main() {
 	if err := database.SeedClansIfEmpty(); err != nil {
 		log.Printf("SeedClansIfEmpty warning: %v", err)
 	}
+	achievements.ReleaseClaims()
+	handlers.ReleaseOrderDeliveries()
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -63,12 +89,140 @@ func main() {
 	// Server status cache — обновление раз в 10 сек, первый прогрев сразу
 	go handlers.InitServerStatusCache()
 
-	// Online history collector every 5 minutes (fallback when plugin doesn't report)
+	// Online history collector every 5 minutes (fallback when plugin doesn't report),
+	// затем часовые/суточные агрегаты; раз в сутки — удаление старых точек
 	go func() {
 		onlinehistory.Collect()
+		onlinehistory.Prune()
 		ticker := time.NewTicker(5 * time.Minute)
+		prune := time.NewTicker(24 * time.Hour)
+		for {
+			select {
+			case <-ticker.C:
+				onlinehistory.Collect()
+				onlinehistory.Rollup()
+			case <-prune.C:
+				onlinehistory.Prune()
+			}
+		}
+	}()
+
+	// Scheduled RCON jobs — проверка раз в минуту
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			rconjobs.Tick()
+		}
+	}()
+
+	// Повтор RCON выдачи оплаченных заказов, которые не удалось доставить
+	go func() {
+		ticker := time.NewTicker(2 * time.Minute)
+		for range ticker.C {
+			handlers.RetryOrderDeliveries()
+		}
+	}()
+
+	// Баны: снятие истёкших и повтор неудачных RCON команд — раз в минуту
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			bans.Tick()
+		}
+	}()
+
+	// Отложенные новости — событие news.published, когда наступает PublishedAt
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			handlers.AnnounceDueNews()
+		}
+	}()
+
+	// Download mirrors health check (доступность, размер, SHA-256 архива клиента)
+	go func() {
+		mirrors.Run()
+		interval := 10 * time.Minute
+		if m, err := strconv.Atoi(os.Getenv("MIRROR_CHECK_INTERVAL_MIN")); err == nil && m > 0 {
+			interval = time.Duration(m) * time.Minute
+		}
+		ticker := time.NewTicker(interval)
+		for range ticker.C {
+			mirrors.Run()
+		}
+	}()
+
+	// Media GC — удаление неиспользуемых загрузок раз в сутки
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		for range ticker.C {
+			handlers.CollectUnusedMedia(false)
+		}
+	}()
+
+	// Снимки счётчиков игроков (дельты по часам) и сворачивание старых часов в сутки
+	go func() {
+		interval := 15 * time.Minute
+		if m, err := strconv.Atoi(os.Getenv("PLAYER_SNAPSHOT_INTERVAL_MIN")); err == nil && m > 0 {
+			interval = time.Duration(m) * time.Minute
+		}
+		ticker := time.NewTicker(interval)
+		compact := time.NewTicker(24 * time.Hour)
+		for {
+			select {
+			case <-ticker.C:
+				playerhistory.Snapshot()
+			case <-compact.C:
+				playerhistory.Compact()
+			}
+		}
+	}()
+
+	// Статистика скачиваний хранится год
+	go func() {
+		handlers.PruneDownloadClicks()
+		ticker := time.NewTicker(24 * time.Hour)
+		for range ticker.C {
+			handlers.PruneDownloadClicks()
+		}
+	}()
+
+	// Сессии игроков: закрытие сессий серверов без отчётов, раз в сутки — удаление старых
+	go func() {
+		sessions.Prune()
+		ticker := time.NewTicker(time.Minute)
+		prune := time.NewTicker(24 * time.Hour)
+		for {
+			select {
+			case <-ticker.C:
+				sessions.CloseStale()
+			case <-prune.C:
+				sessions.Prune()
+			}
+		}
+	}()
+
+	// События безопасности: раз в минуту — счётчики подавленных повторов, хранятся SECURITY_EVENTS_DAYS дней
+	go func() {
+		security.Prune()
+		ticker := time.NewTicker(time.Minute)
+		prune := time.NewTicker(24 * time.Hour)
+		for {
+			select {
+			case <-ticker.C:
+				security.Flush()
+			case <-prune.C:
+				security.Prune()
+			}
+		}
+	}()
+
+	// История убийств хранится KILL_EVENTS_DAYS дней
+	go func() {
+		handlers.PruneKillEvents()
+		ticker := time.NewTicker(24 * time.Hour)
 		for range ticker.C {
-			onlinehistory.Collect()
+			handlers.PruneKillEvents()
 		}
 	}()
 
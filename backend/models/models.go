@@ -16,6 +16,9 @@ type ServerInfo struct {
 	Port          int       `json:"port"`       // game port (for connect)
 	QueryPort     int       `json:"queryPort"`  // A2S_INFO query port (often game port + 1)
 	Order         int       `json:"order" gorm:"column:sort_order"` // приоритет: меньше = выше
+	RconPort      int       `json:"rconPort"`                       // 0 = RCON_PORT из env
+	RconPassword  string    `json:"-" gorm:"column:rcon_password"`  // hidden; пусто = RCON_PASSWORD из env
+	ClientSHA256  string    `json:"clientSha256" gorm:"column:client_sha256"` // SHA-256 архива клиента, зеркала проверяются по нему
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
@@ -24,104 +27,133 @@ type Description struct {
 	ID           uint   `gorm:"primaryKey" json:"id"`
 	ServerInfoID uint   `json:"serverInfoId"`
 	Language     string `json:"language"`
-	Content      string `json:"content" gorm:"type:text"`
+	Content      string `json:"content" gorm:"type:text" media:"ref"`
 }
 
 type Feature struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	ServerInfoID uint      `json:"serverInfoId"`
-	Language     string    `json:"language"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description" gorm:"type:text"`
-	Icon         string    `json:"icon"`
-	Order        int       `json:"order"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ServerInfoID     uint      `json:"serverInfoId"`
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"` // общий для RU/EN версий одной записи
+	Title            string    `json:"title"`
+	Description      string    `json:"description" gorm:"type:text" media:"ref"`
+	Icon             string    `json:"icon" media:"ref"`
+	Order            int       `json:"order"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 type News struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Language    string    `json:"language"`
-	Title       string    `json:"title"`
-	Content     string    `json:"content" gorm:"type:text"`
-	ImageURL    string    `json:"imageUrl"`
-	Published   bool      `json:"published"`
-	PublishedAt time.Time `json:"publishedAt"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	Language         string     `json:"language"`
+	TranslationGroup string     `json:"translationGroup" gorm:"index"`
+	Title            string     `json:"title"`
+	Content          string     `json:"content" gorm:"type:text" media:"ref"`
+	ImageURL         string     `json:"imageUrl" media:"ref"`
+	Published        bool       `json:"published"`
+	PublishedAt      time.Time  `json:"publishedAt"` // в будущем = отложенная публикация
+	Slug             string     `json:"slug" gorm:"index"`
+	Tags             string     `json:"-" gorm:"type:text"`    // через запятую; в API — массив
+	AnnouncedAt      *time.Time `json:"announcedAt,omitempty"` // когда отправлено событие news.published
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
 }
 
 type HowToStartStep struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Language    string    `json:"language"`
-	StepNumber  int       `json:"stepNumber"`
-	Title       string    `json:"title"`
-	Content     string    `json:"content" gorm:"type:text"`
-	ImageURL    string    `json:"imageUrl"`
-	VideoURL    string    `json:"videoUrl"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	StepNumber       int       `json:"stepNumber"`
+	Title            string    `json:"title"`
+	Content          string    `json:"content" gorm:"type:text" media:"ref"`
+	ImageURL         string    `json:"imageUrl" media:"ref"`
+	VideoURL         string    `json:"videoUrl" media:"ref"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 type ServerDetail struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	ServerID  uint      `json:"serverId"` // 0 = all servers
-	Language  string    `json:"language"`
-	Section   string    `json:"section"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content" gorm:"type:text"`
-	ImageURL  string    `json:"imageUrl"`
-	VideoURL  string    `json:"videoUrl"`
-	Order     int       `json:"order"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ServerID         uint      `json:"serverId"` // 0 = all servers
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	Section          string    `json:"section"`
+	Title            string    `json:"title"`
+	Content          string    `json:"content" gorm:"type:text" media:"ref"`
+	ImageURL         string    `json:"imageUrl" media:"ref"`
+	VideoURL         string    `json:"videoUrl" media:"ref"`
+	Order            int       `json:"order"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 type Plugin struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	ServerID    uint      `json:"serverId"` // 0 = all servers
-	Language    string    `json:"language"`
-	Name        string    `json:"name"`
-	Description string    `json:"description" gorm:"type:text"`
-	Order       int       `json:"order"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Commands    []Command `gorm:"foreignKey:PluginID" json:"commands"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ServerID         uint      `json:"serverId"` // 0 = all servers
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description" gorm:"type:text" media:"ref"`
+	Order            int       `json:"order"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	Commands         []Command `gorm:"foreignKey:PluginID" json:"commands"`
 }
 
 type Command struct {
 	ID          uint   `gorm:"primaryKey" json:"id"`
 	PluginID    uint   `json:"pluginId"`
 	Command     string `json:"command"`
-	Description string `json:"description" gorm:"type:text"`
+	Description string `json:"description" gorm:"type:text" media:"ref"`
 	Usage       string `json:"usage"`
 }
 
 type Rule struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Language  string    `json:"language"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content" gorm:"type:text"`
-	Order     int       `json:"order"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	Title            string    `json:"title"`
+	Content          string    `json:"content" gorm:"type:text" media:"ref"`
+	Order            int       `json:"order"`
+	Version          int       `json:"version"` // номер текущей ревизии (ContentRevision)
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 type PaymentMethod struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Name     string `json:"name"`
-	ImageURL string `json:"imageUrl"`
+	ImageURL string `json:"imageUrl" media:"ref"`
 	Order    int    `json:"order"`
 	Enabled  bool   `json:"enabled"`
 }
 
 type LegalDocument struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Language  string    `json:"language"`
-	Type      string    `json:"type"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content" gorm:"type:text"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	Type             string    `json:"type"`
+	Title            string    `json:"title"`
+	Content          string    `json:"content" gorm:"type:text" media:"ref"`
+	Version          int       `json:"version"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// ContentRevision - snapshot of LegalDocument / Rule after each change (история, diff, откат)
+type ContentRevision struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EntityType string    `json:"entityType" gorm:"uniqueIndex:idx_content_revision_version"` // "legal_document" | "rule"
+	EntityID   uint      `json:"entityId" gorm:"uniqueIndex:idx_content_revision_version"`
+	Version    int       `json:"version" gorm:"uniqueIndex:idx_content_revision_version"`
+	Language   string    `json:"language"`
+	Type       string    `json:"type,omitempty"` // тип юр. документа
+	Title      string    `json:"title"`
+	Content    string    `json:"content,omitempty" gorm:"type:text" media:"ref"`
+	Order      int       `json:"order,omitempty"`
+	AuthorID   *uint     `json:"authorId"`
+	AuthorName string    `json:"authorName"`
+	Comment    string    `json:"comment,omitempty"` // "rollback to v3" и т.п.
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 // Clan - Rust Legacy format [0x38471ABB] NAME=WaR ABBREV= LEADER=...
@@ -148,6 +180,40 @@ type Clan struct {
 	Rank            int       `json:"rank" gorm:"-"`    // computed for leaderboard
 }
 
+// ClanStats - агрегаты клана по участникам, пересчитываются одним запросом после синхронизации (database.RefreshClanStats)
+type ClanStats struct {
+	ClanID        uint      `gorm:"primaryKey" json:"-"`
+	HexID         string    `json:"-" gorm:"index"`
+	Members       int       `json:"trackedMembers"` // участники, найденные среди игроков
+	OnlineMembers int       `json:"onlineMembers"`
+	TotalKills    int64     `json:"totalKills"`
+	TotalDeaths   int64     `json:"totalDeaths"`
+	AvgKills      float64   `json:"avgKills"`
+	KD            float64   `json:"kd"`
+	Wood          int64     `json:"wood"`
+	Metal         int64     `json:"metal"`
+	Sulfur        int64     `json:"sulfur"`
+	TotalFarm     int64     `json:"totalFarm"` // wood+metal+sulfur
+	RaidObjects   int64     `json:"raidObjects"`
+	RefreshedAt   time.Time `json:"refreshedAt"`
+}
+
+// ClanStatHistory - суточный снимок агрегатов клана (по HexID: при синхронизации кланы пересоздаются с новыми ID)
+type ClanStatHistory struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	HexID       string    `json:"hexId" gorm:"uniqueIndex:idx_clan_stat_history_day,priority:1"`
+	Day         time.Time `json:"day" gorm:"type:date;uniqueIndex:idx_clan_stat_history_day,priority:2"`
+	Name        string    `json:"name"`
+	Level       int       `json:"level"`
+	Experience  int       `json:"experience"`
+	Members     int       `json:"members"`
+	TotalKills  int64     `json:"totalKills"`
+	TotalDeaths int64     `json:"totalDeaths"`
+	KD          float64   `json:"kd"`
+	TotalFarm   int64     `json:"totalFarm"`
+	RaidObjects int64     `json:"raidObjects"`
+}
+
 // ClanMember - MEMBER=76561197970954269,invite,dismiss,management
 type ClanMember struct {
 	ID          uint   `gorm:"primaryKey" json:"id"`
@@ -181,6 +247,7 @@ type Player struct {
 	UpdatedAt        time.Time  `json:"updatedAt"`
 	Stats            *PlayerStats `gorm:"-" json:"stats,omitempty"` // loaded separately by SteamID
 	RankPosition     int        `json:"rankPosition" gorm:"-"` // computed leaderboard pos
+	Achievements     []PlayerAchievement `gorm:"-" json:"achievements,omitempty"` // loaded separately by SteamID
 }
 
 // PlayerStats - extended stats from JSON { "76561197961407422": { "RaidObjects": 0, "TimeMinutes": 981, ... } }
@@ -197,6 +264,160 @@ type PlayerStats struct {
 	Suicides    int    `json:"suicides"`
 }
 
+// PlayerName - история ников игрока: с SeenAt игрок использует Name (текущий ник — players.username)
+type PlayerName struct {
+	ID      uint      `gorm:"primaryKey" json:"-"`
+	SteamID string    `json:"steamId" gorm:"index"`
+	Name    string    `json:"name"`
+	SeenAt  time.Time `json:"seenAt"`
+}
+
+// Achievement - правило достижения: Metric >= Threshold (проверяется после каждой синхронизации статистики)
+type Achievement struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Key           string    `json:"key" gorm:"uniqueIndex"` // "kills_100"
+	Name          string    `json:"name"`
+	NameEn        string    `json:"nameEn"`
+	Description   string    `json:"description"`
+	DescriptionEn string    `json:"descriptionEn"`
+	Icon          string    `json:"icon" media:"ref"`
+	Metric        string    `json:"metric"` // killedPlayers, playTime (минуты), sulfur, clanLeader... см. pkg/achievements
+	Threshold     int       `json:"threshold"`
+	RewardCommand string    `json:"rewardCommand,omitempty" gorm:"type:text"` // RCON шаблон ({{steamid}}, {{username}}), пусто = без награды
+	ServerID      uint      `json:"serverId"`                                 // RCON для награды, 0 = из env
+	Enabled       bool      `json:"enabled"`
+	Order         int       `json:"order"`
+	Unlocked      int64     `json:"unlocked" gorm:"-"` // сколько игроков получили
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// PlayerAchievement - полученное игроком достижение
+type PlayerAchievement struct {
+	ID            uint         `gorm:"primaryKey" json:"-"`
+	SteamID       string       `json:"steamId" gorm:"uniqueIndex:idx_player_achievement,priority:1"`
+	AchievementID uint         `json:"achievementId" gorm:"uniqueIndex:idx_player_achievement,priority:2;index"`
+	Achievement   *Achievement `gorm:"foreignKey:AchievementID" json:"achievement,omitempty"`
+	UnlockedAt    time.Time    `json:"unlockedAt"`
+	RewardStatus  string       `json:"rewardStatus,omitempty"` // "" без награды | pending | delivering | ok | failed | needs_review
+	RewardError   string       `json:"-"`
+	RewardTries   int          `json:"-"`
+	RewardStep    int          `json:"-"` // сколько строк команды уже выполнено (повтор продолжает с этой строки)
+}
+
+// KillEvent - убийство игрока игроком (из плагина, /api/events/kills)
+type KillEvent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ServerID      uint      `json:"serverId" gorm:"uniqueIndex:idx_kill_event,priority:1;index:idx_kill_events_server_time,priority:1"`
+	KillerSteamID string    `json:"killerSteamId" gorm:"uniqueIndex:idx_kill_event,priority:2;index"`
+	KillerName    string    `json:"killerName"`
+	VictimSteamID string    `json:"victimSteamId" gorm:"uniqueIndex:idx_kill_event,priority:3;index"`
+	VictimName    string    `json:"victimName"`
+	Weapon        string    `json:"weapon" gorm:"index"`
+	Distance      float64   `json:"distance"` // метры
+	KilledAt      time.Time `json:"killedAt" gorm:"uniqueIndex:idx_kill_event,priority:4;index;index:idx_kill_events_server_time,priority:2"`
+}
+
+// PlayerSession - сессия игрока на сервере (из разницы списков онлайна в /api/server-status/report)
+type PlayerSession struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ServerID   uint       `json:"serverId" gorm:"index"`
+	ServerType string     `json:"serverType" gorm:"index:idx_player_sessions_open,priority:1"`
+	SteamID    string     `json:"steamId" gorm:"index"`
+	Username   string     `json:"username"`
+	StartedAt  time.Time  `json:"startedAt" gorm:"index"`
+	EndedAt    *time.Time `json:"endedAt" gorm:"index:idx_player_sessions_open,priority:2;index"` // nil = игрок ещё онлайн
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	Duration   int        `json:"duration"` // секунды, заполняется при закрытии
+}
+
+// Ban - бан игрока на всех серверах (применяется через RCON, снимается по истечении ExpiresAt)
+type Ban struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	SteamID     string     `json:"steamId" gorm:"index"`
+	Username    string     `json:"username"` // ник на момент бана
+	Reason      string     `json:"reason" gorm:"type:text"`
+	Evidence    []string   `json:"evidence" gorm:"serializer:json;type:text"` // ссылки на доказательства
+	AdminID     uint       `json:"-"`
+	AdminName   string     `json:"adminName"`
+	ExpiresAt   *time.Time `json:"expiresAt" gorm:"index"` // nil = навсегда
+	Active      bool       `json:"active" gorm:"index"`
+	UnbannedAt  *time.Time `json:"unbannedAt,omitempty"`
+	UnbannedBy  string     `json:"unbannedBy,omitempty"` // имя админа или "system" (истёк срок)
+	UnbanReason string     `json:"unbanReason,omitempty"`
+	RconStatus  string     `json:"rconStatus"` // pending | ok | failed — применён ли бан/разбан на серверах
+	RconError   string     `json:"rconError,omitempty" gorm:"type:text"`
+	RconTries   int        `json:"-"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// Ticket - жалоба на игрока или апелляция бана от пользователя сайта
+type Ticket struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	Type          string          `json:"type" gorm:"index"`   // report | appeal
+	Category      string          `json:"category"`            // для report: cheating, abuse, ...
+	Status        string          `json:"status" gorm:"index"` // open | in_progress | waiting | resolved | closed
+	Subject       string          `json:"subject"`
+	UserID        uint            `json:"userId" gorm:"index"`
+	UserLogin     string          `json:"userLogin"`
+	Email         string          `json:"email,omitempty"`                      // для уведомлений (из формы или логин-email)
+	TargetSteamID string          `json:"targetSteamId,omitempty" gorm:"index"` // на кого жалоба / чей бан
+	BanID         *uint           `json:"banId,omitempty" gorm:"index"`
+	Evidence      []string        `json:"evidence" gorm:"serializer:json;type:text"`
+	AssigneeID    *uint           `json:"assigneeId" gorm:"index"` // AdminUser
+	AssigneeName  string          `json:"assigneeName,omitempty"`
+	LastReplyAt   time.Time       `json:"lastReplyAt"`
+	LastReplyBy   string          `json:"lastReplyBy"` // user | staff
+	Messages      []TicketMessage `json:"messages,omitempty" gorm:"foreignKey:TicketID"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
+
+// TicketMessage - сообщение в переписке по тикету
+type TicketMessage struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TicketID   uint      `json:"ticketId" gorm:"index"`
+	AuthorType string    `json:"authorType"` // user | staff
+	AuthorID   uint      `json:"-"`
+	AuthorName string    `json:"authorName"`
+	Body       string    `json:"body" gorm:"type:text"`
+	Internal   bool      `json:"internal"` // заметка для персонала, пользователь не видит
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// PlayerCounters - последние известные значения счётчиков игрока (база для расчёта дельт)
+type PlayerCounters struct {
+	SteamID       string    `gorm:"primaryKey" json:"steamId"`
+	KilledPlayers int       `json:"killedPlayers"`
+	KilledMutants int       `json:"killedMutants"`
+	KilledAnimals int       `json:"killedAnimals"`
+	Deaths        int       `json:"deaths"`
+	PlayTime      int       `json:"playTime"`
+	RaidObjects   int       `json:"raidObjects"`
+	Wood          int       `json:"wood"`
+	Metal         int       `json:"metal"`
+	Sulfur        int       `json:"sulfur"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// PlayerStatDelta - прирост счётчиков игрока за час или сутки (hour-строки старше недели сворачиваются в day)
+type PlayerStatDelta struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	SteamID       string    `json:"steamId" gorm:"uniqueIndex:idx_player_stat_delta_bucket,priority:1"`
+	Resolution    string    `json:"resolution" gorm:"size:8;uniqueIndex:idx_player_stat_delta_bucket,priority:2"` // hour, day
+	Bucket        time.Time `json:"bucket" gorm:"uniqueIndex:idx_player_stat_delta_bucket,priority:3;index"`
+	KilledPlayers int       `json:"killedPlayers"`
+	KilledMutants int       `json:"killedMutants"`
+	KilledAnimals int       `json:"killedAnimals"`
+	Deaths        int       `json:"deaths"`
+	PlayTime      int       `json:"playTime"`
+	RaidObjects   int       `json:"raidObjects"`
+	Wood          int       `json:"wood"`
+	Metal         int       `json:"metal"`
+	Sulfur        int       `json:"sulfur"`
+}
+
 // User — обычные пользователи (магазин, баланс)
 type User struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
@@ -214,16 +435,23 @@ type Order struct {
 	ID                uint      `gorm:"primaryKey" json:"id"`
 	UserID            *uint     `json:"userId"`
 	OrderType         string    `json:"orderType"` // "shop" | "topup"
-	Status            string    `json:"status"`   // "pending" | "paid" | "failed" | "delivered" | "refunded"
+	Status            string    `json:"status"`    // "pending" | "paid" | "failed" | "delivered" | "refunded"
 	ItemID            *uint     `json:"itemId"`
 	SteamID           string    `json:"steamId"`
+	Quantity          int       `json:"quantity" gorm:"default:1"`
 	Amount            float64   `json:"amount"`
 	Currency          string    `json:"currency"`
 	PaygateInvoiceID  string    `json:"-" gorm:"column:paygate_invoice_id"`
 	PaygatePaymentURL string    `json:"-" gorm:"column:paygate_payment_url;type:text"`
-	RconCommand       string    `json:"-" gorm:"column:rcon_command;type:text"`
+	RconCommand       string    `json:"-" gorm:"column:rcon_command;type:text"` // шаблон команды на момент заказа
 	RconExecuted      bool      `json:"-" gorm:"column:rcon_executed"`
-	PaymentMethod     string    `json:"paymentMethod"` // "paygate" | "balance"
+	RconStep          int       `json:"-" gorm:"column:rcon_step"`       // сколько строк команды уже выполнено
+	RconDelivering    bool      `json:"-" gorm:"column:rcon_delivering"` // выдача идёт прямо сейчас (захват от повторной выдачи)
+	RconTries         int       `json:"-" gorm:"column:rcon_tries"`
+	RconError         string    `json:"-" gorm:"column:rcon_error;type:text"`
+	RconNeedsReview   bool      `json:"-" gorm:"column:rcon_needs_review"` // строка могла выполниться (таймаут/обрыв после отправки) - не повторяется автоматически
+	LegalRevisionIDs  string    `json:"legalRevisionIds,omitempty" gorm:"type:text"` // ревизии юр. документов, действовавшие при оформлении (через запятую)
+	PaymentMethod     string    `json:"paymentMethod"`                               // "paygate" | "balance"
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
 }
@@ -253,39 +481,42 @@ type AdminUser struct {
 type Setting struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Key       string    `json:"key" gorm:"uniqueIndex"`
-	Value     string    `json:"value" gorm:"type:text"`
+	Value     string    `json:"value" gorm:"type:text" media:"ref"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 type ShopCategory struct {
-	ID       uint      `gorm:"primaryKey" json:"id"`
-	Name     string    `json:"name"`
-	Language string    `json:"language"`
-	Order    int       `json:"order"`
-	Enabled  bool      `json:"enabled"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Name             string    `json:"name"`
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	Order            int       `json:"order"`
+	Enabled          bool      `json:"enabled"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 type ShopItem struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	CategoryID      uint      `json:"categoryId"`
-	Language        string    `json:"language"`
-	Name            string    `json:"name"`
-	Description     string    `json:"description" gorm:"type:text"`
-	Price           float64   `json:"price"`
-	Currency        string    `json:"currency"`
-	ImageURL        string    `json:"imageUrl"`
-	Enabled         bool      `json:"enabled"`
-	Order           int       `json:"order"`
-	Features        string    `json:"features" gorm:"type:text"`
-	Discount        int       `json:"discount"`
-	RconCommand     string    `json:"rconCommand"`
-	Warranty        string    `json:"warranty" gorm:"type:text"`           // гарантийные условия
-	Specs           string    `json:"specs" gorm:"type:text"`              // характеристики
-	PackageContents string    `json:"packageContents" gorm:"type:text"`    // комплектация
-	CreatedAt       time.Time `json:"createdAt"`
-	UpdatedAt       time.Time `json:"updatedAt"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	CategoryID       uint      `json:"categoryId"`
+	Language         string    `json:"language"`
+	TranslationGroup string    `json:"translationGroup" gorm:"index"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description" gorm:"type:text" media:"ref"`
+	Price            float64   `json:"price"`
+	Currency         string    `json:"currency"`
+	ImageURL         string    `json:"imageUrl" media:"ref"`
+	Enabled          bool      `json:"enabled"`
+	Order            int       `json:"order"`
+	Features         string    `json:"features" gorm:"type:text"`
+	Discount         int       `json:"discount"`
+	RconCommand      string    `json:"rconCommand" gorm:"type:text"`     // шаблон: по команде на строку, {{steamid}} {{username}} {{quantity}} {{order_id}}
+	MaxQuantity      int       `json:"maxQuantity"`                      // максимум штук в заказе (0/1 — только по одной); >1 требует {{quantity}} в команде
+	Warranty         string    `json:"warranty" gorm:"type:text"`        // гарантийные условия
+	Specs            string    `json:"specs" gorm:"type:text"`           // характеристики
+	PackageContents  string    `json:"packageContents" gorm:"type:text"` // комплектация
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 
@@ -293,9 +524,10 @@ type ShopItem struct {
 type DownloadLink struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
 	ServerInfoID uint      `json:"serverInfoId"`
-	Label        string    `json:"label"`        // e.g. "Google Drive", "Mirror 1"
+	Label        string    `json:"label"` // e.g. "Google Drive", "Mirror 1"
 	URL          string    `json:"url"`
 	Order        int       `json:"order"`
+	Weight       int       `json:"weight" gorm:"default:1"` // доля трафика /api/download при балансировке, 0 = не выбирать автоматически
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
@@ -349,10 +581,25 @@ type CompanyInfo struct {
 // OnlineHistory - история онлайна для графика
 type OnlineHistory struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
-	ServerID   uint      `json:"serverId"`
+	ServerID   uint      `json:"serverId" gorm:"index"`
 	ServerType string    `json:"serverType"`
 	Players    int       `json:"players"`
-	RecordedAt time.Time `json:"recordedAt"`
+	Source     string    `json:"source"` // report (плагин) или query (опрос сервера), см. pkg/onlinehistory
+	RecordedAt time.Time `json:"recordedAt" gorm:"index"`
+}
+
+// OnlineHistoryRollup - онлайн за час/сутки (min/avg/max), из сырых точек OnlineHistory
+type OnlineHistoryRollup struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	ServerID   uint      `json:"serverId" gorm:"uniqueIndex:idx_online_rollup_bucket,priority:1"`
+	ServerType string    `json:"serverType"`
+	Resolution string    `json:"resolution" gorm:"uniqueIndex:idx_online_rollup_bucket,priority:2"` // hour / day
+	Bucket     time.Time `json:"bucket" gorm:"uniqueIndex:idx_online_rollup_bucket,priority:3;index"`
+	MinPlayers int       `json:"min"`
+	AvgPlayers float64   `json:"avg"`
+	MaxPlayers int       `json:"max"`
+	PeakAt     time.Time `json:"peakAt"` // когда был максимум
+	Samples    int       `json:"samples"`
 }
 
 type ServerStatus struct {
@@ -368,3 +615,132 @@ type ServerStatus struct {
 	Port           int       `json:"port"`
 	ActivePlayers  []Player  `json:"activePlayers"`
 }
+
+// RconJob - периодическая RCON задача (airdrop, объявления, рестарт) по cron-выражению
+type RconJob struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `json:"name"`
+	Schedule   string     `json:"schedule"`                  // cron: "*/30 * * * *" (UTC)
+	ServerID   uint       `json:"serverId"`                  // 0 = RCON из env
+	Commands   string     `json:"commands" gorm:"type:text"` // по одной команде на строку
+	Enabled    bool       `json:"enabled"`
+	NextRunAt  *time.Time `json:"nextRunAt"`
+	LastRunAt  *time.Time `json:"lastRunAt"`
+	LastStatus string     `json:"lastStatus"` // "ok" | "failed" | "skipped"
+	LastResult string     `json:"lastResult" gorm:"type:text"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// RconJobRun - история запусков RconJob
+type RconJobRun struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	JobID      uint      `json:"jobId" gorm:"index"`
+	Trigger    string    `json:"trigger"` // "schedule" | "manual"
+	Status     string    `json:"status"`  // "ok" | "failed" | "skipped"
+	Output     string    `json:"output" gorm:"type:text"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// RconConsoleCommand - история команд админа в WebSocket консоли
+type RconConsoleCommand struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AdminID   uint      `json:"adminId" gorm:"index"`
+	ServerID  uint      `json:"serverId"` // 0 = RCON из env
+	Command   string    `json:"command" gorm:"type:text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RconAllowedCommand - allowlist консольных команд, допустимых в шаблонах магазина
+type RconAllowedCommand struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Command     string    `json:"command" gorm:"uniqueIndex"` // имя команды в нижнем регистре: "inv.giveplayer"
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// SecurityEvent - отклонённые подозрительные данные (невалидный SteamID, попытка RCON инъекции)
+type SecurityEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Kind      string    `json:"kind" gorm:"index"` // "invalid_steamid" | "rcon_injection"
+	Source    string    `json:"source"`            // checkout, import/players, stats/sync ...
+	IP        string    `json:"ip"`
+	Value     string    `json:"value" gorm:"type:text"`
+	Detail    string    `json:"detail" gorm:"type:text"`
+	Count     int       `json:"count" gorm:"default:1"` // событий с этого IP и вида, свёрнутых в строку (см. security.Log)
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+}
+
+// MirrorHealth - последнее состояние зеркала загрузки (DownloadLink.URL или ServerInfo.DownloadURL), pkg/mirrors
+type MirrorHealth struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	URL          string     `json:"url" gorm:"uniqueIndex;type:text"`
+	ServerInfoID uint       `json:"serverInfoId"`
+	Status       string     `json:"status"` // "unknown" | "ok" | "degraded" | "mismatch" | "dead"
+	HTTPStatus   int        `json:"httpStatus"`
+	ContentType  string     `json:"contentType"`
+	Size         int64      `json:"size"`
+	LatencyMs    int        `json:"latencyMs"`
+	SHA256       string     `json:"sha256" gorm:"column:sha256"` // посчитан по скачанному файлу
+	ChecksumOK   *bool      `json:"checksumOk"`                  // nil = не проверялся (нет ClientSHA256 или файл не скачан)
+	Fingerprint  string     `json:"-"`                           // size/ETag/Last-Modified при последнем хешировании
+	HashedAt     *time.Time `json:"hashedAt"`
+	HashedFor    string     `json:"-" gorm:"column:hashed_for"` // ClientSHA256, с которым сравнивали при последнем хешировании
+	FailCount    int        `json:"failCount"` // ошибок подряд
+	LastError    string     `json:"lastError,omitempty" gorm:"type:text"`
+	CheckedAt    *time.Time `json:"checkedAt"`
+	LastOKAt     *time.Time `json:"lastOkAt"`
+}
+
+// DownloadClick - переход по /api/download/{linkId} (без IP и User-Agent)
+type DownloadClick struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	LinkID          uint      `json:"linkId" gorm:"index"` // куда реально отправили
+	RequestedLinkID uint      `json:"requestedLinkId"`      // что выбрал пользователь (0 = auto)
+	ServerInfoID    uint      `json:"serverInfoId"`
+	Referrer        string    `json:"referrer"` // только домен
+	Country         string    `json:"country"`  // ISO код по локальной GeoIP базе, пусто если базы нет
+	CreatedAt       time.Time `json:"createdAt" gorm:"index"`
+}
+
+// MirrorCheck - история проверок зеркал (хранится неделю)
+type MirrorCheck struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	URL        string    `json:"url" gorm:"index;type:text"`
+	OK         bool      `json:"ok"`
+	HTTPStatus int       `json:"httpStatus"`
+	Size       int64     `json:"size"`
+	LatencyMs  int       `json:"latencyMs"`
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"index"`
+}
+
+// MediaFile - загруженная через админку картинка/видео (файл в pkg/media Storage).
+// Файл считается используемым, пока его URL есть в поле с тегом media:"ref" (см. database.UnusedMedia)
+type MediaFile struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Key          string    `json:"key" gorm:"uniqueIndex"` // путь в хранилище: 2026/10/3f9c0a1b2c3d4e5f.jpg
+	ThumbKey     string    `json:"thumbKey,omitempty"`
+	Storage      string    `json:"storage"` // "local" | "s3"
+	URL          string    `json:"url"`
+	ThumbURL     string    `json:"thumbUrl,omitempty"`
+	OriginalName string    `json:"originalName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	UploadedBy   string    `json:"uploadedBy"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"index"`
+}
+
+// TranslationKey - язык и группа перевода (pkg/i18n.Translatable)
+func (f Feature) TranslationKey() (string, string)        { return f.Language, f.TranslationGroup }
+func (n News) TranslationKey() (string, string)           { return n.Language, n.TranslationGroup }
+func (h HowToStartStep) TranslationKey() (string, string) { return h.Language, h.TranslationGroup }
+func (d ServerDetail) TranslationKey() (string, string)   { return d.Language, d.TranslationGroup }
+func (p Plugin) TranslationKey() (string, string)         { return p.Language, p.TranslationGroup }
+func (r Rule) TranslationKey() (string, string)           { return r.Language, r.TranslationGroup }
+func (d LegalDocument) TranslationKey() (string, string)  { return d.Language, d.TranslationGroup }
+func (c ShopCategory) TranslationKey() (string, string)   { return c.Language, c.TranslationGroup }
+func (i ShopItem) TranslationKey() (string, string)       { return i.Language, i.TranslationGroup }
@@ -0,0 +1,138 @@
+// Package achievements evaluates achievement rules (metric >= threshold) against player counters.
+package achievements
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+)
+
+// Reward statuses of PlayerAchievement
+const (
+	RewardPending    = "pending"
+	RewardDelivering = "delivering" // захвачена одним из обработчиков, см. Claim
+	RewardOK         = "ok"
+	RewardFailed     = "failed"
+	RewardReview     = "needs_review" // строка команды могла выполниться (таймаут/обрыв после отправки), решает админ
+)
+
+// metrics - SQL expression of each metric over players p LEFT JOIN player_stats s
+var metrics = map[string]string{
+	"killedPlayers": "p.killed_players",
+	"killedMutants": "p.killed_mutants",
+	"killedAnimals": "p.killed_animals",
+	"deaths":        "p.deaths",
+	"playTime":      "p.play_time",
+	"raidObjects":   "COALESCE(s.raid_objects, 0)",
+	"wood":          "COALESCE(s.wood, 0)",
+	"metal":         "COALESCE(s.metal, 0)",
+	"sulfur":        "COALESCE(s.sulfur, 0)",
+	"farm":          "COALESCE(s.wood, 0) + COALESCE(s.metal, 0) + COALESCE(s.sulfur, 0)",
+	"clanLeader":    "CASE WHEN EXISTS (SELECT 1 FROM clans c WHERE c.leader_steam_id = p.steam_id) THEN 1 ELSE 0 END",
+	"clanMember":    "CASE WHEN EXISTS (SELECT 1 FROM clan_members cm WHERE cm.steam_id = p.steam_id) THEN 1 ELSE 0 END",
+}
+
+var running sync.Mutex
+
+// Metrics returns supported metric names (sorted)
+func Metrics() []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks achievement rule
+func Validate(a models.Achievement) error {
+	if a.Key == "" || a.Name == "" {
+		return fmt.Errorf("key and name are required")
+	}
+	if _, ok := metrics[a.Metric]; !ok {
+		return fmt.Errorf("unknown metric %q (allowed: %v)", a.Metric, Metrics())
+	}
+	if a.Threshold < 1 {
+		return fmt.Errorf("threshold must be at least 1")
+	}
+	return nil
+}
+
+// Evaluate unlocks enabled achievements for every player who reached the threshold.
+// Already unlocked ones are kept (ON CONFLICT DO NOTHING). Returns the number of new unlocks.
+// Called after each stats sync.
+func Evaluate() int {
+	running.Lock()
+	defer running.Unlock()
+
+	var list []models.Achievement
+	if err := database.DB.Where("enabled = ?", true).Find(&list).Error; err != nil {
+		log.Printf("[Achievements] failed to list achievements: %v", err)
+		return 0
+	}
+	now := time.Now()
+	total := 0
+	for _, a := range list {
+		expr, ok := metrics[a.Metric]
+		if !ok {
+			log.Printf("[Achievements] %s: unknown metric %q", a.Key, a.Metric)
+			continue
+		}
+		status := ""
+		if a.RewardCommand != "" {
+			status = RewardPending
+		}
+		res := database.DB.Exec(`INSERT INTO player_achievements (steam_id, achievement_id, unlocked_at, reward_status, reward_error, reward_tries)
+			SELECT p.steam_id, ?, ?, ?, '', 0
+			FROM players p LEFT JOIN player_stats s ON s.steam_id = p.steam_id
+			WHERE p.steam_id <> '' AND (`+expr+`) >= ?
+			ON CONFLICT (steam_id, achievement_id) DO NOTHING`, a.ID, now, status, a.Threshold)
+		if res.Error != nil {
+			log.Printf("[Achievements] %s: %v", a.Key, res.Error)
+			continue
+		}
+		if res.RowsAffected > 0 {
+			log.Printf("[Achievements] %s: unlocked by %d players", a.Key, res.RowsAffected)
+			total += int(res.RowsAffected)
+		}
+	}
+	return total
+}
+
+// PendingRewards returns unlocks with undelivered reward of players who are online now
+// (награда выдаётся в игре, поэтому ждём, пока игрок зайдёт)
+func PendingRewards() []models.PlayerAchievement {
+	var list []models.PlayerAchievement
+	database.DB.Preload("Achievement").
+		Joins("JOIN players p ON p.steam_id = player_achievements.steam_id AND p.is_online").
+		Where("player_achievements.reward_status = ?", RewardPending).
+		Order("player_achievements.unlocked_at ASC").
+		Find(&list)
+	return list
+}
+
+// Claim atomically takes a pending reward for delivery. Returns false if another run has already taken it.
+func Claim(id uint) bool {
+	res := database.DB.Model(&models.PlayerAchievement{}).
+		Where("id = ? AND reward_status = ?", id, RewardPending).
+		Update("reward_status", RewardDelivering)
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+// ReleaseClaims returns rewards left in delivering (процесс остановился во время выдачи) to pending.
+// Delivery resumes from RewardStep. Called once on startup.
+func ReleaseClaims() {
+	res := database.DB.Model(&models.PlayerAchievement{}).
+		Where("reward_status = ?", RewardDelivering).
+		Update("reward_status", RewardPending)
+	if res.Error != nil {
+		log.Printf("[Achievements] release claims: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("[Achievements] %d interrupted rewards returned to pending", res.RowsAffected)
+	}
+}
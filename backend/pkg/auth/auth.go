@@ -2,6 +2,9 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -90,9 +93,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 }
 
 func AdminMiddleware(next http.Handler) http.Handler {
-	return extractToken(next, func(claims *Claims) bool {
-		return claims.Role == "admin" || (claims.Role == "" && claims.AdminID > 0)
-	})
+	return extractToken(next, IsAdmin)
 }
 
 func UserMiddleware(next http.Handler) http.Handler {
@@ -118,6 +119,96 @@ func OptionalAuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// TokenFromRequest returns bearer token from Authorization header.
+// JWT in the URL is not accepted (попадает в логи и историю); WebSocket uses a one-time ticket, see IssueOnceToken
+func TokenFromRequest(r *http.Request) string {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) == 2 && parts[0] == "Bearer" {
+		return parts[1]
+	}
+	return ""
+}
+
+// IsAdmin reports whether claims belong to an admin (old tokens may not have Role)
+func IsAdmin(claims *Claims) bool {
+	return claims.Role == "admin" || (claims.Role == "" && claims.AdminID > 0)
+}
+
+// IsAdminRequest reports whether request carries a valid admin token (for public routes with admin extras)
+func IsAdminRequest(r *http.Request) bool {
+	claims, err := ValidateToken(TokenFromRequest(r))
+	return err == nil && claims != nil && IsAdmin(claims)
+}
+
+// gameAPIKeys returns plugin keys from GAME_API_KEY (через запятую — для ротации)
+func gameAPIKeys() []string {
+	var keys []string
+	for _, k := range strings.Split(os.Getenv("GAME_API_KEY"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// gameAPITransition - GAME_API_ALLOW_UNAUTHENTICATED=true: переходный режим для старых плагинов,
+// которые шлют stats sync и online report без ключа
+func gameAPITransition() bool {
+	return os.Getenv("GAME_API_ALLOW_UNAUTHENTICATED") == "true"
+}
+
+// CheckGameAPIConfig is called on startup: plugin endpoints require GAME_API_KEY
+// unless the transition mode is enabled explicitly
+func CheckGameAPIConfig() error {
+	if !gameAPITransition() {
+		if len(gameAPIKeys()) == 0 {
+			return errors.New("GAME_API_KEY is not set: plugins must send it in X-Api-Key " +
+				"(set GAME_API_ALLOW_UNAUTHENTICATED=true to accept stats sync and online reports without a key while plugins are updated)")
+		}
+		return nil
+	}
+	log.Printf("[Auth] GAME_API_ALLOW_UNAUTHENTICATED=true: /api/stats/sync and /api/server-status/report accept requests without a key, turn it off once plugins send GAME_API_KEY")
+	return nil
+}
+
+// GameServerMiddleware authenticates game server plugins by shared key (GAME_API_KEY).
+// Key is sent as "Authorization: Bearer <key>" or "X-Api-Key: <key>". Without GAME_API_KEY all requests are rejected.
+func GameServerMiddleware(next http.Handler) http.Handler {
+	return gameServerMiddleware(next, false)
+}
+
+// LegacyGameServerMiddleware is GameServerMiddleware for endpoints that older plugins call without a key
+// (stats sync, online report): in the transition mode a request without a key is let through,
+// a wrong key is still rejected.
+func LegacyGameServerMiddleware(next http.Handler) http.Handler {
+	return gameServerMiddleware(next, true)
+}
+
+func gameServerMiddleware(next http.Handler, legacy bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Api-Key")
+		if parts := strings.Split(r.Header.Get("Authorization"), " "); key == "" && len(parts) == 2 && parts[0] == "Bearer" {
+			key = parts[1]
+		}
+		if key == "" && legacy && gameAPITransition() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		keys := gameAPIKeys()
+		if len(keys) == 0 {
+			http.Error(w, `{"error":"GAME_API_KEY is not configured"}`, http.StatusServiceUnavailable)
+			return
+		}
+		for _, k := range keys {
+			if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	})
+}
+
 func extractToken(next http.Handler, allow func(*Claims) bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGameServerMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name       string
+		keys       string
+		transition string
+		legacy     bool
+		header     string
+		want       int
+	}{
+		{"valid key", "k1", "", false, "k1", http.StatusOK},
+		{"rotated key", "k1, k2", "", false, "k2", http.StatusOK},
+		{"wrong key", "k1", "", false, "k3", http.StatusUnauthorized},
+		{"no key", "k1", "", false, "", http.StatusUnauthorized},
+		{"not configured", "", "", false, "k1", http.StatusServiceUnavailable},
+		{"transition: legacy without key", "k1", "true", true, "", http.StatusOK},
+		{"transition: legacy without key and config", "", "true", true, "", http.StatusOK},
+		{"transition: legacy wrong key", "k1", "true", true, "k3", http.StatusUnauthorized},
+		{"transition: strict without key", "k1", "true", false, "", http.StatusUnauthorized},
+		{"legacy without transition", "k1", "", true, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GAME_API_KEY", tt.keys)
+			t.Setenv("GAME_API_ALLOW_UNAUTHENTICATED", tt.transition)
+			h := GameServerMiddleware(ok)
+			if tt.legacy {
+				h = LegacyGameServerMiddleware(ok)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/stats/sync", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Api-Key", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckGameAPIConfig(t *testing.T) {
+	tests := []struct {
+		keys, transition string
+		wantErr          bool
+	}{
+		{"k1", "", false},
+		{" , ", "", true},
+		{"", "", true},
+		{"", "true", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys+"/"+tt.transition, func(t *testing.T) {
+			t.Setenv("GAME_API_KEY", tt.keys)
+			t.Setenv("GAME_API_ALLOW_UNAUTHENTICATED", tt.transition)
+			if err := CheckGameAPIConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("CheckGameAPIConfig() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		header string
+		want   string
+	}{
+		{"bearer", "/api/achievements", "Bearer abc", "abc"},
+		{"query is ignored", "/api/achievements?token=abc", "", ""},
+		{"bearer with query", "/api/achievements?token=xyz", "Bearer abc", "abc"},
+		{"not bearer", "/api/achievements", "Basic abc", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if got := TokenFromRequest(r); got != tt.want {
+				t.Errorf("TokenFromRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// onceToken - короткоживущий одноразовый токен (state для Steam OpenID, тикет WebSocket)
+type onceToken struct {
+	purpose string
+	claims  Claims
+	expires time.Time
+}
+
+var (
+	onceMu     sync.Mutex
+	onceTokens = make(map[string]onceToken)
+)
+
+// IssueOnceToken returns a random single-use token bound to claims and purpose, valid for ttl.
+// Tokens live in memory: a restart invalidates them, which is fine for their short lifetime.
+func IssueOnceToken(purpose string, claims Claims, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	now := time.Now()
+
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	for k, t := range onceTokens {
+		if now.After(t.expires) {
+			delete(onceTokens, k)
+		}
+	}
+	onceTokens[token] = onceToken{purpose: purpose, claims: claims, expires: now.Add(ttl)}
+	return token, nil
+}
+
+// RedeemOnceToken consumes token and returns its claims; false if unknown, expired or issued for another purpose
+func RedeemOnceToken(purpose, token string) (*Claims, bool) {
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	t, ok := onceTokens[token]
+	if !ok {
+		return nil, false
+	}
+	delete(onceTokens, token)
+	if t.purpose != purpose || time.Now().After(t.expires) {
+		return nil, false
+	}
+	return &t.claims, true
+}
@@ -0,0 +1,167 @@
+// Package bans keeps the ban registry in sync with game servers: bans and unbans are applied
+// via RCON on every server, failed attempts are retried and expired bans are lifted.
+// Handlers only mark a ban pending and call Notify; RCON runs in the background worker (Tick).
+package bans
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/rcon"
+
+	"gorm.io/gorm"
+)
+
+// RCON statuses of Ban
+const (
+	RconPending = "pending"
+	RconOK      = "ok"
+	RconFailed  = "failed"
+)
+
+// UnbannedBySystem - UnbannedBy of bans lifted on expiry
+const UnbannedBySystem = "system"
+
+// maxTries - после стольких неудачных попыток бан остаётся failed до ручного resync
+const maxTries = 10
+
+// ActiveIndex - partial unique index: one active ban per SteamID
+const ActiveIndex = "idx_bans_active_steam_id"
+
+var (
+	running sync.Mutex  // one worker at a time, so ban and unban of a player are applied in order
+	again   atomic.Bool // Notify during a running tick: run once more
+)
+
+// BanCommand - RCON template of ban (BAN_COMMAND, плейсхолдеры {{steamid}} и {{username}})
+func BanCommand() string {
+	if c := strings.TrimSpace(os.Getenv("BAN_COMMAND")); c != "" {
+		return c
+	}
+	return "banid {{steamid}}"
+}
+
+// UnbanCommand - RCON template of unban (UNBAN_COMMAND)
+func UnbanCommand() string {
+	if c := strings.TrimSpace(os.Getenv("UNBAN_COMMAND")); c != "" {
+		return c
+	}
+	return "unbanid {{steamid}}"
+}
+
+// Active returns active ban of the player or nil
+func Active(steamID string) *models.Ban {
+	var ban models.Ban
+	err := database.DB.Where("steam_id = ? AND active = ? AND (expires_at IS NULL OR expires_at > ?)", steamID, true, time.Now()).
+		Order("id DESC").First(&ban).Error
+	if err != nil {
+		return nil
+	}
+	return &ban
+}
+
+// apply runs ban or unban (по ban.Active) on all servers
+func apply(ban models.Ban) error {
+	template := BanCommand()
+	if !ban.Active {
+		template = UnbanCommand()
+	}
+	cmds, err := rcon.Render(template, rcon.Vars{"steamid": ban.SteamID, "username": ban.Username})
+	if err != nil {
+		return err
+	}
+	targets := rcon.AllTargets()
+	if len(targets) == 0 {
+		return fmt.Errorf("rcon not configured")
+	}
+	var errs []string
+	for _, t := range targets {
+		for _, cmd := range cmds {
+			if _, err := t.Execute(cmd); err != nil {
+				errs = append(errs, fmt.Sprintf("%s:%d %q: %v", t.Host, t.Port, cmd, err))
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// record stores RCON result unless the ban was banned/unbanned again meanwhile (then it stays pending for the next tick)
+func record(ban models.Ban, err error) {
+	update := map[string]interface{}{"rcon_status": RconOK, "rcon_error": "", "rcon_tries": 0}
+	if err != nil {
+		update["rcon_status"], update["rcon_error"], update["rcon_tries"] = RconFailed, err.Error(), ban.RconTries+1
+		log.Printf("[Bans] ban %d (%s, active=%v): %v", ban.ID, ban.SteamID, ban.Active, err)
+	}
+	database.DB.Model(&models.Ban{}).Where("id = ? AND active = ? AND rcon_tries = ?", ban.ID, ban.Active, ban.RconTries).Updates(update)
+}
+
+// Notify starts a tick in the background, e.g. right after a ban was created or lifted
+func Notify() {
+	go Tick()
+}
+
+// Tick lifts expired bans and applies pending (or retries failed) bans on servers. Called every minute from main and by Notify.
+// If another tick is running, it repeats after finishing instead of running in parallel.
+func Tick() {
+	again.Store(true)
+	for again.Load() {
+		if !running.TryLock() {
+			return
+		}
+		again.Store(false)
+		tick()
+		running.Unlock()
+	}
+}
+
+// expire lifts active bans whose expiry has passed (RCON unban goes through the tick)
+func expire(db *gorm.DB) *gorm.DB {
+	now := time.Now()
+	return db.Model(&models.Ban{}).
+		Where("active = ? AND expires_at IS NOT NULL AND expires_at <= ?", true, now).
+		Updates(map[string]interface{}{
+			"active":      false,
+			"unbanned_at": now,
+			"unbanned_by": UnbannedBySystem,
+			"rcon_status": RconPending,
+			"rcon_tries":  0,
+		})
+}
+
+// ExpirePlayer lifts expired bans of the player in tx. The unique index covers every active row,
+// so it is called before creating a new ban, without waiting for the tick.
+func ExpirePlayer(tx *gorm.DB, steamID string) error {
+	return expire(tx.Where("steam_id = ?", steamID)).Error
+}
+
+func tick() {
+	res := expire(database.DB)
+	if res.Error != nil {
+		log.Printf("[Bans] expire: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("[Bans] %d bans expired", res.RowsAffected)
+	}
+
+	var pending []models.Ban
+	database.DB.Where("rcon_status = ? OR (rcon_status = ? AND rcon_tries < ?)", RconPending, RconFailed, maxTries).
+		Order("id ASC").Find(&pending)
+	for _, ban := range pending {
+		// Разбан по старому бану не нужен, если у игрока уже есть новый активный
+		if !ban.Active && Active(ban.SteamID) != nil {
+			database.DB.Model(&models.Ban{}).Where("id = ?", ban.ID).Updates(map[string]interface{}{"rcon_status": RconOK, "rcon_error": ""})
+			continue
+		}
+		record(ban, apply(ban))
+	}
+}
@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", ""},
+		{"four fields", "* * * *"},
+		{"six fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "* 24 * * *"},
+		{"day of month zero", "* * 0 * *"},
+		{"month out of range", "* * * 13 *"},
+		{"day of week out of range", "* * * * 8"},
+		{"reversed range", "5-1 * * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"not a number", "a * * * *"},
+		{"unknown macro", "@yearly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q): expected error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			v, err = time.Parse("2006-01-02 15:04:05", s)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string // "" = no activation within 5 years
+	}{
+		{"every 15 minutes", "*/15 * * * *", "2024-01-01 10:07", "2024-01-01 10:15"},
+		{"strictly after", "0 * * * *", "2024-01-01 10:00", "2024-01-01 11:00"},
+		{"seconds are ignored", "* * * * *", "2024-01-01 10:00:42", "2024-01-01 10:01"},
+		{"list", "5,40 * * * *", "2024-01-01 10:06", "2024-01-01 10:40"},
+		{"range with step", "0 8-20/6 * * *", "2024-01-01 15:00", "2024-01-01 20:00"},
+		{"value with step", "30/10 * * * *", "2024-01-01 10:55", "2024-01-01 11:30"},
+		{"daily macro", "@daily", "2024-01-01 10:00", "2024-01-02 00:00"},
+		{"hourly macro", "@hourly", "2024-01-01 10:30", "2024-01-01 11:00"},
+		{"weekly macro", "@weekly", "2024-01-01 10:00", "2024-01-07 00:00"},
+		{"monthly macro", "@monthly", "2024-01-15 00:00", "2024-02-01 00:00"},
+		{"weekdays skip weekend", "30 9 * * 1-5", "2024-01-05 10:00", "2024-01-08 09:30"},
+		{"sunday as 7", "0 0 * * 7", "2024-01-01 00:00", "2024-01-07 00:00"},
+		{"day of month or day of week", "0 0 13 * 5", "2024-01-01 00:00", "2024-01-05 00:00"},
+		{"year rollover", "0 0 1 1 *", "2024-06-01 00:00", "2025-01-01 00:00"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"never", "0 0 31 2 *", "2024-01-01 00:00", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			got := s.Next(at(tt.from))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want zero time", tt.from, got)
+				}
+				return
+			}
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
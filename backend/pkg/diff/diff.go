@@ -0,0 +1,114 @@
+// Package diff computes line diffs between two texts (LCS, достаточно для документов в несколько сотен строк).
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Line ops
+const (
+	Equal  = " "
+	Insert = "+"
+	Delete = "-"
+)
+
+// Line - one line of a diff
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns line-by-line diff turning a into b
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+	n, m := len(x), len(y)
+
+	// lcs[i][j] - length of LCS of x[i:] and y[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []Line
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Delete, x[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, Line{Delete, x[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, Line{Insert, y[j]})
+	}
+	return out
+}
+
+// Stats returns number of inserted and deleted lines
+func Stats(lines []Line) (added, removed int) {
+	for _, l := range lines {
+		switch l.Op {
+		case Insert:
+			added++
+		case Delete:
+			removed++
+		}
+	}
+	return
+}
+
+// Unified renders diff in unified format with context lines around changes
+func Unified(lines []Line, fromName, toName string, context int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	lastPrinted := -1
+	for idx, l := range lines {
+		if l.Op == Equal && !nearChange(lines, idx, context) {
+			continue
+		}
+		if lastPrinted >= 0 && idx > lastPrinted+1 {
+			b.WriteString("@@\n")
+		}
+		b.WriteString(l.Op + l.Text + "\n")
+		lastPrinted = idx
+	}
+	return b.String()
+}
+
+func nearChange(lines []Line, idx, context int) bool {
+	for k := idx - context; k <= idx+context; k++ {
+		if k >= 0 && k < len(lines) && lines[k].Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
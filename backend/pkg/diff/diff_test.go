@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"both empty", "", "", nil},
+		{"equal", "a\nb", "a\nb", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{"append", "a", "a\nb", []Line{{Equal, "a"}, {Insert, "b"}}},
+		{"from empty", "", "a\nb", []Line{{Insert, "a"}, {Insert, "b"}}},
+		{"to empty", "a\nb", "", []Line{{Delete, "a"}, {Delete, "b"}}},
+		{"replace middle", "a\nb\nc", "a\nx\nc", []Line{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}}},
+		{"swap", "a\nb", "b\na", []Line{{Delete, "a"}, {Equal, "b"}, {Insert, "a"}}},
+		{"line endings and trailing newline ignored", "a\r\nb\r\n", "a\nb", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{"blank lines kept", "a\n\nb", "a\nb", []Line{{Equal, "a"}, {Delete, ""}, {Equal, "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           string
+		added, removed int
+	}{
+		{"equal", "a\nb", "a\nb", 0, 0},
+		{"replace", "a\nb\nc", "a\nx\ny\nc", 2, 1},
+		{"clear", "a\nb\nc", "", 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := Stats(Lines(tt.a, tt.b))
+			if added != tt.added || removed != tt.removed {
+				t.Errorf("Stats = +%d -%d, want +%d -%d", added, removed, tt.added, tt.removed)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name:    "no changes",
+			a:       "a\nb",
+			b:       "a\nb",
+			context: 3,
+			want:    "--- v1\n+++ v2\n",
+		},
+		{
+			name:    "context around change",
+			a:       "1\n2\n3\n4\n5\n6\n7",
+			b:       "1\n2\n3\nX\n5\n6\n7",
+			context: 1,
+			want:    "--- v1\n+++ v2\n 3\n-4\n+X\n 5\n",
+		},
+		{
+			name:    "separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n9",
+			b:       "1\nB\n3\n4\n5\n6\n7\nH\n9",
+			context: 1,
+			want:    "--- v1\n+++ v2\n 1\n-2\n+B\n 3\n@@\n 7\n-8\n+H\n 9\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(Lines(tt.a, tt.b), "v1", "v2", tt.context); got != tt.want {
+				t.Errorf("Unified =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
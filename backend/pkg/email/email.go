@@ -3,6 +3,7 @@ package email
 import (
 	"bytes"
 	"fmt"
+	"mime"
 	"net/smtp"
 	"os"
 	"strings"
@@ -50,10 +51,12 @@ func Send(cfg Config, to, subject, body string) error {
 	addr := cfg.Host + ":" + cfg.Port
 	auth := smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host)
 
+	// Значения заголовков могут прийти от пользователя (тема тикета, имя в форме) — CR/LF добавили бы свои заголовки
+	to = HeaderValue(to)
 	msg := bytes.NewBuffer(nil)
-	msg.WriteString("From: " + cfg.From + "\r\n")
+	msg.WriteString("From: " + HeaderValue(cfg.From) + "\r\n")
 	msg.WriteString("To: " + to + "\r\n")
-	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", HeaderValue(subject)) + "\r\n")
 	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
 	msg.WriteString("\r\n")
 	msg.WriteString(body)
@@ -61,6 +64,16 @@ func Send(cfg Config, to, subject, body string) error {
 	return smtp.SendMail(addr, auth, cfg.From, []string{to}, msg.Bytes())
 }
 
+// HeaderValue makes s safe for a single header line: control characters (CR, LF, ...) are replaced by spaces
+func HeaderValue(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s))
+}
+
 // SendContactForm sends contact form submission to recipient
 func SendContactForm(cfg Config, to, name, fromEmail, message string) error {
 	subject := fmt.Sprintf("[Rust Legacy] Сообщение от %s", name)
@@ -68,6 +81,16 @@ func SendContactForm(cfg Config, to, name, fromEmail, message string) error {
 	return Send(cfg, to, subject, body)
 }
 
+// SendTicketUpdate notifies about a new ticket, reply or status change. link - ссылка на тикет на сайте
+func SendTicketUpdate(cfg Config, to string, ticketID uint, subject, text, link string) error {
+	subject = fmt.Sprintf("[Rust Legacy] Тикет #%d: %s", ticketID, subject)
+	body := text
+	if link != "" {
+		body += "\n\n" + link
+	}
+	return Send(cfg, to, subject, body)
+}
+
 // GetRecipient returns email recipient (company support or env override)
 func GetRecipient(companyEmail string) string {
 	if override := os.Getenv("CONTACT_EMAIL"); override != "" {
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Event names
+const (
+	NewsPublished = "news.published"
+)
+
+// Event - message delivered to integration webhooks
+type Event struct {
+	Name string      `json:"event"`
+	Data interface{} `json:"data"`
+	At   time.Time   `json:"at"`
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Publish POSTs the event to EVENTS_WEBHOOK_URL (comma-separated list). Never blocks the caller.
+func Publish(name string, data interface{}) {
+	ev := Event{Name: name, Data: data, At: time.Now().UTC()}
+	for _, url := range webhookURLs() {
+		go deliver(url, ev)
+	}
+}
+
+func webhookURLs() []string {
+	var urls []string
+	for _, u := range strings.Split(os.Getenv("EVENTS_WEBHOOK_URL"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// deliver POSTs event JSON; body is signed with EVENTS_WEBHOOK_SECRET (X-Signature: sha256=<hex>)
+func deliver(url string, ev Event) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("[Events] %s: marshal: %v", ev.Name, err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[Events] %s: %v", ev.Name, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event", ev.Name)
+	if secret := os.Getenv("EVENTS_WEBHOOK_SECRET"); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		log.Printf("[Events] %s -> %s: %v", ev.Name, url, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("[Events] %s -> %s: HTTP %d", ev.Name, url, resp.StatusCode)
+	}
+}
@@ -3,8 +3,8 @@ package gameserver
 import (
 	"bytes"
 	"encoding/binary"
-	"fmt"
 	"net"
+	"strconv"
 	"time"
 )
 
@@ -38,7 +38,7 @@ func Query(ip string, port int, queryPort int) Info {
 	if qport <= 0 {
 		qport = port + 1 // Rust Legacy: query port = game port + 1
 	}
-	addr := fmt.Sprintf("%s:%d", ip, qport)
+	addr := net.JoinHostPort(ip, strconv.Itoa(qport))
 
 	info := Info{
 		Status:     "Offline",
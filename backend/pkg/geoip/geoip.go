@@ -0,0 +1,61 @@
+// Package geoip resolves country by IP using local MaxMind/DB-IP .mmdb database, if present.
+package geoip
+
+import (
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// defaultPaths - where geoipupdate and distro packages put the country DB
+var defaultPaths = []string{
+	"/usr/share/GeoIP/GeoLite2-Country.mmdb",
+	"/var/lib/GeoIP/GeoLite2-Country.mmdb",
+	"GeoLite2-Country.mmdb",
+}
+
+var (
+	db     *maxminddb.Reader
+	dbOnce sync.Once
+)
+
+func open() {
+	paths := defaultPaths
+	if p := os.Getenv("GEOIP_DB"); p != "" {
+		paths = []string{p}
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		r, err := maxminddb.Open(p)
+		if err != nil {
+			log.Printf("[GeoIP] %s: %v", p, err)
+			continue
+		}
+		db = r
+		log.Printf("[GeoIP] using %s", p)
+		return
+	}
+}
+
+// Country returns ISO 3166-1 alpha-2 code ("RU", "BY"...) or "" if unknown or no database
+func Country(ip string) string {
+	dbOnce.Do(open)
+	parsed := net.ParseIP(ip)
+	if db == nil || parsed == nil {
+		return ""
+	}
+	var rec struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := db.Lookup(parsed, &rec); err != nil {
+		return ""
+	}
+	return rec.Country.ISOCode
+}
@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// Translatable - content row that exists in several language variants linked by translation group
+type Translatable interface {
+	TranslationKey() (lang, group string)
+}
+
+var (
+	loadOnce  sync.Once
+	languages []string
+	matcher   language.Matcher
+)
+
+func load() {
+	loadOnce.Do(func() {
+		def := strings.ToLower(strings.TrimSpace(os.Getenv("DEFAULT_LANGUAGE")))
+		if def == "" {
+			def = "ru"
+		}
+		languages = []string{def}
+		list := os.Getenv("SITE_LANGUAGES")
+		if list == "" {
+			list = "ru,en"
+		}
+		for _, l := range strings.Split(list, ",") {
+			l = strings.ToLower(strings.TrimSpace(l))
+			if l != "" && l != def {
+				languages = append(languages, l)
+			}
+		}
+		// Первый тег матчера — язык по умолчанию (его возвращает Match при отсутствии совпадений)
+		tags := make([]language.Tag, 0, len(languages))
+		for _, l := range languages {
+			tags = append(tags, language.Make(l))
+		}
+		matcher = language.NewMatcher(tags)
+	})
+}
+
+// Default returns fallback language (DEFAULT_LANGUAGE env, "ru")
+func Default() string {
+	load()
+	return languages[0]
+}
+
+// Languages returns site languages, default first (SITE_LANGUAGES env, "ru,en")
+func Languages() []string {
+	load()
+	return append([]string(nil), languages...)
+}
+
+// Supported reports whether lang is one of site languages
+func Supported(lang string) bool {
+	for _, l := range Languages() {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize returns lang if supported, default language otherwise
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if Supported(lang) {
+		return lang
+	}
+	return Default()
+}
+
+// FromAcceptLanguage picks the best site language for Accept-Language header
+func FromAcceptLanguage(header string) string {
+	load()
+	if header == "" {
+		return Default()
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return Default()
+	}
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return Default()
+	}
+	return languages[idx]
+}
+
+// Candidates returns languages to query for lang: the language itself plus the fallback
+func Candidates(lang string) []string {
+	if lang == Default() {
+		return []string{lang}
+	}
+	return []string{lang, Default()}
+}
+
+// NewGroup returns a new random translation group ID
+func NewGroup() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Resolve keeps one row per translation group: the lang variant, or the default language one if
+// translation is missing. Order of groups follows the first occurrence in rows.
+func Resolve[T Translatable](rows []T, lang string) []T {
+	if lang == "" {
+		return rows
+	}
+	res := make([]T, 0, len(rows))
+	pos := make(map[string]int)
+	for _, row := range rows {
+		rowLang, group := row.TranslationKey()
+		if group == "" {
+			if rowLang == lang {
+				res = append(res, row)
+			}
+			continue
+		}
+		i, seen := pos[group]
+		if !seen {
+			pos[group] = len(res)
+			res = append(res, row)
+			continue
+		}
+		if rowLang == lang {
+			res[i] = row
+		}
+	}
+	return res
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+type row struct {
+	ID    int
+	Lang  string
+	Group string
+}
+
+func (r row) TranslationKey() (string, string) { return r.Lang, r.Group }
+
+func ids(rows []row) []int {
+	out := make([]int, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.ID)
+	}
+	return out
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []row
+		lang string
+		want []int
+	}{
+		{
+			name: "no lang keeps everything",
+			rows: []row{{1, "ru", "a"}, {2, "en", "a"}, {3, "ru", ""}},
+			lang: "",
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "translation replaces fallback",
+			rows: []row{{1, "ru", "a"}, {2, "en", "a"}},
+			lang: "en",
+			want: []int{2},
+		},
+		{
+			name: "fallback when translation is missing",
+			rows: []row{{1, "ru", "a"}, {2, "ru", "b"}, {3, "en", "b"}},
+			lang: "en",
+			want: []int{1, 3},
+		},
+		{
+			name: "order follows first occurrence of the group",
+			rows: []row{{1, "ru", "a"}, {2, "ru", "b"}, {3, "en", "b"}, {4, "en", "a"}},
+			lang: "en",
+			want: []int{4, 3},
+		},
+		{
+			name: "rows without group only in requested language",
+			rows: []row{{1, "ru", ""}, {2, "en", ""}, {3, "ru", "a"}},
+			lang: "en",
+			want: []int{2, 3},
+		},
+		{
+			name: "requested language is the default",
+			rows: []row{{1, "ru", "a"}, {2, "en", "a"}, {3, "ru", ""}},
+			lang: "ru",
+			want: []int{1, 3},
+		},
+		{
+			name: "empty input",
+			rows: nil,
+			lang: "en",
+			want: []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(Resolve(tt.rows, tt.lang)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve(%s) = %v, want %v", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // decoder for image/gif
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // decoder for image/webp
+)
+
+// Allowed content types (определяются по содержимому, а не по расширению/заголовку клиента)
+var allowedTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// maxPixels - protection from decompression bombs
+const maxPixels = 40_000_000
+
+var ErrUnsupportedType = errors.New("unsupported file type (allowed: jpeg, png, gif, webp, mp4, webm)")
+
+// Sniff detects content type by first bytes and returns it with file extension
+func Sniff(head []byte) (contentType, ext string, err error) {
+	contentType = http.DetectContentType(head)
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return contentType, "", ErrUnsupportedType
+	}
+	return contentType, ext, nil
+}
+
+// IsImage reports whether content type is an image
+func IsImage(contentType string) bool {
+	return len(contentType) > 6 && contentType[:6] == "image/"
+}
+
+// Processed - result of ProcessImage
+type Processed struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Thumb       []byte // JPEG/PNG thumbnail
+	ThumbExt    string
+}
+
+// ProcessImage validates image, scales it down to maxSize (longest side) and makes thumbnail thumbSize.
+// JPEG/PNG are re-encoded (убирает EXIF с геометкой и мусор после картинки); GIF keeps animation.
+func ProcessImage(data []byte, contentType string, maxSize, thumbSize int) (*Processed, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image: " + err.Error())
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, errors.New("image dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image: " + err.Error())
+	}
+
+	res := &Processed{ContentType: contentType, Ext: allowedTypes[contentType], Width: cfg.Width, Height: cfg.Height}
+	opaque := isOpaque(img)
+
+	switch {
+	case contentType == "image/gif":
+		res.Data = data
+	case contentType == "image/webp" && cfg.Width <= maxSize && cfg.Height <= maxSize:
+		// WebP encoder в x/image нет — оставляем оригинал, если уменьшать не нужно
+		res.Data = data
+	default:
+		scaled := img
+		if cfg.Width > maxSize || cfg.Height > maxSize {
+			scaled = resize(img, maxSize)
+		}
+		b := scaled.Bounds()
+		res.Width, res.Height = b.Dx(), b.Dy()
+		res.Data, res.ContentType, res.Ext, err = encode(scaled, opaque && contentType != "image/png")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	thumb := img
+	if cfg.Width > thumbSize || cfg.Height > thumbSize {
+		thumb = resize(img, thumbSize)
+	}
+	res.Thumb, _, res.ThumbExt, err = encode(thumb, opaque)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// resize scales image so that longest side equals size
+func resize(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := size, b.Dy()*size/b.Dx()
+	if b.Dy() > b.Dx() {
+		w, h = b.Dx()*size/b.Dy(), size
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+	return dst
+}
+
+// encode writes JPEG or PNG (for transparency and PNG originals — скриншоты с текстом)
+func encode(img image.Image, asJPEG bool) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if asJPEG {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Package media stores uploaded images/videos (local disk or S3-compatible storage) and processes images.
+package media
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// Storage - pluggable backend for uploaded files. Keys look like "2026/10/3f9c0a1b2c3d4e5f.jpg".
+type Storage interface {
+	// Name returns backend name stored with MediaFile ("local" | "s3")
+	Name() string
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Delete(key string) error
+	// URL returns public URL of the file
+	URL(key string) string
+}
+
+// FromEnv returns storage configured by MEDIA_STORAGE (local by default)
+func FromEnv() Storage {
+	if strings.EqualFold(os.Getenv("MEDIA_STORAGE"), "s3") {
+		return &S3Storage{
+			Endpoint:  strings.TrimRight(os.Getenv("MEDIA_S3_ENDPOINT"), "/"),
+			Region:    envOr("MEDIA_S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("MEDIA_S3_BUCKET"),
+			AccessKey: os.Getenv("MEDIA_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("MEDIA_S3_SECRET_KEY"),
+			PublicURL: strings.TrimRight(os.Getenv("MEDIA_S3_PUBLIC_URL"), "/"),
+		}
+	}
+	return &LocalStorage{
+		Dir:     envOr("MEDIA_DIR", "uploads"),
+		BaseURL: strings.TrimRight(envOr("MEDIA_BASE_URL", "/api/media/files"), "/"),
+	}
+}
+
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,226 @@
+package mirrors
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+)
+
+// Mirror statuses
+const (
+	StatusUnknown  = "unknown"
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // отвечает, но медленно / HTML вместо файла / первая ошибка
+	StatusMismatch = "mismatch" // SHA-256 не совпадает с ClientSHA256 — зеркало скрывается
+	StatusDead     = "dead"     // несколько ошибок подряд — зеркало скрывается
+)
+
+const (
+	deadAfterFails = 2
+	historyDays    = 7
+	parallel       = 4
+)
+
+var running sync.Mutex
+
+// Visible reports whether mirror with the status may be shown to visitors
+func Visible(status string) bool {
+	return status != StatusDead && status != StatusMismatch
+}
+
+// Rank - sort key: healthy mirrors first, degraded last
+func Rank(status string) int {
+	switch status {
+	case StatusOK:
+		return 0
+	case StatusDegraded:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// HealthByURL returns stored health of mirrors by URL
+func HealthByURL() map[string]models.MirrorHealth {
+	var rows []models.MirrorHealth
+	database.DB.Find(&rows)
+	m := make(map[string]models.MirrorHealth, len(rows))
+	for _, h := range rows {
+		m[h.URL] = h
+	}
+	return m
+}
+
+type target struct {
+	url          string
+	serverInfoID uint
+	sha256       string
+}
+
+// Run checks all download mirrors (DownloadLink + ServerInfo.DownloadURL). Called every MIRROR_CHECK_INTERVAL_MIN from main.
+func Run() {
+	if !running.TryLock() {
+		return // предыдущая проверка ещё идёт (хеширование большого архива)
+	}
+	defer running.Unlock()
+
+	var servers []models.ServerInfo
+	var links []models.DownloadLink
+	if err := database.DB.Find(&servers).Error; err != nil {
+		log.Printf("[Mirrors] failed to list servers: %v", err)
+		return
+	}
+	database.DB.Find(&links)
+
+	expected := make(map[uint]string)
+	seen := make(map[string]bool)
+	var targets []target
+	add := func(url string, serverID uint) {
+		url = strings.TrimSpace(url)
+		if url == "" || seen[url] || !(strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")) {
+			return
+		}
+		seen[url] = true
+		targets = append(targets, target{url: url, serverInfoID: serverID, sha256: expected[serverID]})
+	}
+	for _, srv := range servers {
+		expected[srv.ID] = strings.ToLower(strings.TrimSpace(srv.ClientSHA256))
+	}
+	for _, l := range links {
+		add(l.URL, l.ServerInfoID)
+	}
+	for _, srv := range servers {
+		add(srv.DownloadURL, srv.ID)
+	}
+
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			check(t)
+		}(t)
+	}
+	wg.Wait()
+
+	// Удаляем состояние зеркал, которых больше нет, и старую историю
+	if len(targets) > 0 {
+		urls := make([]string, 0, len(targets))
+		for _, t := range targets {
+			urls = append(urls, t.url)
+		}
+		database.DB.Where("url NOT IN ?", urls).Delete(&models.MirrorHealth{})
+	} else {
+		database.DB.Where("1 = 1").Delete(&models.MirrorHealth{})
+	}
+	database.DB.Where("created_at < ?", time.Now().AddDate(0, 0, -historyDays)).Delete(&models.MirrorCheck{})
+}
+
+func check(t target) {
+	var h models.MirrorHealth
+	if database.DB.Where("url = ?", t.url).First(&h).Error != nil {
+		h = models.MirrorHealth{URL: t.url, Status: StatusUnknown}
+	}
+	h.ServerInfoID = t.serverInfoID
+
+	res := Probe(t.url)
+	now := time.Now()
+	h.CheckedAt = &now
+	h.HTTPStatus = res.HTTPStatus
+	h.ContentType = res.ContentType
+	h.LatencyMs = int(res.Latency / time.Millisecond)
+	if res.Size >= 0 {
+		h.Size = res.Size
+	}
+
+	rec := models.MirrorCheck{URL: t.url, OK: res.OK(), HTTPStatus: res.HTTPStatus, Size: res.Size, LatencyMs: h.LatencyMs}
+	switch {
+	case res.Err != nil:
+		h.LastError = res.Err.Error()
+	case !res.OK():
+		h.LastError = "HTTP " + strconv.Itoa(res.HTTPStatus)
+	case res.HTML():
+		h.LastError = "returned HTML page instead of the file"
+	default:
+		h.LastError = ""
+	}
+	rec.Error = h.LastError
+
+	if res.OK() {
+		h.FailCount = 0
+		h.LastOKAt = &now
+		if t.sha256 == "" {
+			h.ChecksumOK = nil
+		} else if !res.HTML() && needsHash(h, res, t.sha256) {
+			verify(&h, t.sha256, res)
+		}
+	} else {
+		h.FailCount++
+	}
+	h.Status = status(h, res)
+
+	if err := database.DB.Save(&h).Error; err != nil {
+		log.Printf("[Mirrors] save %s: %v", t.url, err)
+	}
+	database.DB.Create(&rec)
+	if h.Status != StatusOK {
+		log.Printf("[Mirrors] %s: %s %s", t.url, h.Status, h.LastError)
+	}
+}
+
+// needsHash - файл изменился (size/ETag/Last-Modified), сменился ожидаемый ClientSHA256 или давно не проверялся
+func needsHash(h models.MirrorHealth, res Result, expected string) bool {
+	if h.HashedAt == nil || h.Fingerprint != res.Fingerprint() || h.HashedFor != expected {
+		return true
+	}
+	return time.Since(*h.HashedAt) > time.Duration(envInt("MIRROR_HASH_INTERVAL_HOURS", 24))*time.Hour
+}
+
+func verify(h *models.MirrorHealth, expected string, res Result) {
+	sum, size, err := Hash(h.URL, int64(envInt("MIRROR_HASH_MAX_MB", 4096))<<20)
+	now := time.Now()
+	h.HashedAt = &now
+	h.Fingerprint = res.Fingerprint()
+	h.HashedFor = expected
+	if err != nil {
+		h.ChecksumOK = nil
+		h.LastError = "checksum: " + err.Error()
+		return
+	}
+	ok := sum == expected
+	h.SHA256, h.Size, h.ChecksumOK = sum, size, &ok
+	if !ok {
+		h.LastError = "SHA-256 mismatch: " + sum
+		log.Printf("[Mirrors] %s: SHA-256 mismatch (got %s, want %s)", h.URL, sum, expected)
+	}
+}
+
+func status(h models.MirrorHealth, res Result) string {
+	switch {
+	case !res.OK() && h.FailCount >= deadAfterFails:
+		return StatusDead
+	case !res.OK():
+		return StatusDegraded
+	case h.ChecksumOK != nil && !*h.ChecksumOK:
+		return StatusMismatch
+	case res.HTML(), h.LatencyMs > envInt("MIRROR_SLOW_MS", 3000):
+		return StatusDegraded
+	}
+	return StatusOK
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
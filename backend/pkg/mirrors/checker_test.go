@@ -0,0 +1,89 @@
+package mirrors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rust-legacy-site/models"
+)
+
+func TestStatus(t *testing.T) {
+	ok, bad := true, false
+	okRes := Result{HTTPStatus: 200, ContentType: "application/zip", Size: 100}
+	tests := []struct {
+		name string
+		h    models.MirrorHealth
+		res  Result
+		want string
+	}{
+		{name: "healthy", res: okRes, want: StatusOK},
+		{name: "checksum matches", h: models.MirrorHealth{ChecksumOK: &ok}, res: okRes, want: StatusOK},
+		{name: "first failure degrades", h: models.MirrorHealth{FailCount: 1}, res: Result{HTTPStatus: 503, Size: -1}, want: StatusDegraded},
+		{name: "repeated failures kill", h: models.MirrorHealth{FailCount: deadAfterFails}, res: Result{Err: errors.New("timeout"), Size: -1}, want: StatusDead},
+		{name: "checksum mismatch hides", h: models.MirrorHealth{ChecksumOK: &bad}, res: okRes, want: StatusMismatch},
+		{name: "html page instead of file", res: Result{HTTPStatus: 200, ContentType: "text/html; charset=utf-8", Size: 5000}, want: StatusDegraded},
+		{name: "slow", h: models.MirrorHealth{LatencyMs: 5000}, res: okRes, want: StatusDegraded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status(tt.h, tt.res); got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsHash(t *testing.T) {
+	res := Result{HTTPStatus: 200, Size: 100, ETag: `"v1"`}
+	recent := time.Now().Add(-time.Hour)
+	old := time.Now().Add(-48 * time.Hour)
+	hashed := models.MirrorHealth{HashedAt: &recent, Fingerprint: res.Fingerprint(), HashedFor: "abc"}
+	tests := []struct {
+		name     string
+		change   func(h *models.MirrorHealth)
+		res      Result
+		expected string
+		want     bool
+	}{
+		{name: "up to date", res: res, expected: "abc"},
+		{name: "never hashed", change: func(h *models.MirrorHealth) { h.HashedAt = nil }, res: res, expected: "abc", want: true},
+		{name: "file changed", res: Result{HTTPStatus: 200, Size: 100, ETag: `"v2"`}, expected: "abc", want: true},
+		{name: "expected checksum changed", res: res, expected: "def", want: true},
+		{name: "hash is stale", change: func(h *models.MirrorHealth) { h.HashedAt = &old }, res: res, expected: "abc", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := hashed
+			if tt.change != nil {
+				tt.change(&h)
+			}
+			if got := needsHash(h, tt.res, tt.expected); got != tt.want {
+				t.Errorf("needsHash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeFallsBackToRangedGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Range") != "bytes=0-0" {
+			t.Errorf("Range = %q, want bytes=0-0", r.Header.Get("Range"))
+		}
+		w.Header().Set("Content-Range", "bytes 0-0/123456")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte{0})
+	}))
+	defer srv.Close()
+
+	res := Probe(srv.URL)
+	if !res.OK() || res.Size != 123456 {
+		t.Errorf("Probe = status %d size %d err %v, want 200 and size 123456", res.HTTPStatus, res.Size, res.Err)
+	}
+}
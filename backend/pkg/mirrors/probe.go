@@ -0,0 +1,173 @@
+// Package mirrors checks download mirrors of the game client (availability, size, latency, SHA-256).
+package mirrors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var client = &http.Client{
+	Timeout: 30 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("too many redirects")
+		}
+		return nil
+	},
+}
+
+// hashClient - без общего таймаута: архив клиента может качаться долго, дедлайн задаёт Hash
+var hashClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+// hashIdleTimeout - сколько ждать очередных байт, прежде чем считать загрузку зависшей
+const hashIdleTimeout = time.Minute
+
+// hashTimeout - общий дедлайн загрузки: минута + maxBytes на минимальной скорости MIRROR_HASH_MIN_KBPS (по умолчанию 512 КБ/с)
+func hashTimeout(maxBytes int64) time.Duration {
+	bps := int64(envInt("MIRROR_HASH_MIN_KBPS", 512)) << 10
+	return time.Minute + time.Duration(maxBytes/bps)*time.Second
+}
+
+// idleReader продлевает таймер простоя после каждого прочитанного куска
+type idleReader struct {
+	r     io.Reader
+	timer *time.Timer
+}
+
+func (r idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(hashIdleTimeout)
+	}
+	return n, err
+}
+
+const userAgent = "RustLegacyMirrorCheck/1.0"
+
+// Result - result of a single probe
+type Result struct {
+	HTTPStatus   int
+	ContentType  string
+	Size         int64 // -1 = unknown
+	ETag         string
+	LastModified string
+	Latency      time.Duration
+	Err          error
+}
+
+// OK reports whether mirror answered with 2xx
+func (r Result) OK() bool {
+	return r.Err == nil && r.HTTPStatus >= 200 && r.HTTPStatus < 300
+}
+
+// HTML reports whether mirror returned a web page instead of the file (Google Drive "virus scan" page, login page...)
+func (r Result) HTML() bool {
+	return strings.HasPrefix(r.ContentType, "text/html")
+}
+
+// Fingerprint identifies file version without downloading it
+func (r Result) Fingerprint() string {
+	return fmt.Sprintf("%d|%s|%s", r.Size, r.ETag, r.LastModified)
+}
+
+// Probe sends HEAD; if the server does not support HEAD or does not report size, falls back to ranged GET
+func Probe(url string) Result {
+	res := request(http.MethodHead, url)
+	if res.Err == nil && (res.HTTPStatus == http.StatusMethodNotAllowed || res.HTTPStatus == http.StatusForbidden ||
+		res.HTTPStatus == http.StatusNotImplemented || (res.OK() && res.Size < 0)) {
+		res = request(http.MethodGet, url)
+	}
+	return res
+}
+
+func request(method, url string) Result {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return Result{Size: -1, Err: err}
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	res := Result{Size: -1, Latency: time.Since(start)}
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	res.HTTPStatus = resp.StatusCode
+	res.ContentType = resp.Header.Get("Content-Type")
+	res.ETag = resp.Header.Get("ETag")
+	res.LastModified = resp.Header.Get("Last-Modified")
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 0-0/123456
+		cr := resp.Header.Get("Content-Range")
+		if i := strings.LastIndex(cr, "/"); i >= 0 {
+			if n, err := strconv.ParseInt(cr[i+1:], 10, 64); err == nil {
+				res.Size = n
+			}
+		}
+		res.HTTPStatus = http.StatusOK
+	} else if method == http.MethodHead {
+		res.Size = resp.ContentLength
+	}
+	return res
+}
+
+// Hash downloads the file and returns its SHA-256 (hex) and size. Files larger than maxBytes are not hashed.
+// Download is aborted after hashTimeout(maxBytes) or when no data arrives for hashIdleTimeout.
+func Hash(url string, maxBytes int64) (string, int64, error) {
+	timeout := hashTimeout(maxBytes)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	idle := time.AfterFunc(hashIdleTimeout, cancel)
+	defer idle.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := hashClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", 0, fmt.Errorf("GET: %s", resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return "", 0, fmt.Errorf("file is larger than %d bytes, not hashed", maxBytes)
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, idleReader{r: io.LimitReader(resp.Body, maxBytes+1), timer: idle})
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", n, fmt.Errorf("download stalled or exceeded %s after %d bytes", timeout, n)
+		}
+		return "", n, err
+	}
+	if n > maxBytes {
+		return "", n, fmt.Errorf("file is larger than %d bytes, not hashed", maxBytes)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
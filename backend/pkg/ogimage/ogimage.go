@@ -0,0 +1,268 @@
+package ogimage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of Open Graph image recommended by Discord/VK/Twitter
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// Server - one server card on the image
+type Server struct {
+	Name    string
+	Current int
+	Max     int
+	Online  bool
+}
+
+// Palette - theme colours (models.Theme values, "#rrggbb" or "rgba(...)")
+type Palette struct {
+	Background    string
+	Card          string
+	Primary       string
+	Accent        string
+	Text          string
+	TextSecondary string
+	Border        string
+}
+
+// Data - everything drawn on the image
+type Data struct {
+	Title    string
+	Subtitle string
+	Servers  []Server
+	Footer   string // строка внизу: обратный отсчёт до вайпа
+	Colors   Palette
+}
+
+var defaultPalette = Palette{
+	Background:    "#0f0c09",
+	Card:          "#1a1510",
+	Primary:       "#e67e22",
+	Accent:        "#f39c12",
+	Text:          "#f5f0eb",
+	TextSecondary: "#a89f96",
+	Border:        "#2d2520",
+}
+
+var (
+	fontsOnce   sync.Once
+	fontsErr    error
+	boldFont    *opentype.Font
+	regularFont *opentype.Font
+	faces       = make(map[string]font.Face)
+	facesMu     sync.Mutex
+)
+
+func face(bold bool, size float64) (font.Face, error) {
+	fontsOnce.Do(func() {
+		if boldFont, fontsErr = opentype.Parse(gobold.TTF); fontsErr != nil {
+			return
+		}
+		regularFont, fontsErr = opentype.Parse(goregular.TTF)
+	})
+	if fontsErr != nil {
+		return nil, fontsErr
+	}
+	key := fmt.Sprintf("%t/%.0f", bold, size)
+	facesMu.Lock()
+	defer facesMu.Unlock()
+	if f, ok := faces[key]; ok {
+		return f, nil
+	}
+	src := regularFont
+	if bold {
+		src = boldFont
+	}
+	f, err := opentype.NewFace(src, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	faces[key] = f
+	return f, nil
+}
+
+// Render draws the image and returns PNG bytes
+func Render(d Data) ([]byte, error) {
+	p := resolvePalette(d.Colors)
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fill(img, img.Bounds(), p.bg)
+	fill(img, image.Rect(0, 0, Width, 10), p.primary)
+
+	const margin = 64
+	if err := text(img, d.Title, true, 72, margin, 130, Width-2*margin, p.primary); err != nil {
+		return nil, err
+	}
+	if d.Subtitle != "" {
+		if err := text(img, d.Subtitle, false, 32, margin, 180, Width-2*margin, p.textSecondary); err != nil {
+			return nil, err
+		}
+	}
+
+	// Карточки серверов: до 3 в ряд
+	servers := d.Servers
+	if len(servers) > 3 {
+		servers = servers[:3]
+	}
+	if len(servers) > 0 {
+		const gap = 24
+		cardW := (Width - 2*margin - gap*(len(servers)-1)) / len(servers)
+		top, bottom := 230, 470
+		for i, s := range servers {
+			x := margin + i*(cardW+gap)
+			r := image.Rect(x, top, x+cardW, bottom)
+			fill(img, r, p.border)
+			fill(img, r.Inset(2), p.card)
+
+			dot := p.offline
+			if s.Online {
+				dot = p.online
+			}
+			circle(img, x+40, top+52, 10, dot)
+			if err := text(img, s.Name, true, 34, x+64, top+64, cardW-88, p.text); err != nil {
+				return nil, err
+			}
+			count := strconv.Itoa(s.Current)
+			if s.Max > 0 {
+				count += " / " + strconv.Itoa(s.Max)
+			}
+			if err := text(img, count, true, 80, x+32, top+180, cardW-64, p.primary); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if d.Footer != "" {
+		if err := text(img, d.Footer, true, 40, margin, 560, Width-2*margin, p.accent); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type palette struct {
+	bg, card, primary, accent, text, textSecondary, border, online, offline color.NRGBA
+}
+
+func resolvePalette(c Palette) palette {
+	pick := func(v, def string) color.NRGBA {
+		if col, ok := ParseColor(v); ok {
+			return col
+		}
+		col, _ := ParseColor(def)
+		return col
+	}
+	return palette{
+		bg:            pick(c.Background, defaultPalette.Background),
+		card:          pick(c.Card, defaultPalette.Card),
+		primary:       pick(c.Primary, defaultPalette.Primary),
+		accent:        pick(c.Accent, defaultPalette.Accent),
+		text:          pick(c.Text, defaultPalette.Text),
+		textSecondary: pick(c.TextSecondary, defaultPalette.TextSecondary),
+		border:        pick(c.Border, defaultPalette.Border),
+		online:        color.NRGBA{R: 0x2e, G: 0xcc, B: 0x71, A: 0xff},
+		offline:       color.NRGBA{R: 0xe7, G: 0x4c, B: 0x3c, A: 0xff},
+	}
+}
+
+// ParseColor parses CSS colour: #rgb, #rrggbb, #rrggbbaa, rgb(r,g,b), rgba(r,g,b,a)
+func ParseColor(s string) (color.NRGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(s, "#") {
+		h := s[1:]
+		if len(h) == 3 {
+			h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+		}
+		if len(h) == 6 {
+			h += "ff"
+		}
+		if len(h) != 8 {
+			return color.NRGBA{}, false
+		}
+		v, err := strconv.ParseUint(h, 16, 32)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+	}
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || end < open || (s[:open] != "rgb" && s[:open] != "rgba") {
+		return color.NRGBA{}, false
+	}
+	parts := strings.Split(s[open+1:end], ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, false
+	}
+	var rgb [3]uint8
+	for i := 0; i < 3; i++ {
+		v, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil || v < 0 || v > 255 {
+			return color.NRGBA{}, false
+		}
+		rgb[i] = uint8(v)
+	}
+	alpha := uint8(255)
+	if len(parts) == 4 {
+		a, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil || a < 0 || a > 1 {
+			return color.NRGBA{}, false
+		}
+		alpha = uint8(a*255 + 0.5)
+	}
+	return color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: alpha}, true
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.NRGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func circle(img *image.RGBA, cx, cy, radius int, c color.NRGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				img.Set(cx+x, cy+y, c)
+			}
+		}
+	}
+}
+
+// text draws s with baseline at (x, y), truncating with "…" to fit maxWidth
+func text(img *image.RGBA, s string, bold bool, size float64, x, y, maxWidth int, c color.NRGBA) error {
+	f, err := face(bold, size)
+	if err != nil {
+		return err
+	}
+	limit := fixed.I(maxWidth)
+	if font.MeasureString(f, s) > limit {
+		runes := []rune(s)
+		for len(runes) > 0 && font.MeasureString(f, string(runes)+"…") > limit {
+			runes = runes[:len(runes)-1]
+		}
+		s = string(runes) + "…"
+	}
+	dr := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: f, Dot: fixed.P(x, y)}
+	dr.DrawString(s)
+	return nil
+}
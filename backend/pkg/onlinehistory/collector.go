@@ -2,6 +2,7 @@ package onlinehistory
 
 import (
 	"log"
+	"sync"
 	"time"
 
 	"rust-legacy-site/database"
@@ -9,7 +10,71 @@ import (
 	"rust-legacy-site/pkg/gameserver"
 )
 
+// Sources of online points
+const (
+	SourceReport = "report" // плагин (/api/server-status/report)
+	SourceQuery  = "query"  // опрос сервера, если плагин не присылает отчёты
+)
+
+// Минимальный интервал между точками одного сервера. Опрос (раз в 5 минут) пишется,
+// только если от плагина давно ничего не было, — так два источника не дублируют друг друга.
+const (
+	reportInterval = 2 * time.Minute
+	queryInterval  = 4 * time.Minute
+)
+
+var (
+	lastSaved   = make(map[uint]time.Time) // serverID -> время последней точки
+	lastSavedMu sync.Mutex
+)
+
+// Record saves a point unless the server already has a recent one. Returns true if saved.
+func Record(srv models.ServerInfo, players int, source string, at time.Time) bool {
+	minGap := reportInterval
+	if source == SourceQuery {
+		minGap = queryInterval
+	}
+	lastSavedMu.Lock()
+	last, ok := lastSaved[srv.ID]
+	if !ok {
+		// после рестарта — из БД
+		var rec models.OnlineHistory
+		if database.DB.Select("recorded_at").Where("server_id = ?", srv.ID).Order("recorded_at DESC").First(&rec).Error == nil {
+			last = rec.RecordedAt
+		}
+	}
+	if at.Sub(last) < minGap {
+		lastSavedMu.Unlock()
+		return false
+	}
+	lastSaved[srv.ID] = at
+	lastSavedMu.Unlock()
+
+	rec := models.OnlineHistory{
+		ServerID:   srv.ID,
+		ServerType: srv.Type,
+		Players:    players,
+		Source:     source,
+		RecordedAt: at,
+	}
+	if err := database.DB.Create(&rec).Error; err != nil {
+		log.Printf("[OnlineHistory] save failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// Report saves online reported by the plugin for server type (classic / deathmatch)
+func Report(serverType string, players int, at time.Time) {
+	var srv models.ServerInfo
+	if err := database.DB.Where("type = ?", serverType).First(&srv).Error; err != nil {
+		return
+	}
+	Record(srv, players, SourceReport, at)
+}
+
 // Collect runs every 5 minutes, queries servers and saves online history
+// (fallback for servers without plugin reports)
 func Collect() {
 	var servers []models.ServerInfo
 	if err := database.DB.Find(&servers).Error; err != nil {
@@ -18,15 +83,13 @@ func Collect() {
 	}
 	now := time.Now()
 	for _, srv := range servers {
-		info := gameserver.Query(srv.IP, srv.Port, srv.QueryPort)
-		rec := models.OnlineHistory{
-			ServerID:   srv.ID,
-			ServerType: srv.Type,
-			Players:    info.Players,
-			RecordedAt: now,
-		}
-		if err := database.DB.Create(&rec).Error; err != nil {
-			log.Printf("[OnlineHistory] save failed: %v", err)
+		lastSavedMu.Lock()
+		last, ok := lastSaved[srv.ID]
+		lastSavedMu.Unlock()
+		if ok && now.Sub(last) < queryInterval {
+			continue // плагин отчитывается — опрашивать незачем
 		}
+		info := gameserver.Query(srv.IP, srv.Port, srv.QueryPort)
+		Record(srv, info.Players, SourceQuery, now)
 	}
 }
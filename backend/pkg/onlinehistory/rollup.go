@@ -0,0 +1,111 @@
+package onlinehistory
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ResolutionHour = "hour"
+	ResolutionDay  = "day"
+)
+
+var rollupMu sync.Mutex
+
+// RawDays - сколько дней хранятся сырые точки (ONLINE_HISTORY_RAW_DAYS, по умолчанию 14)
+func RawDays() int { return envInt("ONLINE_HISTORY_RAW_DAYS", 14) }
+
+// HourlyDays - сколько дней хранятся часовые агрегаты (ONLINE_HISTORY_HOURLY_DAYS, по умолчанию 90)
+func HourlyDays() int { return envInt("ONLINE_HISTORY_HOURLY_DAYS", 90) }
+
+// RetentionDays - сколько дней хранятся суточные агрегаты (ONLINE_HISTORY_DAYS, по умолчанию 730)
+func RetentionDays() int { return envInt("ONLINE_HISTORY_DAYS", 730) }
+
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// lastBucket returns the latest rolled bucket of resolution (zero time if none — тогда считаем всю историю)
+func lastBucket(tx *gorm.DB, resolution string) time.Time {
+	var rollup models.OnlineHistoryRollup
+	if tx.Select("bucket").Where("resolution = ?", resolution).Order("bucket DESC").First(&rollup).Error != nil {
+		return time.Time{}
+	}
+	return rollup.Bucket
+}
+
+// Rollup recomputes hourly buckets from raw points and daily buckets from hourly ones,
+// starting at the last rolled bucket (текущий час/день пересчитываются, пока не закончатся).
+// Called after each Collect.
+func Rollup() {
+	if !rollupMu.TryLock() {
+		return
+	}
+	defer rollupMu.Unlock()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO online_history_rollups
+				(server_id, server_type, resolution, bucket, min_players, avg_players, max_players, peak_at, samples)
+			SELECT server_id, MAX(server_type), ?, date_trunc('hour', recorded_at),
+				MIN(players), AVG(players), MAX(players),
+				(array_agg(recorded_at ORDER BY players DESC, recorded_at ASC))[1], COUNT(*)
+			FROM online_histories
+			WHERE recorded_at >= ?
+			GROUP BY server_id, date_trunc('hour', recorded_at)
+			ON CONFLICT (server_id, resolution, bucket) DO UPDATE SET
+				server_type = excluded.server_type, min_players = excluded.min_players, avg_players = excluded.avg_players,
+				max_players = excluded.max_players, peak_at = excluded.peak_at, samples = excluded.samples`,
+			ResolutionHour, lastBucket(tx, ResolutionHour)).Error; err != nil {
+			return err
+		}
+		// Среднее за сутки — взвешенное по числу точек в часе
+		return tx.Exec(`INSERT INTO online_history_rollups
+				(server_id, server_type, resolution, bucket, min_players, avg_players, max_players, peak_at, samples)
+			SELECT server_id, MAX(server_type), ?, date_trunc('day', bucket),
+				MIN(min_players), SUM(avg_players * samples) / NULLIF(SUM(samples), 0), MAX(max_players),
+				(array_agg(peak_at ORDER BY max_players DESC, peak_at ASC))[1], SUM(samples)
+			FROM online_history_rollups
+			WHERE resolution = ? AND bucket >= ?
+			GROUP BY server_id, date_trunc('day', bucket)
+			ON CONFLICT (server_id, resolution, bucket) DO UPDATE SET
+				server_type = excluded.server_type, min_players = excluded.min_players, avg_players = excluded.avg_players,
+				max_players = excluded.max_players, peak_at = excluded.peak_at, samples = excluded.samples`,
+			ResolutionDay, ResolutionHour, lastBucket(tx, ResolutionDay)).Error
+	})
+	if err != nil {
+		log.Printf("[OnlineHistory] rollup failed: %v", err)
+	}
+}
+
+// Prune deletes raw points older than RawDays and rollups past their retention.
+// Raw points are rolled up first so nothing is lost. Called daily from main.
+func Prune() {
+	Rollup()
+	now := time.Now()
+	steps := []struct {
+		what  string
+		query *gorm.DB
+	}{
+		{"raw points", database.DB.Where("recorded_at < ?", now.AddDate(0, 0, -RawDays())).Delete(&models.OnlineHistory{})},
+		{"hourly rollups", database.DB.Where("resolution = ? AND bucket < ?", ResolutionHour, now.AddDate(0, 0, -HourlyDays())).Delete(&models.OnlineHistoryRollup{})},
+		{"daily rollups", database.DB.Where("resolution = ? AND bucket < ?", ResolutionDay, now.AddDate(0, 0, -RetentionDays())).Delete(&models.OnlineHistoryRollup{})},
+	}
+	for _, s := range steps {
+		if s.query.Error != nil {
+			log.Printf("[OnlineHistory] prune %s: %v", s.what, s.query.Error)
+		} else if s.query.RowsAffected > 0 {
+			log.Printf("[OnlineHistory] pruned %d %s", s.query.RowsAffected, s.what)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package playerhistory keeps time series of player counters: periodic snapshots are stored
+// as hourly deltas, older hours are downsampled to days, days past retention are dropped.
+package playerhistory
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ResolutionHour = "hour"
+	ResolutionDay  = "day"
+)
+
+// Metrics maps API metric name to column of player_stat_deltas / player_counters
+var Metrics = map[string]string{
+	"killedPlayers": "killed_players",
+	"killedMutants": "killed_mutants",
+	"killedAnimals": "killed_animals",
+	"deaths":        "deaths",
+	"playTime":      "play_time",
+	"raidObjects":   "raid_objects",
+	"wood":          "wood",
+	"metal":         "metal",
+	"sulfur":        "sulfur",
+}
+
+var running sync.Mutex
+
+// HourlyDays - сколько дней хранятся часовые дельты (PLAYER_HISTORY_HOURLY_DAYS, по умолчанию 7)
+func HourlyDays() int { return envInt("PLAYER_HISTORY_HOURLY_DAYS", 7) }
+
+// RetentionDays - сколько дней хранится история вообще (PLAYER_HISTORY_DAYS, по умолчанию 365)
+func RetentionDays() int { return envInt("PLAYER_HISTORY_DAYS", 365) }
+
+// current totals of a player (players + player_stats)
+type totals struct {
+	SteamID       string
+	KilledPlayers int
+	KilledMutants int
+	KilledAnimals int
+	Deaths        int
+	PlayTime      int
+	RaidObjects   int
+	Wood          int
+	Metal         int
+	Sulfur        int
+}
+
+// delta returns growth of counter; counter that went down means wipe/reset — считаем с нуля
+func delta(cur, prev int) int {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// Snapshot compares current counters with previous snapshot and adds the difference to the current hour.
+// First snapshot of a player is only a baseline. Called every PLAYER_SNAPSHOT_INTERVAL_MIN from main.
+func Snapshot() {
+	if !running.TryLock() {
+		return
+	}
+	defer running.Unlock()
+
+	var rows []totals
+	err := database.DB.Table("players p").
+		Select(`p.steam_id, p.killed_players, p.killed_mutants, p.killed_animals, p.deaths, p.play_time,
+			COALESCE(s.raid_objects, 0) AS raid_objects, COALESCE(s.wood, 0) AS wood,
+			COALESCE(s.metal, 0) AS metal, COALESCE(s.sulfur, 0) AS sulfur`).
+		Joins("LEFT JOIN player_stats s ON s.steam_id = p.steam_id").
+		Where("p.steam_id <> ''").
+		Scan(&rows).Error
+	if err != nil {
+		log.Printf("[PlayerHistory] failed to load players: %v", err)
+		return
+	}
+	if len(rows) == 0 {
+		return // игроки удалены (идёт полная синхронизация) — базу не трогаем
+	}
+
+	var prevRows []models.PlayerCounters
+	database.DB.Find(&prevRows)
+	prev := make(map[string]models.PlayerCounters, len(prevRows))
+	for _, c := range prevRows {
+		prev[c.SteamID] = c
+	}
+
+	now := time.Now()
+	bucket := now.Truncate(time.Hour)
+	var deltas []models.PlayerStatDelta
+	counters := make([]models.PlayerCounters, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for _, t := range rows {
+		if seen[t.SteamID] {
+			continue
+		}
+		seen[t.SteamID] = true
+		counters = append(counters, models.PlayerCounters{
+			SteamID: t.SteamID, KilledPlayers: t.KilledPlayers, KilledMutants: t.KilledMutants,
+			KilledAnimals: t.KilledAnimals, Deaths: t.Deaths, PlayTime: t.PlayTime,
+			RaidObjects: t.RaidObjects, Wood: t.Wood, Metal: t.Metal, Sulfur: t.Sulfur, UpdatedAt: now,
+		})
+		p, ok := prev[t.SteamID]
+		if !ok {
+			continue
+		}
+		d := models.PlayerStatDelta{
+			SteamID:       t.SteamID,
+			Resolution:    ResolutionHour,
+			Bucket:        bucket,
+			KilledPlayers: delta(t.KilledPlayers, p.KilledPlayers),
+			KilledMutants: delta(t.KilledMutants, p.KilledMutants),
+			KilledAnimals: delta(t.KilledAnimals, p.KilledAnimals),
+			Deaths:        delta(t.Deaths, p.Deaths),
+			PlayTime:      delta(t.PlayTime, p.PlayTime),
+			RaidObjects:   delta(t.RaidObjects, p.RaidObjects),
+			Wood:          delta(t.Wood, p.Wood),
+			Metal:         delta(t.Metal, p.Metal),
+			Sulfur:        delta(t.Sulfur, p.Sulfur),
+		}
+		if d.KilledPlayers|d.KilledMutants|d.KilledAnimals|d.Deaths|d.PlayTime|d.RaidObjects|d.Wood|d.Metal|d.Sulfur != 0 {
+			deltas = append(deltas, d)
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(deltas) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "steam_id"}, {Name: "resolution"}, {Name: "bucket"}},
+				DoUpdates: addColumns(),
+			}).CreateInBatches(&deltas, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&counters, 500).Error
+	})
+	if err != nil {
+		log.Printf("[PlayerHistory] snapshot failed: %v", err)
+	}
+}
+
+// addColumns - ON CONFLICT: прибавить дельту к уже записанной в этом бакете
+func addColumns() clause.Set {
+	set := make(clause.Set, 0, len(Metrics))
+	for _, col := range Metrics {
+		set = append(set, clause.Assignment{
+			Column: clause.Column{Name: col},
+			Value:  gorm.Expr("player_stat_deltas." + col + " + excluded." + col),
+		})
+	}
+	return set
+}
+
+// Compact rolls hourly deltas older than HourlyDays into daily ones and deletes history older than RetentionDays.
+// Called daily from main.
+func Compact() {
+	cols, sums, updates := "", "", ""
+	for _, col := range Metrics {
+		cols += ", " + col
+		sums += ", SUM(" + col + ")"
+		if updates != "" {
+			updates += ", "
+		}
+		updates += col + " = player_stat_deltas." + col + " + excluded." + col
+	}
+	hourlyBefore := time.Now().AddDate(0, 0, -HourlyDays())
+	retainAfter := time.Now().AddDate(0, 0, -RetentionDays())
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Граница — начало суток, чтобы день не оказался разрезан между hour и day строками
+		if err := tx.Exec(`INSERT INTO player_stat_deltas (steam_id, resolution, bucket`+cols+`)
+			SELECT steam_id, ?, date_trunc('day', bucket)`+sums+`
+			FROM player_stat_deltas
+			WHERE resolution = ? AND bucket < date_trunc('day', ?::timestamptz)
+			GROUP BY steam_id, date_trunc('day', bucket)
+			ON CONFLICT (steam_id, resolution, bucket) DO UPDATE SET `+updates,
+			ResolutionDay, ResolutionHour, hourlyBefore).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DELETE FROM player_stat_deltas WHERE resolution = ? AND bucket < date_trunc('day', ?::timestamptz)`,
+			ResolutionHour, hourlyBefore).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bucket < ?", retainAfter).Delete(&models.PlayerStatDelta{}).Error; err != nil {
+			return err
+		}
+		// Игроки, которых давно нет в статистике: при возвращении начнут с новой базы
+		return tx.Where("updated_at < ?", retainAfter).Delete(&models.PlayerCounters{}).Error
+	})
+	if err != nil {
+		log.Printf("[PlayerHistory] compact failed: %v", err)
+	}
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,493 @@
+package rcon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout - per-command timeout (override via RCON_TIMEOUT, seconds)
+	DefaultTimeout = 5 * time.Second
+	// HealthInterval - how often open connections are pinged
+	HealthInterval = 30 * time.Second
+	// IdleTimeout - client without commands and subscribers for this long is closed and removed from the pool
+	IdleTimeout = 10 * time.Minute
+
+	dialTimeout = 5 * time.Second
+	minBackoff  = time.Second
+	maxBackoff  = time.Minute
+)
+
+var (
+	ErrTimeout      = errors.New("rcon: command timed out")
+	ErrDisconnected = errors.New("rcon: connection lost")
+	ErrClosed       = errors.New("rcon: client closed")
+)
+
+// OutcomeUnknown reports whether err happened after the command was written to the server
+// (timeout or lost connection): the command may have run, so re-sending it can run it twice.
+// Dial, auth and write errors mean the command was not executed and can be retried.
+func OutcomeUnknown(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrDisconnected)
+}
+
+// State - connection state of a Client
+type State string
+
+const (
+	StateDisconnected State = "disconnected"
+	StateConnecting   State = "connecting"
+	StateConnected    State = "connected"
+)
+
+// Client keeps one persistent authenticated connection to a game server.
+// Commands are serialized; on failure the client reconnects with exponential backoff.
+type Client struct {
+	target Target
+
+	cmdMu sync.Mutex // one command at a time
+
+	mu      sync.Mutex // guards fields below
+	conn    net.Conn
+	done    chan struct{} // closed when current conn is dropped
+	state   State
+	nextID  int32
+	pending map[int32]*pendingCommand // by command id and by sentinel id
+	backoff time.Duration
+	retryAt time.Time
+	connErr string // last dial/connection error, for retry messages
+	closed  bool
+	stop    chan struct{}
+	stats   Metrics
+	latency time.Duration // sum of command latencies, for average
+	subs    map[chan string]struct{}
+	used    time.Time // last command, Connect or Get, for idle eviction
+}
+
+// pendingCommand collects response packets until the server echoes the sentinel.
+// Long responses are split into several RESPONSE_VALUE packets with the command id;
+// the empty RESPONSE_VALUE sent after the command is answered only after all of them.
+type pendingCommand struct {
+	id       int32
+	sentinel int32
+	parts    []string
+	done     chan string
+}
+
+// Metrics - connection state and command latency of a Client
+type Metrics struct {
+	Address         string     `json:"address"`
+	State           State      `json:"state"`
+	ConnectedSince  *time.Time `json:"connectedSince,omitempty"`
+	Connects        int64      `json:"connects"`
+	ConnectFailures int64      `json:"connectFailures"`
+	Disconnects     int64      `json:"disconnects"`
+	Commands        int64      `json:"commands"`
+	CommandErrors   int64      `json:"commandErrors"`
+	Timeouts        int64      `json:"timeouts"`
+	LastLatencyMs   float64    `json:"lastLatencyMs"`
+	AvgLatencyMs    float64    `json:"avgLatencyMs"`
+	MaxLatencyMs    float64    `json:"maxLatencyMs"`
+	LastPingAt      *time.Time `json:"lastPingAt,omitempty"`
+	LastPingOK      bool       `json:"lastPingOk"`
+	LastPingMs      float64    `json:"lastPingMs"`
+	LastError       string     `json:"lastError,omitempty"`
+	RetryAt         *time.Time `json:"retryAt,omitempty"`
+}
+
+func newClient(t Target) *Client {
+	c := &Client{
+		target:  t,
+		state:   StateDisconnected,
+		pending: make(map[int32]*pendingCommand),
+		subs:    make(map[chan string]struct{}),
+		backoff: minBackoff,
+		stop:    make(chan struct{}),
+		used:    time.Now(),
+	}
+	c.stats.Address = t.addr()
+	go c.healthLoop()
+	return c
+}
+
+func (t Target) addr() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// Execute runs command with the default timeout
+func (c *Client) Execute(command string) (string, error) {
+	return c.ExecuteTimeout(command, commandTimeout())
+}
+
+// ExecuteTimeout runs command and waits for the response at most timeout
+func (c *Client) ExecuteTimeout(command string, timeout time.Duration) (string, error) {
+	if command == "" {
+		return "", fmt.Errorf("rcon: empty command")
+	}
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+	c.touch()
+
+	start := time.Now()
+	resp, err := c.execute(command, timeout)
+	c.recordCommand(time.Since(start), err)
+	return resp, err
+}
+
+func (c *Client) touch() {
+	c.mu.Lock()
+	c.used = time.Now()
+	c.mu.Unlock()
+}
+
+func (c *Client) execute(command string, timeout time.Duration) (string, error) {
+	conn, done, err := c.connect()
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	pc := &pendingCommand{id: c.nextID + 1, sentinel: c.nextID + 2, done: make(chan string, 1)}
+	c.nextID += 2
+	c.pending[pc.id] = pc
+	c.pending[pc.sentinel] = pc
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, pc.id)
+		delete(c.pending, pc.sentinel)
+		c.mu.Unlock()
+	}()
+
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	if err := writePacket(conn, packet{ID: pc.id, Type: typeExecCommand, Body: command}); err != nil {
+		// неполный пакет сервер не выполнит - команду можно повторить
+		if !errors.Is(err, ErrCommandTooLong) {
+			c.drop(conn, err)
+		}
+		return "", fmt.Errorf("rcon execute: %w", err)
+	}
+	if err := writePacket(conn, packet{ID: pc.sentinel, Type: typeResponseValue}); err != nil {
+		// команда уже отправлена, результат неизвестен
+		c.drop(conn, err)
+		return "", fmt.Errorf("%w: %v", ErrDisconnected, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case body := <-pc.done:
+		return body, nil
+	case <-done:
+		return "", ErrDisconnected
+	case <-timer.C:
+		return "", ErrTimeout
+	}
+}
+
+// connect returns the live connection, dialing and authenticating if needed (respects backoff)
+func (c *Client) connect() (net.Conn, chan struct{}, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, nil, ErrClosed
+	}
+	if c.conn != nil {
+		conn, done := c.conn, c.done
+		c.mu.Unlock()
+		return conn, done, nil
+	}
+	if wait := time.Until(c.retryAt); wait > 0 {
+		last := c.connErr
+		c.mu.Unlock()
+		return nil, nil, fmt.Errorf("rcon connect: retry in %s (last error: %s)", wait.Round(time.Second), last)
+	}
+	c.state = StateConnecting
+	c.nextID++
+	authID := c.nextID
+	c.mu.Unlock()
+
+	conn, err := c.dial(authID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.state = StateDisconnected
+		c.stats.ConnectFailures++
+		c.connErr = err.Error()
+		c.retryAt = time.Now().Add(c.backoff)
+		c.backoff *= 2
+		if c.backoff > maxBackoff {
+			c.backoff = maxBackoff
+		}
+		return nil, nil, fmt.Errorf("rcon connect: %w", err)
+	}
+	if c.closed {
+		conn.Close()
+		return nil, nil, ErrClosed
+	}
+	now := time.Now()
+	c.conn = conn
+	c.done = make(chan struct{})
+	c.state = StateConnected
+	c.backoff = minBackoff
+	c.retryAt = time.Time{}
+	c.stats.Connects++
+	c.stats.ConnectedSince = &now
+	go c.readLoop(conn)
+	return c.conn, c.done, nil
+}
+
+func (c *Client) dial(authID int32) (*bufferedConn, error) {
+	conn, err := net.DialTimeout("tcp", c.target.addr(), dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	if err := writePacket(conn, packet{ID: authID, Type: typeAuth, Body: c.target.Password}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// Server answers with an empty RESPONSE_VALUE (optional) followed by AUTH_RESPONSE
+	br := bufio.NewReader(conn)
+	for {
+		p, err := readPacket(br)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if p.Type != typeAuthResponse {
+			continue
+		}
+		if p.ID == -1 {
+			conn.Close()
+			return nil, ErrAuthFailed
+		}
+		break
+	}
+	conn.SetDeadline(time.Time{})
+	// bufio may already hold bytes past the auth response, so keep using the same reader
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+func (c *Client) readLoop(conn *bufferedConn) {
+	for {
+		p, err := readPacket(conn.r)
+		if err != nil {
+			c.drop(conn, err)
+			return
+		}
+		c.mu.Lock()
+		pc, ok := c.pending[p.ID]
+		finished := false
+		if ok && p.ID == pc.id {
+			pc.parts = append(pc.parts, p.Body)
+		} else if ok {
+			// Sentinel echo: everything for the command has arrived
+			delete(c.pending, pc.id)
+			delete(c.pending, pc.sentinel)
+			finished = true
+		}
+		// Late reply to a command that already timed out (or the second sentinel echo) — not console output
+		stale := !ok && p.ID > 0 && p.ID <= c.nextID
+		c.mu.Unlock()
+		if finished {
+			pc.done <- strings.Join(pc.parts, "")
+		}
+		if ok {
+			continue
+		}
+		if !stale && p.Body != "" {
+			c.publish(p.Body)
+		}
+	}
+}
+
+// Subscribe returns channel with unsolicited server output (chat, kills, joins).
+// Slow subscribers lose messages. Call cancel to unsubscribe.
+func (c *Client) Subscribe() (<-chan string, func()) {
+	ch := make(chan string, 64)
+	c.mu.Lock()
+	c.subs[ch] = struct{}{}
+	c.used = time.Now()
+	c.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.subs, ch)
+			c.used = time.Now()
+			c.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (c *Client) publish(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ch := range c.subs {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+}
+
+// Connect establishes the connection if it is not up yet (e.g. to start receiving server output)
+func (c *Client) Connect() error {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+	c.touch()
+	_, _, err := c.connect()
+	return err
+}
+
+// drop closes conn (if it is still current); the next command re-dials right away,
+// backoff applies only after a failed dial (see connect)
+func (c *Client) drop(conn net.Conn, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != conn {
+		return
+	}
+	conn.Close()
+	close(c.done)
+	c.conn = nil
+	c.done = nil
+	c.state = StateDisconnected
+	c.stats.Disconnects++
+	c.stats.ConnectedSince = nil
+	if err != nil && !c.closed {
+		c.connErr = err.Error()
+	}
+}
+
+func (c *Client) recordCommand(d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Commands++
+	if err != nil {
+		c.stats.CommandErrors++
+		c.stats.LastError = err.Error()
+		if errors.Is(err, ErrTimeout) {
+			c.stats.Timeouts++
+		}
+		return
+	}
+	ms := float64(d.Microseconds()) / 1000
+	c.latency += d
+	c.stats.LastLatencyMs = ms
+	if ms > c.stats.MaxLatencyMs {
+		c.stats.MaxLatencyMs = ms
+	}
+	ok := c.stats.Commands - c.stats.CommandErrors
+	c.stats.AvgLatencyMs = float64(c.latency.Microseconds()) / 1000 / float64(ok)
+}
+
+// healthLoop pings open connections; a timed out ping drops the connection so the next command re-dials.
+// Disconnected clients are not re-dialed here, and idle ones are closed and removed from the pool.
+func (c *Client) healthLoop() {
+	ticker := time.NewTicker(HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+		if evictIdle(c) {
+			return
+		}
+		c.ping()
+	}
+}
+
+// idle reports whether the client has no subscribers and was not used for IdleTimeout
+func (c *Client) idle() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.subs) == 0 && time.Since(c.used) > IdleTimeout
+}
+
+// ping runs the ping command on the open connection; it is not counted in command metrics
+func (c *Client) ping() {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return
+	}
+
+	start := time.Now()
+	_, err := c.execute(pingCommand(), commandTimeout())
+	now := time.Now()
+	c.mu.Lock()
+	c.stats.LastPingAt = &now
+	c.stats.LastPingOK = err == nil
+	if err == nil {
+		c.stats.LastPingMs = float64(now.Sub(start).Microseconds()) / 1000
+	}
+	c.mu.Unlock()
+	if errors.Is(err, ErrTimeout) {
+		c.drop(conn, err)
+	}
+}
+
+// Metrics returns a snapshot of client metrics
+func (c *Client) Metrics() Metrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.stats
+	m.State = c.state
+	if c.conn == nil && !c.retryAt.IsZero() {
+		retry := c.retryAt
+		m.RetryAt = &retry
+	}
+	return m
+}
+
+// Close stops health checks and closes the connection
+func (c *Client) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.stop)
+	conn := c.conn
+	c.mu.Unlock()
+	if conn != nil {
+		c.drop(conn, nil)
+	}
+}
+
+// bufferedConn keeps bufio.Reader used during auth together with the connection
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func commandTimeout() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("RCON_TIMEOUT")); err == nil && v > 0 {
+		return time.Duration(v) * time.Second
+	}
+	return DefaultTimeout
+}
+
+func pingCommand() string {
+	if cmd := os.Getenv("RCON_PING_COMMAND"); cmd != "" {
+		return cmd
+	}
+	return "status"
+}
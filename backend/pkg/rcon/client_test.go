@@ -0,0 +1,228 @@
+package rcon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeServer - minimal Source RCON server: "part a b c" answers with one packet per word,
+// "slow" answers after 300ms, "drop" closes the connection, "say x" also sends x as server output
+type fakeServer struct {
+	ln    net.Listener
+	dials int32
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&s.dials, 1)
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) target(password string) Target {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return Target{Host: "127.0.0.1", Port: addr.Port, Password: password}
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	auth, err := readPacket(r)
+	if err != nil {
+		return
+	}
+	id := auth.ID
+	if auth.Body != "secret" {
+		id = -1
+	}
+	writePacket(conn, packet{ID: auth.ID, Type: typeResponseValue})
+	writePacket(conn, packet{ID: id, Type: typeAuthResponse})
+	if id == -1 {
+		return
+	}
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		if p.Type == typeResponseValue {
+			// sentinel: echoed after the previous command is answered
+			writePacket(conn, packet{ID: p.ID, Type: typeResponseValue})
+			continue
+		}
+		fields := strings.Fields(p.Body)
+		switch fields[0] {
+		case "part":
+			for _, f := range fields[1:] {
+				writePacket(conn, packet{ID: p.ID, Type: typeResponseValue, Body: f})
+			}
+		case "slow":
+			time.Sleep(300 * time.Millisecond)
+			writePacket(conn, packet{ID: p.ID, Type: typeResponseValue, Body: "late"})
+		case "drop":
+			return
+		case "say":
+			writePacket(conn, packet{ID: 0, Type: typeResponseValue, Body: strings.Join(fields[1:], " ")})
+			writePacket(conn, packet{ID: p.ID, Type: typeResponseValue, Body: "ok"})
+		default:
+			writePacket(conn, packet{ID: p.ID, Type: typeResponseValue, Body: p.Body})
+		}
+	}
+}
+
+func TestClientMultiPacketResponse(t *testing.T) {
+	c := newClient(newFakeServer(t).target("secret"))
+	defer c.Close()
+	got, err := c.Execute("part a b c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "abc" {
+		t.Errorf("response = %q, want %q", got, "abc")
+	}
+}
+
+func TestClientServerOutput(t *testing.T) {
+	c := newClient(newFakeServer(t).target("secret"))
+	defer c.Close()
+	out, cancel := c.Subscribe()
+	defer cancel()
+	got, err := c.Execute("say hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ok" {
+		t.Errorf("response = %q, want %q", got, "ok")
+	}
+	select {
+	case line := <-out:
+		if line != "hello" {
+			t.Errorf("server output = %q, want %q", line, "hello")
+		}
+	case <-time.After(time.Second):
+		t.Error("server output not published")
+	}
+}
+
+func TestClientTimeoutDoesNotLeakLateReply(t *testing.T) {
+	c := newClient(newFakeServer(t).target("secret"))
+	defer c.Close()
+	out, cancel := c.Subscribe()
+	defer cancel()
+	_, err := c.ExecuteTimeout("slow", 100*time.Millisecond)
+	if !errors.Is(err, ErrTimeout) || !OutcomeUnknown(err) {
+		t.Fatalf("error = %v, want ErrTimeout", err)
+	}
+	// ответ на просроченную команду не попадает ни в следующий ответ, ни в вывод сервера
+	got, err := c.Execute("echo next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "echo next" {
+		t.Errorf("response = %q, want %q", got, "echo next")
+	}
+	select {
+	case line := <-out:
+		t.Errorf("late reply published as server output: %q", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClientReconnectsAfterDisconnect(t *testing.T) {
+	s := newFakeServer(t)
+	c := newClient(s.target("secret"))
+	defer c.Close()
+	_, err := c.Execute("drop")
+	if !errors.Is(err, ErrDisconnected) || !OutcomeUnknown(err) {
+		t.Fatalf("error = %v, want ErrDisconnected", err)
+	}
+	// обрыв не включает backoff: следующая команда сразу переподключается
+	got, err := c.Execute("echo again")
+	if err != nil {
+		t.Fatalf("execute after disconnect: %v", err)
+	}
+	if got != "echo again" {
+		t.Errorf("response = %q, want %q", got, "echo again")
+	}
+	if n := atomic.LoadInt32(&s.dials); n != 2 {
+		t.Errorf("dials = %d, want 2", n)
+	}
+	if m := c.Metrics(); m.RetryAt != nil || m.State != StateConnected {
+		t.Errorf("metrics after reconnect: state %s, retryAt %v", m.State, m.RetryAt)
+	}
+}
+
+func TestClientDialFailureBacksOff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	c := newClient(Target{Host: "127.0.0.1", Port: port, Password: "secret"})
+	defer c.Close()
+
+	_, err = c.Execute("echo")
+	if err == nil || OutcomeUnknown(err) {
+		t.Fatalf("error = %v, want dial error with known outcome", err)
+	}
+	_, err = c.Execute("echo")
+	if err == nil || !strings.Contains(err.Error(), "retry in") {
+		t.Errorf("second error = %v, want backoff", err)
+	}
+	if m := c.Metrics(); m.RetryAt == nil || m.ConnectFailures != 1 {
+		t.Errorf("metrics: retryAt %v, connectFailures %d", m.RetryAt, m.ConnectFailures)
+	}
+}
+
+func TestClientAuthFailure(t *testing.T) {
+	c := newClient(newFakeServer(t).target("wrong"))
+	defer c.Close()
+	_, err := c.Execute("echo")
+	if !errors.Is(err, ErrAuthFailed) || OutcomeUnknown(err) {
+		t.Errorf("error = %v, want ErrAuthFailed", err)
+	}
+}
+
+func TestOutcomeUnknown(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"timeout", ErrTimeout, true},
+		{"disconnected", ErrDisconnected, true},
+		{"wrapped timeout", fmt.Errorf("%q: %w", "give", ErrTimeout), true},
+		{"dial", fmt.Errorf("rcon connect: %w", errors.New("connection refused")), false},
+		{"auth", fmt.Errorf("rcon connect: %w", ErrAuthFailed), false},
+		{"closed", ErrClosed, false},
+		{"too long", fmt.Errorf("rcon execute: %w", ErrCommandTooLong), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OutcomeUnknown(tt.err); got != tt.want {
+				t.Errorf("OutcomeUnknown(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
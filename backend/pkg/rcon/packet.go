@@ -0,0 +1,72 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Source RCON packet types
+const (
+	typeResponseValue = 0
+	typeExecCommand   = 2
+	typeAuthResponse  = 2
+	typeAuth          = 3
+)
+
+const (
+	packetHeaderSize  = 8 // id + type
+	packetPaddingSize = 2 // body null + empty string null
+	maxPacketSize     = 4096 + packetHeaderSize + packetPaddingSize
+	// Rust servers may send larger console packets, so reading is more lenient than writing
+	maxReadPacketSize = 64 * 1024
+)
+
+var (
+	ErrAuthFailed      = errors.New("rcon: authentication failed")
+	ErrCommandTooLong  = errors.New("rcon: command too long")
+	ErrInvalidResponse = errors.New("rcon: invalid response packet")
+)
+
+type packet struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+func writePacket(w io.Writer, p packet) error {
+	size := int32(len(p.Body)) + packetHeaderSize + packetPaddingSize
+	if size > maxPacketSize {
+		return ErrCommandTooLong
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size+4))
+	binary.Write(buf, binary.LittleEndian, size)
+	binary.Write(buf, binary.LittleEndian, p.ID)
+	binary.Write(buf, binary.LittleEndian, p.Type)
+	buf.WriteString(p.Body)
+	buf.Write([]byte{0, 0})
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	var p packet
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return p, err
+	}
+	if size < packetHeaderSize+packetPaddingSize || size > maxReadPacketSize {
+		return p, fmt.Errorf("%w: size %d", ErrInvalidResponse, size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return p, err
+	}
+	p.ID = int32(binary.LittleEndian.Uint32(buf[0:4]))
+	p.Type = int32(binary.LittleEndian.Uint32(buf[4:8]))
+	p.Body = string(bytes.TrimRight(buf[8:], "\x00"))
+	return p, nil
+}
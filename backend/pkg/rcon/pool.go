@@ -0,0 +1,60 @@
+package rcon
+
+import (
+	"sort"
+	"sync"
+)
+
+// Persistent clients, one per server address
+var (
+	pool   = make(map[string]*Client)
+	poolMu sync.Mutex
+)
+
+// Get returns pooled client for target. Changing password replaces the client.
+func Get(t Target) *Client {
+	key := t.addr()
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if c, ok := pool[key]; ok {
+		if c.target == t {
+			c.touch()
+			return c
+		}
+		c.Close()
+	}
+	c := newClient(t)
+	pool[key] = c
+	return c
+}
+
+// evictIdle closes c and removes it from the pool if it is idle; checked under poolMu so Get does not hand it out meanwhile
+func evictIdle(c *Client) bool {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if !c.idle() {
+		return false
+	}
+	if pool[c.target.addr()] == c {
+		delete(pool, c.target.addr())
+	}
+	c.Close()
+	return true
+}
+
+// AllMetrics returns metrics of all pooled clients sorted by address
+func AllMetrics() []Metrics {
+	poolMu.Lock()
+	clients := make([]*Client, 0, len(pool))
+	for _, c := range pool {
+		clients = append(clients, c)
+	}
+	poolMu.Unlock()
+
+	out := make([]Metrics, 0, len(clients))
+	for _, c := range clients {
+		out = append(out, c.Metrics())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
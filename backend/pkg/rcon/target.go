@@ -0,0 +1,106 @@
+package rcon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+)
+
+// DefaultPort is Rust Legacy default RCON port
+const DefaultPort = 28016
+
+// Target - RCON endpoint of a single game server
+type Target struct {
+	Host     string
+	Port     int
+	Password string
+}
+
+// EnvTarget returns RCON target from RCON_HOST / RCON_PORT / RCON_PASSWORD
+func EnvTarget() Target {
+	port := DefaultPort
+	if p := os.Getenv("RCON_PORT"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil {
+			port = v
+		}
+	}
+	return Target{
+		Host:     os.Getenv("RCON_HOST"),
+		Port:     port,
+		Password: os.Getenv("RCON_PASSWORD"),
+	}
+}
+
+// TargetForServer returns RCON target of the server. Servers without own RCON settings use env.
+func TargetForServer(srv models.ServerInfo) Target {
+	t := EnvTarget()
+	if srv.RconPort == 0 && srv.RconPassword == "" {
+		return t
+	}
+	if srv.IP != "" {
+		t.Host = srv.IP
+	}
+	if srv.RconPort > 0 {
+		t.Port = srv.RconPort
+	}
+	if srv.RconPassword != "" {
+		t.Password = srv.RconPassword
+	}
+	return t
+}
+
+// ResolveTarget returns configured RCON target by server ID (0 = env)
+func ResolveTarget(serverID uint) (Target, error) {
+	if serverID == 0 {
+		t := EnvTarget()
+		if !t.Configured() {
+			return t, fmt.Errorf("rcon not configured")
+		}
+		return t, nil
+	}
+	var srv models.ServerInfo
+	if err := database.DB.First(&srv, serverID).Error; err != nil {
+		return Target{}, fmt.Errorf("server %d not found", serverID)
+	}
+	t := TargetForServer(srv)
+	if !t.Configured() {
+		return t, fmt.Errorf("rcon not configured for server %d", serverID)
+	}
+	return t, nil
+}
+
+// Configured reports whether target has everything needed to connect
+func (t Target) Configured() bool {
+	return t.Host != "" && t.Port > 0 && t.Password != ""
+}
+
+// Execute runs command on target as is
+func (t Target) Execute(command string) (string, error) {
+	return run(t, command)
+}
+
+// AllTargets returns configured RCON targets of all servers (одинаковые адреса — один раз).
+// Without servers in DB returns env target if it is configured.
+func AllTargets() []Target {
+	var servers []models.ServerInfo
+	database.DB.Order("id ASC").Find(&servers)
+	seen := make(map[string]bool)
+	var targets []Target
+	for _, srv := range servers {
+		t := TargetForServer(srv)
+		if !t.Configured() || seen[t.addr()] {
+			continue
+		}
+		seen[t.addr()] = true
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		if t := EnvTarget(); t.Configured() {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,178 @@
+package rcon
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Placeholders supported in shop command templates
+var Placeholders = []string{"steamid", "username", "quantity", "order_id"}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z_]+)\s*\}\}`)
+
+// Vars - values substituted into command template
+type Vars map[string]string
+
+// Lines splits template into commands: one per non-empty line (# starts a comment)
+func Lines(template string) []string {
+	var lines []string
+	for _, line := range strings.Split(template, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ValidateTemplate checks that template has commands and only known placeholders
+func ValidateTemplate(template string) error {
+	lines := Lines(template)
+	if len(lines) == 0 {
+		return fmt.Errorf("rcon template: no commands")
+	}
+	known := make(map[string]bool, len(Placeholders))
+	for _, p := range Placeholders {
+		known[p] = true
+	}
+	for _, line := range lines {
+		for _, m := range placeholderRe.FindAllStringSubmatch(line, -1) {
+			if !known[strings.ToLower(m[1])] {
+				return fmt.Errorf("rcon template: unknown placeholder {{%s}} (allowed: %s)", m[1], strings.Join(Placeholders, ", "))
+			}
+		}
+		if strings.Contains(placeholderRe.ReplaceAllString(line, ""), "{{") {
+			return fmt.Errorf("rcon template: malformed placeholder in %q", line)
+		}
+	}
+	return nil
+}
+
+// HasPlaceholder reports whether template uses {{name}}
+func HasPlaceholder(template, name string) bool {
+	for _, line := range Lines(template) {
+		for _, m := range placeholderRe.FindAllStringSubmatch(line, -1) {
+			if strings.EqualFold(m[1], name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Render substitutes placeholders and returns one command per template line.
+// Values are escaped; outside of a quoted template string they are also wrapped in quotes,
+// so a value can never split into extra arguments or commands.
+func Render(template string, vars Vars) ([]string, error) {
+	if err := ValidateTemplate(template); err != nil {
+		return nil, err
+	}
+	lines := Lines(template)
+	cmds := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var b strings.Builder
+		last := 0
+		for _, loc := range placeholderRe.FindAllStringSubmatchIndex(line, -1) {
+			name := strings.ToLower(line[loc[2]:loc[3]])
+			v, ok := vars[name]
+			if !ok {
+				return nil, fmt.Errorf("rcon template: no value for {{%s}}", name)
+			}
+			b.WriteString(line[last:loc[0]])
+			if insideQuotes(line[:loc[0]]) {
+				b.WriteString(Escape(v))
+			} else {
+				b.WriteString(Quote(v))
+			}
+			last = loc[1]
+		}
+		b.WriteString(line[last:])
+		cmds = append(cmds, b.String())
+	}
+	return cmds, nil
+}
+
+// Escape makes value safe inside a quoted console argument:
+// drops control characters (newlines end a command) and escapes \ and "
+func Escape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			continue
+		case r == '\\' || r == '"':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Quote returns value as a single quoted console argument
+func Quote(value string) string {
+	return `"` + Escape(value) + `"`
+}
+
+// Suspicious reports whether value carries characters that could break out of an argument
+func Suspicious(value string) bool {
+	return Escape(value) != value || strings.ContainsAny(value, ";\"")
+}
+
+// insideQuotes reports whether an unclosed double quote precedes the end of s
+func insideQuotes(s string) bool {
+	in := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			in = !in
+		}
+	}
+	return in
+}
+
+// CommandName returns console command of the line (first word, lower case): "inv.giveplayer"
+func CommandName(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimLeft(fields[0], "/"))
+}
+
+// CheckAllowed returns error for the first command whose name is not in allowed (lower-case names)
+func CheckAllowed(cmds []string, allowed map[string]bool) error {
+	for _, cmd := range cmds {
+		name := CommandName(cmd)
+		if !allowed[name] {
+			return fmt.Errorf("rcon: command %q is not in allowlist", name)
+		}
+	}
+	return nil
+}
+
+// LegacyTemplate converts old "*"-style command to template syntax ("give * wood" -> "give {{steamid}} wood")
+func LegacyTemplate(command string) string {
+	if command == "" || strings.Contains(command, "{{") {
+		return command
+	}
+	return strings.ReplaceAll(command, "*", "{{steamid}}")
+}
+
+// TemplateCommandNames returns sorted unique command names used in template
+func TemplateCommandNames(template string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, line := range Lines(template) {
+		if name := CommandName(line); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,122 @@
+package rcon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{"empty", "", nil},
+		{"single", "say hi", []string{"say hi"}},
+		{"trims and skips blank lines", "  say hi  \n\n\t\nkick x\n", []string{"say hi", "kick x"}},
+		{"skips comments", "# header\nsay hi\n  # indented comment\nkick x", []string{"say hi", "kick x"}},
+		{"windows line endings", "say hi\r\nkick x\r\n", []string{"say hi", "kick x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.template); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	vars := Vars{"steamid": "76561198000000001", "username": "Bob", "quantity": "10", "order_id": "42"}
+	tests := []struct {
+		name     string
+		template string
+		vars     Vars
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "values are quoted",
+			template: `inv.giveplayer {{steamid}} "Wood" {{quantity}}`,
+			want:     []string{`inv.giveplayer "76561198000000001" "Wood" "10"`},
+		},
+		{
+			name:     "several lines",
+			template: "# выдача\ninv.giveplayer {{steamid}} Wood 1\nsay {{username}} bought order {{order_id}}",
+			want:     []string{`inv.giveplayer "76561198000000001" Wood 1`, `say "Bob" bought order "42"`},
+		},
+		{
+			name:     "placeholder names are case and space insensitive",
+			template: "kick {{ SteamID }}",
+			want:     []string{`kick "76561198000000001"`},
+		},
+		{
+			name:     "inside a quoted string only escaped",
+			template: `say "Thanks, {{username}}!"`,
+			vars:     Vars{"username": `Bob"; ban *`},
+			want:     []string{`say "Thanks, Bob\"; ban *!"`},
+		},
+		{
+			name:     "control characters dropped",
+			template: "say {{username}}",
+			vars:     Vars{"username": "Bob\nquit"},
+			want:     []string{`say "Bobquit"`},
+		},
+		{
+			name:     "backslash escaped",
+			template: "say {{username}}",
+			vars:     Vars{"username": `a\b`},
+			want:     []string{`say "a\\b"`},
+		},
+		{name: "no commands", template: "# only comment\n", wantErr: true},
+		{name: "unknown placeholder", template: "say {{password}}", wantErr: true},
+		{name: "malformed placeholder", template: "say {{steamid}", wantErr: true},
+		{name: "missing value", template: "say {{quantity}}", vars: Vars{"steamid": "1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.vars
+			if v == nil {
+				v = vars
+			}
+			got, err := Render(tt.template, v)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Render(%q): expected error, got %q", tt.template, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render(%q): %v", tt.template, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAllowed(t *testing.T) {
+	allowed := map[string]bool{"inv.giveplayer": true, "say": true}
+	tests := []struct {
+		name    string
+		cmds    []string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"allowed", []string{`inv.giveplayer "1" Wood 1`, `say "hi"`}, false},
+		{"name is case insensitive", []string{"SAY hi"}, false},
+		{"leading slash ignored", []string{"/say hi"}, false},
+		{"not allowed", []string{"say hi", "quit"}, true},
+		{"prefix of allowed name", []string{"inv.give 1"}, true},
+		{"blank command", []string{"   "}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAllowed(tt.cmds, allowed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAllowed(%q) error = %v, wantErr %v", tt.cmds, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package rconjobs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+	"rust-legacy-site/pkg/cron"
+	"rust-legacy-site/pkg/rcon"
+)
+
+// runsKeptPerJob - сколько последних запусков храним в истории каждой задачи
+const runsKeptPerJob = 200
+
+// ErrAlreadyRunning is returned when the job is still executing its previous run
+var ErrAlreadyRunning = errors.New("job is already running")
+
+var (
+	running   = make(map[uint]bool)
+	runningMu sync.Mutex
+)
+
+// NextRun computes next activation of cron expression after t (UTC)
+func NextRun(schedule string, after time.Time) (*time.Time, error) {
+	s, err := cron.Parse(schedule)
+	if err != nil {
+		return nil, err
+	}
+	next := s.Next(after.UTC())
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron: schedule never fires")
+	}
+	return &next, nil
+}
+
+// Tick runs every minute from main: executes all enabled jobs whose NextRunAt has come
+func Tick() {
+	now := time.Now().UTC()
+	var jobs []models.RconJob
+	if err := database.DB.Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now).Find(&jobs).Error; err != nil {
+		log.Printf("[RconJobs] failed to list jobs: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		// Сдвигаем NextRunAt до запуска, чтобы долгая задача не стартовала повторно на следующем тике
+		next, err := NextRun(job.Schedule, now)
+		if err != nil {
+			log.Printf("[RconJobs] job %d: %v — disabling", job.ID, err)
+			database.DB.Model(&models.RconJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{"enabled": false, "next_run_at": nil})
+			continue
+		}
+		database.DB.Model(&models.RconJob{}).Where("id = ?", job.ID).Update("next_run_at", next)
+		go func(j models.RconJob) {
+			if _, err := Run(j, "schedule"); err != nil && !errors.Is(err, ErrAlreadyRunning) {
+				log.Printf("[RconJobs] job %d (%s) failed: %v", j.ID, j.Name, err)
+			}
+		}(job)
+	}
+}
+
+// Run executes job commands one by one and records the run. Overlapping runs of the same job are skipped.
+func Run(job models.RconJob, trigger string) (*models.RconJobRun, error) {
+	run := &models.RconJobRun{JobID: job.ID, Trigger: trigger, StartedAt: time.Now()}
+
+	runningMu.Lock()
+	if running[job.ID] {
+		runningMu.Unlock()
+		run.Status = "skipped"
+		run.Output = "previous run still in progress"
+		run.FinishedAt = run.StartedAt
+		record(job, run)
+		return run, ErrAlreadyRunning
+	}
+	running[job.ID] = true
+	runningMu.Unlock()
+	defer func() {
+		runningMu.Lock()
+		delete(running, job.ID)
+		runningMu.Unlock()
+	}()
+
+	target, err := rcon.ResolveTarget(job.ServerID)
+	var runErr error
+	var out strings.Builder
+	if err != nil {
+		runErr = err
+	} else {
+		for _, cmd := range Commands(job.Commands) {
+			resp, err := target.Execute(cmd)
+			fmt.Fprintf(&out, "> %s\n", cmd)
+			if err != nil {
+				fmt.Fprintf(&out, "error: %v\n", err)
+				runErr = err
+				break
+			}
+			if resp != "" {
+				out.WriteString(strings.TrimRight(resp, "\n") + "\n")
+			}
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	run.Output = out.String()
+	run.Status = "ok"
+	if runErr != nil {
+		run.Status = "failed"
+		if out.Len() == 0 {
+			run.Output = runErr.Error()
+		}
+	}
+	record(job, run)
+	return run, runErr
+}
+
+// Commands splits job command text into non-empty lines (# starts a comment)
+func Commands(text string) []string {
+	var cmds []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmds = append(cmds, line)
+	}
+	return cmds
+}
+
+func record(job models.RconJob, run *models.RconJobRun) {
+	if err := database.DB.Create(run).Error; err != nil {
+		log.Printf("[RconJobs] save run failed: %v", err)
+	}
+	database.DB.Model(&models.RconJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"last_run_at": run.StartedAt,
+		"last_status": run.Status,
+		"last_result": run.Output,
+	})
+	// Ограничиваем историю: оставляем последние runsKeptPerJob запусков
+	database.DB.Exec(`DELETE FROM rcon_job_runs WHERE job_id = ? AND id NOT IN (
+		SELECT id FROM rcon_job_runs WHERE job_id = ? ORDER BY id DESC LIMIT ?)`, job.ID, job.ID, runsKeptPerJob)
+}
@@ -0,0 +1,93 @@
+// Package sanitize cleans admin-authored rich text (HTML) before it is stored.
+package sanitize
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"rust-legacy-site/pkg/media"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// defaultEmbedHosts - hosts allowed in <iframe src> (видео), override with SANITIZE_EMBED_HOSTS
+var defaultEmbedHosts = []string{
+	"www.youtube.com",
+	"youtube.com",
+	"www.youtube-nocookie.com",
+	"player.vimeo.com",
+	"player.twitch.tv",
+	"clips.twitch.tv",
+	"rutube.ru",
+	"vk.com",
+	"vkvideo.ru",
+}
+
+var (
+	policy     *bluemonday.Policy
+	policyOnce sync.Once
+)
+
+// EmbedHosts returns hosts allowed for video embeds
+func EmbedHosts() []string {
+	v := strings.TrimSpace(os.Getenv("SANITIZE_EMBED_HOSTS"))
+	if v == "" {
+		return defaultEmbedHosts
+	}
+	var hosts []string
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+func buildPolicy() *bluemonday.Policy {
+	// UGC: текст, списки, таблицы, картинки, ссылки (http/https/mailto/относительные)
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[A-Za-z0-9_\- ]{1,200}$`)).Globally()
+	p.AllowAttrs("target").Matching(regexp.MustCompile(`^_blank$`)).OnElements("a")
+	// Ссылки на внешние сайты: rel="noopener noreferrer" + target=_blank, свои ссылки без nofollow
+	p.RequireNoFollowOnLinks(false)
+	p.RequireNoReferrerOnFullyQualifiedLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	// Видео-вставки только с разрешённых хостов, только https
+	quoted := make([]string, 0, len(EmbedHosts()))
+	for _, h := range EmbedHosts() {
+		quoted = append(quoted, regexp.QuoteMeta(h))
+	}
+	embedSrc := regexp.MustCompile(`^https://(` + strings.Join(quoted, "|") + `)/[^\s"'<>]*$`)
+	p.AllowAttrs("src").Matching(embedSrc).OnElements("iframe")
+	p.AllowAttrs("width", "height").Matching(bluemonday.NumberOrPercent).OnElements("iframe", "video")
+	p.AllowAttrs("title").OnElements("iframe")
+	p.AllowAttrs("allowfullscreen").Matching(regexp.MustCompile(`^(|allowfullscreen|true)$`)).OnElements("iframe")
+	p.AllowAttrs("frameborder").Matching(bluemonday.Integer).OnElements("iframe")
+	p.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("iframe", "img")
+	// Видеофайлы — со своих загрузок (pkg/media) или с тех же хостов, что и iframe
+	mediaSrc := regexp.MustCompile(`^(` + regexp.QuoteMeta(media.FromEnv().URL("")) + `[0-9]{4}/[0-9]{2}/[A-Za-z0-9_-]+\.[a-z0-9]+|https://(` + strings.Join(quoted, "|") + `)/[^\s"'<>]*)$`)
+	p.AllowAttrs("src", "poster").Matching(mediaSrc).OnElements("video")
+	p.AllowAttrs("controls", "muted", "loop", "playsinline").Matching(regexp.MustCompile(`^(|controls|muted|loop|playsinline|true)$`)).OnElements("video")
+	p.AllowAttrs("preload").Matching(regexp.MustCompile(`^(none|metadata|auto)$`)).OnElements("video")
+	p.AllowAttrs("src").Matching(mediaSrc).OnElements("source")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^video/[a-z0-9.+-]+$`)).OnElements("source")
+	p.AllowElements("iframe", "video", "source")
+	p.SkipElementsContent("iframe")
+	return p
+}
+
+// HTML returns s with everything outside of the allowlist removed
+func HTML(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	policyOnce.Do(func() { policy = buildPolicy() })
+	// iframe с отброшенным src бесполезен — удаляем целиком
+	return emptyIframeRe.ReplaceAllString(policy.Sanitize(s), "")
+}
+
+// emptyIframeRe matches iframes whose src was dropped by the policy
+var emptyIframeRe = regexp.MustCompile(`<iframe(\s+(width|height|title|allowfullscreen|frameborder|loading)="[^"]*")*\s*>\s*</iframe>`)
@@ -0,0 +1,85 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		keep    []string
+		removed []string
+	}{
+		{
+			name:    "script and handlers",
+			in:      `<p onclick="x()">Hi<script>alert(1)</script></p>`,
+			keep:    []string{"<p>Hi</p>"},
+			removed: []string{"script", "onclick"},
+		},
+		{
+			name:    "javascript link",
+			in:      `<a href="javascript:alert(1)">x</a>`,
+			removed: []string{"javascript:"},
+		},
+		{
+			name: "external link opens in new tab",
+			in:   `<a href="https://example.com/">x</a>`,
+			keep: []string{`target="_blank"`, `rel="noreferrer noopener"`},
+		},
+		{
+			name: "allowed iframe",
+			in:   `<iframe src="https://www.youtube.com/embed/abc" width="560" height="315" allowfullscreen></iframe>`,
+			keep: []string{`src="https://www.youtube.com/embed/abc"`, `width="560"`},
+		},
+		{
+			name:    "iframe from other host is dropped",
+			in:      `<p>a</p><iframe src="https://evil.example/embed" width="560"></iframe>`,
+			keep:    []string{"<p>a</p>"},
+			removed: []string{"iframe", "evil"},
+		},
+		{
+			name:    "plain http iframe is dropped",
+			in:      `<iframe src="http://www.youtube.com/embed/abc"></iframe>`,
+			removed: []string{"iframe"},
+		},
+		{
+			name: "video from own media",
+			in:   `<video src="/api/media/files/2026/10/3f9c0a1b2c3d4e5f.mp4" poster="/api/media/files/2026/10/3f9c0a1b2c3d4e5f_thumb.jpg" controls></video>`,
+			keep: []string{`src="/api/media/files/2026/10/3f9c0a1b2c3d4e5f.mp4"`, `poster="/api/media/files/2026/10/3f9c0a1b2c3d4e5f_thumb.jpg"`, "controls"},
+		},
+		{
+			name:    "video from other host",
+			in:      `<video src="https://evil.example/a.mp4" poster="https://evil.example/a.jpg"></video>`,
+			keep:    []string{"<video>"},
+			removed: []string{"evil"},
+		},
+		{
+			name:    "video path outside of media",
+			in:      `<video src="/api/media/files/../../admin/x.mp4"></video>`,
+			removed: []string{"admin"},
+		},
+		{
+			name:    "source src",
+			in:      `<video controls><source src="/api/media/files/2026/10/abc.webm" type="video/webm"><source src="https://evil.example/b.webm" type="video/webm"></video>`,
+			keep:    []string{`<source src="/api/media/files/2026/10/abc.webm" type="video/webm"`},
+			removed: []string{"evil"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HTML(tt.in)
+			for _, s := range tt.keep {
+				if !strings.Contains(got, s) {
+					t.Errorf("HTML(%q) = %q, want it to contain %q", tt.in, got, s)
+				}
+			}
+			for _, s := range tt.removed {
+				if strings.Contains(got, s) {
+					t.Errorf("HTML(%q) = %q, want %q removed", tt.in, got, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package security
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+)
+
+// Event kinds
+const (
+	KindInvalidSteamID = "invalid_steamid"
+	KindRconInjection  = "rcon_injection"
+)
+
+const maxValueLen = 256
+
+// throttleWindow - не больше одной записи (и строки лога) на вид события и IP за окно;
+// остальные считаются и попадают в Count следующей записи или записываются Flush
+const throttleWindow = time.Minute
+
+type event struct {
+	kind, source, ip, value, detail string
+}
+
+type throttleEntry struct {
+	lastSaved  time.Time
+	suppressed int
+	last       event // последнее подавленное событие, для записи из Flush
+}
+
+var (
+	throttleMu sync.Mutex
+	throttle   = make(map[string]*throttleEntry)
+)
+
+// allow reports whether event should be stored now and how many events it aggregates.
+// Events without IP come from internal jobs (заказы, награды) and are not throttled.
+func allow(ev event, now time.Time) (bool, int) {
+	if ev.ip == "" {
+		return true, 1
+	}
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	key := ev.kind + "|" + ev.ip
+	e, ok := throttle[key]
+	if ok && now.Sub(e.lastSaved) < throttleWindow {
+		e.suppressed++
+		e.last = ev
+		return false, 0
+	}
+	if len(throttle) > 10000 {
+		for k, old := range throttle {
+			// с подавленными событиями запись остаётся до Flush
+			if old.suppressed == 0 && now.Sub(old.lastSaved) >= throttleWindow {
+				delete(throttle, k)
+			}
+		}
+	}
+	count := 1
+	if ok {
+		count += e.suppressed
+	}
+	throttle[key] = &throttleEntry{lastSaved: now}
+	return true, count
+}
+
+// takeSuppressed returns events suppressed in windows that are over (with their counts)
+// and marks them as saved, so a burst that stopped is not lost until the next event from that IP
+func takeSuppressed(now time.Time) map[event]int {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	out := make(map[event]int)
+	for k, e := range throttle {
+		if now.Sub(e.lastSaved) < throttleWindow {
+			continue
+		}
+		if e.suppressed == 0 {
+			delete(throttle, k)
+			continue
+		}
+		out[e.last] += e.suppressed
+		e.lastSaved, e.suppressed, e.last = now, 0, event{}
+	}
+	return out
+}
+
+// Log records rejected payload as security event: log line + security_events row.
+// Repeated events of the same kind from one IP are aggregated (one row per minute with Count).
+func Log(kind, source, ip, value, detail string) {
+	ev := event{kind: kind, source: source, ip: ip, value: truncate(value), detail: detail}
+	if ok, count := allow(ev, time.Now()); ok {
+		save(ev, count)
+	}
+}
+
+// Flush stores counts of suppressed events whose window is over. Called every minute from main.
+func Flush() {
+	for ev, count := range takeSuppressed(time.Now()) {
+		save(ev, count)
+	}
+}
+
+func save(ev event, count int) {
+	log.Printf("[Security] %s source=%s ip=%s value=%q count=%d %s", ev.kind, ev.source, ev.ip, ev.value, count, ev.detail)
+	if database.DB == nil {
+		return
+	}
+	if err := database.DB.Create(&models.SecurityEvent{
+		Kind:   ev.kind,
+		Source: ev.source,
+		IP:     ev.ip,
+		Value:  ev.value,
+		Detail: ev.detail,
+		Count:  count,
+	}).Error; err != nil {
+		log.Printf("[Security] save event failed: %v", err)
+	}
+}
+
+// RetentionDays - сколько дней хранятся события (SECURITY_EVENTS_DAYS, по умолчанию 90)
+func RetentionDays() int {
+	if v, err := strconv.Atoi(os.Getenv("SECURITY_EVENTS_DAYS")); err == nil && v > 0 {
+		return v
+	}
+	return 90
+}
+
+// Prune deletes events older than RetentionDays. Called daily from main.
+func Prune() {
+	res := database.DB.Where("created_at < ?", time.Now().AddDate(0, 0, -RetentionDays())).Delete(&models.SecurityEvent{})
+	if res.Error != nil {
+		log.Printf("[Security] prune: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("[Security] pruned %d events", res.RowsAffected)
+	}
+}
+
+func truncate(s string) string {
+	if len(s) <= maxValueLen {
+		return s
+	}
+	s = s[:maxValueLen]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
@@ -0,0 +1,75 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func resetThrottle() {
+	throttleMu.Lock()
+	throttle = make(map[string]*throttleEntry)
+	throttleMu.Unlock()
+}
+
+func TestAllowAggregatesPerKindAndIP(t *testing.T) {
+	resetThrottle()
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	ev := event{kind: KindInvalidSteamID, source: "checkout", ip: "10.0.0.1", value: "x"}
+	steps := []struct {
+		name      string
+		ev        event
+		at        time.Duration
+		wantOK    bool
+		wantCount int
+	}{
+		{"first is stored", ev, 0, true, 1},
+		{"repeat suppressed", ev, 10 * time.Second, false, 0},
+		{"repeat suppressed again", ev, 20 * time.Second, false, 0},
+		{"other IP stored", event{kind: ev.kind, ip: "10.0.0.2"}, 30 * time.Second, true, 1},
+		{"other kind stored", event{kind: KindRconInjection, ip: ev.ip}, 30 * time.Second, true, 1},
+		{"after window carries suppressed", ev, 61 * time.Second, true, 3},
+		{"internal events are not throttled", event{kind: ev.kind}, 62 * time.Second, true, 1},
+		{"internal events are not throttled again", event{kind: ev.kind}, 62 * time.Second, true, 1},
+	}
+	for _, st := range steps {
+		t.Run(st.name, func(t *testing.T) {
+			ok, count := allow(st.ev, start.Add(st.at))
+			if ok != st.wantOK || count != st.wantCount {
+				t.Errorf("allow() = %v, %d, want %v, %d", ok, count, st.wantOK, st.wantCount)
+			}
+		})
+	}
+}
+
+func TestTakeSuppressed(t *testing.T) {
+	resetThrottle()
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	a := event{kind: KindInvalidSteamID, source: "checkout", ip: "10.0.0.1", value: "a"}
+	b := event{kind: KindRconInjection, source: "order", ip: "10.0.0.2", value: "b"}
+	allow(a, start)
+	allow(a, start.Add(time.Second))
+	last := a
+	last.value = "a2"
+	allow(last, start.Add(2*time.Second))
+	allow(b, start.Add(30*time.Second))
+
+	if got := takeSuppressed(start.Add(30 * time.Second)); len(got) != 0 {
+		t.Fatalf("window not over, got %v", got)
+	}
+	got := takeSuppressed(start.Add(61 * time.Second))
+	if len(got) != 1 || got[last] != 2 {
+		t.Fatalf("takeSuppressed() = %v, want {%v: 2}", got, last)
+	}
+	// подавленные уже записаны — следующее событие не несёт их в Count
+	if ok, count := allow(a, start.Add(2*time.Minute+time.Second)); !ok || count != 1 {
+		t.Errorf("allow() after flush = %v, %d, want true, 1", ok, count)
+	}
+	// без подавленных записи забываются
+	takeSuppressed(start.Add(10 * time.Minute))
+	throttleMu.Lock()
+	n := len(throttle)
+	throttleMu.Unlock()
+	if n != 0 {
+		t.Errorf("throttle has %d entries, want 0", n)
+	}
+}
@@ -0,0 +1,165 @@
+// Package sessions derives player join/leave sessions from consecutive online reports of each server.
+package sessions
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"rust-legacy-site/database"
+	"rust-legacy-site/models"
+
+	"gorm.io/gorm"
+)
+
+// StaleAfter - если сервер не присылал отчёт дольше, открытые сессии закрываются временем последнего отчёта
+const StaleAfter = 5 * time.Minute
+
+var (
+	mu     sync.Mutex
+	online = make(map[string]map[string]bool) // serverType -> steamIDs из последнего отчёта
+)
+
+// openSessions returns steamIDs with open session on the server (из памяти, после рестарта — из БД)
+func openSessions(serverType string) map[string]bool {
+	if set, ok := online[serverType]; ok {
+		return set
+	}
+	var ids []string
+	database.DB.Model(&models.PlayerSession{}).
+		Where("server_type = ? AND ended_at IS NULL", serverType).
+		Pluck("steam_id", &ids)
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	online[serverType] = set
+	return set
+}
+
+// closeSessions ends open sessions of the players who left the server
+func closeSessions(serverType string, steamIDs []string, at time.Time) error {
+	return database.DB.Model(&models.PlayerSession{}).
+		Where("server_type = ? AND ended_at IS NULL AND steam_id IN ?", serverType, steamIDs).
+		Updates(map[string]interface{}{
+			"ended_at":     at,
+			"last_seen_at": at,
+			"duration":     gorm.Expr("GREATEST(EXTRACT(EPOCH FROM (?::timestamptz - started_at))::int, 0)", at),
+		}).Error
+}
+
+// diff returns players who left (open session, missing in the report) and who joined (reported, no open session)
+func diff(prev map[string]bool, players map[string]string) (left, joined []string) {
+	for id := range prev {
+		if _, ok := players[id]; !ok {
+			left = append(left, id)
+		}
+	}
+	for id := range players {
+		if !prev[id] {
+			joined = append(joined, id)
+		}
+	}
+	sort.Strings(left)
+	sort.Strings(joined)
+	return left, joined
+}
+
+// Observe diffs the reported player list (steamID -> username) with the previous report of the server:
+// new players open a session, missing ones close it.
+func Observe(serverType string, players map[string]string, at time.Time) {
+	if serverType == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	prev := openSessions(serverType)
+	left, joinedIDs := diff(prev, players)
+	if len(left) > 0 {
+		if err := closeSessions(serverType, left, at); err != nil {
+			log.Printf("[Sessions] %s: close failed: %v", serverType, err)
+			return
+		}
+	}
+	if len(prev) > len(left) {
+		database.DB.Model(&models.PlayerSession{}).
+			Where("server_type = ? AND ended_at IS NULL", serverType).
+			Update("last_seen_at", at)
+	}
+
+	var joined []models.PlayerSession
+	var serverID uint
+	for _, id := range joinedIDs {
+		if serverID == 0 {
+			var srv models.ServerInfo
+			if database.DB.Select("id").Where("type = ?", serverType).First(&srv).Error == nil {
+				serverID = srv.ID
+			}
+		}
+		joined = append(joined, models.PlayerSession{
+			ServerID:   serverID,
+			ServerType: serverType,
+			SteamID:    id,
+			Username:   players[id],
+			StartedAt:  at,
+			LastSeenAt: at,
+		})
+	}
+	if len(joined) > 0 {
+		if err := database.DB.CreateInBatches(&joined, 500).Error; err != nil {
+			log.Printf("[Sessions] %s: open failed: %v", serverType, err)
+			delete(online, serverType) // перечитаем из БД при следующем отчёте
+			return
+		}
+	}
+
+	set := make(map[string]bool, len(players))
+	for id := range players {
+		set[id] = true
+	}
+	online[serverType] = set
+}
+
+// CloseStale ends sessions of servers that stopped reporting (сервер упал или плагин выгружен).
+// End time is the last report where the player was seen.
+func CloseStale() {
+	mu.Lock()
+	defer mu.Unlock()
+	res := database.DB.Model(&models.PlayerSession{}).
+		Where("ended_at IS NULL AND last_seen_at < ?", time.Now().Add(-StaleAfter)).
+		Updates(map[string]interface{}{
+			"ended_at": gorm.Expr("last_seen_at"),
+			"duration": gorm.Expr("GREATEST(EXTRACT(EPOCH FROM (last_seen_at - started_at))::int, 0)"),
+		})
+	if res.Error != nil {
+		log.Printf("[Sessions] close stale: %v", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("[Sessions] closed %d stale sessions", res.RowsAffected)
+		online = make(map[string]map[string]bool)
+	}
+}
+
+// RetentionDays - PLAYER_SESSIONS_DAYS (default 365)
+func RetentionDays() int {
+	if d, err := strconv.Atoi(os.Getenv("PLAYER_SESSIONS_DAYS")); err == nil && d > 0 {
+		return d
+	}
+	return 365
+}
+
+// Prune deletes closed sessions older than RetentionDays
+func Prune() {
+	res := database.DB.Where("ended_at IS NOT NULL AND ended_at < ?", time.Now().AddDate(0, 0, -RetentionDays())).
+		Delete(&models.PlayerSession{})
+	if res.Error != nil {
+		log.Printf("[Sessions] prune: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("[Sessions] pruned %d old sessions", res.RowsAffected)
+	}
+}
@@ -0,0 +1,33 @@
+package sessions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev       map[string]bool
+		players    map[string]string
+		wantLeft   []string
+		wantJoined []string
+	}{
+		{"first report", map[string]bool{}, map[string]string{"b": "B", "a": "A"}, nil, []string{"a", "b"}},
+		{"same players", map[string]bool{"a": true}, map[string]string{"a": "A renamed"}, nil, nil},
+		{"one left one joined", map[string]bool{"a": true, "b": true}, map[string]string{"b": "B", "c": "C"}, []string{"a"}, []string{"c"}},
+		{"server empty", map[string]bool{"a": true, "b": true}, map[string]string{}, []string{"a", "b"}, nil},
+		{"nothing", nil, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, joined := diff(tt.prev, tt.players)
+			if !reflect.DeepEqual(left, tt.wantLeft) {
+				t.Errorf("left = %v, want %v", left, tt.wantLeft)
+			}
+			if !reflect.DeepEqual(joined, tt.wantJoined) {
+				t.Errorf("joined = %v, want %v", joined, tt.wantJoined)
+			}
+		})
+	}
+}
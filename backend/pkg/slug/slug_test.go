@@ -0,0 +1,61 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"cyrillic transliterated", "Вайп 19 июля!", "vayp-19-iyulya"},
+		{"latin lower-cased", "Big Update v2", "big-update-v2"},
+		{"separators collapse", "a  --  b__c", "a-b-c"},
+		{"edges trimmed", "  !Hello!  ", "hello"},
+		{"hard and soft signs dropped", "Объявление, подъезд", "obyavlenie-podezd"},
+		{"multi-letter transliteration", "Щука ЖЖ", "schuka-zhzh"},
+		{"non-latin letters dropped", "Ünïcode 日本", "n-code"},
+		{"empty", "", ""},
+		{"only punctuation", "!!! ???", ""},
+		{"truncated to 80", strings.Repeat("ab-", 40), strings.Repeat("ab-", 26) + "ab"},
+		{"no trailing dash after truncation", strings.Repeat("abc-", 30), strings.Repeat("abc-", 19) + "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.s)
+			if got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+			if got != "" && !Valid(got) {
+				t.Errorf("Make(%q) = %q is not Valid", tt.s, got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"vayp-19-iyulya", true},
+		{"a", true},
+		{"", false},
+		{"-a", false},
+		{"a-", false},
+		{"Upper", false},
+		{"with space", false},
+		{"кириллица", false},
+		{"a_b", false},
+		{strings.Repeat("a", 80), true},
+		{strings.Repeat("a", 81), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.s); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
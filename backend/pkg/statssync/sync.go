@@ -146,28 +146,22 @@ func Run() {
 		playerPayloads = append(playerPayloads, pp)
 	}
 
-	// Clans with members and aggregated stats
+	// Clans with members and aggregated stats (clan_stats пересчитывается при синхронизации)
 	var clans []models.Clan
 	database.DB.Preload("Members").Order("experience DESC").Find(&clans)
+	var clanStats []models.ClanStats
+	database.DB.Find(&clanStats)
+	statsByClan := make(map[uint]models.ClanStats, len(clanStats))
+	for _, cs := range clanStats {
+		statsByClan[cs.ClanID] = cs
+	}
 	clanPayloads := make([]ClanPayload, 0, len(clans))
 	for _, c := range clans {
 		memberIDs := make([]string, 0, len(c.Members))
 		for _, m := range c.Members {
 			memberIDs = append(memberIDs, m.SteamID)
 		}
-		// Aggregate kills, deaths, farm from members
-		var totalKills, totalDeaths, totalFarm int
-		for _, m := range c.Members {
-			var p models.Player
-			if database.DB.Where("steam_id = ?", m.SteamID).First(&p).Error == nil {
-				totalKills += p.KilledPlayers
-				totalDeaths += p.Deaths
-				var s models.PlayerStats
-				if database.DB.Where("steam_id = ?", m.SteamID).First(&s).Error == nil {
-					totalFarm += s.Wood + s.Metal + s.Sulfur
-				}
-			}
-		}
+		agg := statsByClan[c.ID]
 		clanPayloads = append(clanPayloads, ClanPayload{
 			ID:          c.ID,
 			HexID:       c.HexID,
@@ -177,9 +171,9 @@ func Run() {
 			Experience:  c.Experience,
 			MemberCount: c.MemberCount,
 			MemberIDs:   memberIDs,
-			TotalKills:  totalKills,
-			TotalDeaths: totalDeaths,
-			TotalFarm:   totalFarm,
+			TotalKills:  int(agg.TotalKills),
+			TotalDeaths: int(agg.TotalDeaths),
+			TotalFarm:   int(agg.TotalFarm),
 		})
 	}
 
@@ -212,6 +206,9 @@ func Run() {
 		return
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if key := os.Getenv("STATS_SYNC_API_KEY"); key != "" {
+		req.Header.Set("X-Api-Key", key) // GAME_API_KEY принимающей стороны
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -0,0 +1,28 @@
+package steamid
+
+import "strings"
+
+// prefix of individual-account SteamID64 (76561197960265728 and up)
+const prefix = "7656119"
+
+// Valid reports whether s is a SteamID64: 17 digits starting with 7656119
+func Valid(s string) bool {
+	if len(s) != 17 || !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize trims spaces; returns "" if the result is not a valid SteamID64
+func Normalize(s string) string {
+	s = strings.TrimSpace(s)
+	if !Valid(s) {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package steamid
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{"valid", "76561198000000001", true},
+		{"lowest individual account", "76561197960265728", true},
+		{"empty", "", false},
+		{"too short", "7656119800000000", false},
+		{"too long", "765611980000000011", false},
+		{"wrong prefix", "12345678901234567", false},
+		{"letters", "7656119800000000a", false},
+		{"spaces", " 7656119800000001", false},
+		{"SteamID2", "STEAM_0:1:12345", false},
+		{"sign", "+7656119800000001", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.s); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"76561198000000001", "76561198000000001"},
+		{"  76561198000000001\n", "76561198000000001"},
+		{"7656119800000000", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.s); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
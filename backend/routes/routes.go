@@ -21,11 +21,23 @@ func Setup(r *mux.Router) {
 
 	// Embed preview for Discord/VK etc - dynamic OG with online + download link
 	api.HandleFunc("/embed", handlers.EmbedPreview).Methods("GET")
+	api.HandleFunc("/embed/image.png", handlers.EmbedImage).Methods("GET")
+	api.HandleFunc("/embed/{serverType}", handlers.EmbedServerPreview).Methods("GET")
+	api.HandleFunc("/oembed", handlers.OEmbed).Methods("GET")
+
+	// Status widgets for third-party sites (badge, iframe, JSON/JSON-P)
+	api.HandleFunc("/widgets/status.svg", handlers.GetStatusBadge).Methods("GET")
+	api.HandleFunc("/widgets/status.html", handlers.GetStatusWidgetHTML).Methods("GET")
+	api.HandleFunc("/widgets/status.json", handlers.GetStatusWidgetJSON).Methods("GET")
+	api.Handle("/admin/embed/templates", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetEmbedTemplates))).Methods("GET")
+	api.Handle("/admin/embed/templates", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateEmbedTemplates))).Methods("PUT")
 
 	// Auth (public)
 	api.HandleFunc("/auth/login", handlers.Login).Methods("POST")
 	api.HandleFunc("/auth/register", handlers.Register).Methods("POST")
 	api.HandleFunc("/auth/me", handlers.AuthMe).Methods("GET")
+	api.Handle("/auth/steam/link", authpkg.UserMiddleware(http.HandlerFunc(handlers.StartSteamLink))).Methods("POST")
+	api.HandleFunc("/auth/steam/callback", handlers.SteamLinkCallback).Methods("GET")
 
 	// Company Info (GET public, PUT protected)
 	api.HandleFunc("/company-info", handlers.GetCompanyInfo).Methods("GET")
@@ -43,8 +55,9 @@ func Setup(r *mux.Router) {
 	api.HandleFunc("/server-status", handlers.GetServerStatus).Methods("GET")
 	api.HandleFunc("/server-status/classic", handlers.GetServerStatusClassic).Methods("GET")
 	api.HandleFunc("/server-status/deathmatch", handlers.GetServerStatusDeathmatch).Methods("GET")
-	api.HandleFunc("/server-status/report", handlers.ReportServerOnline).Methods("POST")
+	api.Handle("/server-status/report", authpkg.LegacyGameServerMiddleware(http.HandlerFunc(handlers.ReportServerOnline))).Methods("POST") // сессии и история онлайна — только от плагина
 	api.HandleFunc("/server-status/history", handlers.GetOnlineHistory).Methods("GET")
+	api.HandleFunc("/server-status/activity", handlers.GetServerActivity).Methods("GET")
 
 	// Features
 	api.HandleFunc("/features", handlers.GetFeatures).Methods("GET")
@@ -55,6 +68,8 @@ func Setup(r *mux.Router) {
 	// News
 	api.HandleFunc("/news", handlers.GetNews).Methods("GET")
 	api.Handle("/news", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateNews))).Methods("POST")
+	api.HandleFunc("/news/feed.xml", handlers.GetNewsFeed).Methods("GET")
+	api.HandleFunc("/news/by-slug/{slug}", handlers.GetNewsBySlug).Methods("GET")
 	api.HandleFunc("/news/{id}", handlers.GetNewsItem).Methods("GET")
 	api.Handle("/news/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateNews))).Methods("PUT")
 	api.Handle("/news/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteNews))).Methods("DELETE")
@@ -99,13 +114,62 @@ func Setup(r *mux.Router) {
 	api.Handle("/legal-documents", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateLegalDocument))).Methods("POST")
 	api.Handle("/legal-documents/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateLegalDocument))).Methods("PUT")
 	api.Handle("/legal-documents/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteLegalDocument))).Methods("DELETE")
+	api.HandleFunc("/legal-documents/revisions/{id}", handlers.GetLegalDocumentRevision).Methods("GET")
+
+	// Content revisions (legal documents, rules) — история, diff, откат
+	api.Handle("/admin/revisions", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRevisions))).Methods("GET")
+	api.Handle("/admin/revisions/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRevision))).Methods("GET")
+	api.Handle("/admin/revisions/{id}/diff", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRevisionDiff))).Methods("GET")
+	api.Handle("/admin/revisions/{id}/rollback", authpkg.AdminMiddleware(http.HandlerFunc(handlers.RollbackRevision))).Methods("POST")
 
 	// Players
 	api.HandleFunc("/players", handlers.GetPlayers).Methods("GET")
+	api.HandleFunc("/players/search", handlers.SearchPlayers).Methods("GET")
 	api.HandleFunc("/players/{steamid}", handlers.GetPlayer).Methods("GET")
-
-	// Stats sync (receive from TopSystem plugin). GET — проверка, POST — приём данных
-	api.HandleFunc("/stats/sync", handlers.ReceiveStatsSync).Methods("GET", "POST")
+	api.HandleFunc("/players/{steamid}/names", handlers.GetPlayerNames).Methods("GET")
+	api.HandleFunc("/players/{steamid}/history", handlers.GetPlayerHistory).Methods("GET")
+	api.HandleFunc("/players/{steamid}/achievements", handlers.GetPlayerAchievements).Methods("GET")
+	api.HandleFunc("/players/{steamid}/kills", handlers.GetPlayerKills).Methods("GET")
+	api.HandleFunc("/players/{steamid}/sessions", handlers.GetPlayerSessions).Methods("GET")
+	api.HandleFunc("/leaderboards/{metric}", handlers.GetLeaderboard).Methods("GET")
+
+	// Bans: публичный список и управление в админке (RCON на всех серверах)
+	api.HandleFunc("/bans", handlers.GetBans).Methods("GET")
+	api.HandleFunc("/bans/{steamid}", handlers.GetPlayerBan).Methods("GET")
+	api.Handle("/admin/bans", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetAdminBans))).Methods("GET")
+	api.Handle("/admin/bans", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateBan))).Methods("POST")
+	api.Handle("/admin/bans/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateBan))).Methods("PUT")
+	api.Handle("/admin/bans/{id}/unban", authpkg.AdminMiddleware(http.HandlerFunc(handlers.Unban))).Methods("POST")
+	api.Handle("/admin/bans/{id}/resync", authpkg.AdminMiddleware(http.HandlerFunc(handlers.ResyncBan))).Methods("POST")
+
+	// Tickets: жалобы на игроков и апелляции банов (пользователь), очередь персонала (админ)
+	api.Handle("/tickets", authpkg.UserMiddleware(http.HandlerFunc(handlers.CreateTicket))).Methods("POST")
+	api.Handle("/tickets", authpkg.UserMiddleware(http.HandlerFunc(handlers.GetMyTickets))).Methods("GET")
+	api.Handle("/tickets/{id}", authpkg.UserMiddleware(http.HandlerFunc(handlers.GetMyTicket))).Methods("GET")
+	api.Handle("/tickets/{id}/messages", authpkg.UserMiddleware(http.HandlerFunc(handlers.ReplyMyTicket))).Methods("POST")
+	api.Handle("/admin/tickets", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetAdminTickets))).Methods("GET")
+	api.Handle("/admin/tickets/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetAdminTicket))).Methods("GET")
+	api.Handle("/admin/tickets/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateTicket))).Methods("PUT")
+	api.Handle("/admin/tickets/{id}/messages", authpkg.AdminMiddleware(http.HandlerFunc(handlers.ReplyTicket))).Methods("POST")
+
+	// Kill feed (события от плагина по GAME_API_KEY)
+	api.Handle("/events/kills", authpkg.GameServerMiddleware(http.HandlerFunc(handlers.ReceiveKillEvents))).Methods("POST")
+	api.HandleFunc("/kills/feed", handlers.GetKillFeed).Methods("GET")
+	api.HandleFunc("/kills/weapons", handlers.GetWeaponStats).Methods("GET")
+
+	// Achievements
+	api.HandleFunc("/achievements", handlers.GetAchievements).Methods("GET")
+	api.Handle("/achievements", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateAchievement))).Methods("POST")
+	api.Handle("/achievements/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateAchievement))).Methods("PUT")
+	api.Handle("/achievements/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteAchievement))).Methods("DELETE")
+	api.Handle("/admin/achievements/evaluate", authpkg.AdminMiddleware(http.HandlerFunc(handlers.EvaluateAchievementsNow))).Methods("POST")
+	api.Handle("/admin/achievements/rewards/review", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetAchievementRewardsForReview))).Methods("GET")
+	api.Handle("/admin/achievements/{id}/rewards/{steamid}/review", authpkg.AdminMiddleware(http.HandlerFunc(handlers.ReviewAchievementReward))).Methods("POST")
+
+	// Stats sync (receive from TopSystem plugin). GET — проверка, POST — приём данных (по GAME_API_KEY:
+	// после синхронизации выдаются награды за достижения)
+	api.HandleFunc("/stats/sync", handlers.ReceiveStatsSync).Methods("GET")
+	api.Handle("/stats/sync", authpkg.LegacyGameServerMiddleware(http.HandlerFunc(handlers.ReceiveStatsSync))).Methods("POST")
 
 	// Import (sync from game server files)
 	api.HandleFunc("/import/clans", handlers.ImportClans).Methods("POST")
@@ -116,6 +180,7 @@ func Setup(r *mux.Router) {
 	api.HandleFunc("/clans", handlers.GetClans).Methods("GET")
 	api.HandleFunc("/clans", handlers.CreateClan).Methods("POST")
 	api.HandleFunc("/clans/{id}", handlers.GetClan).Methods("GET")
+	api.HandleFunc("/clans/{id}/history", handlers.GetClanHistory).Methods("GET")
 	api.HandleFunc("/clans/{id}", handlers.UpdateClan).Methods("PUT")
 	api.HandleFunc("/clans/{id}", handlers.DeleteClan).Methods("DELETE")
 
@@ -143,9 +208,32 @@ func Setup(r *mux.Router) {
 	api.Handle("/download-links", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateDownloadLink))).Methods("POST")
 	api.Handle("/download-links/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateDownloadLink))).Methods("PUT")
 	api.Handle("/download-links/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteDownloadLink))).Methods("DELETE")
+	api.Handle("/admin/download-links/health", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetMirrorHealth))).Methods("GET")
+	api.Handle("/admin/download-links/check", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CheckMirrorsNow))).Methods("POST")
+	// Tracked download: /api/download/{linkId} или /api/download/auto?serverId= (балансировка по весам)
+	api.HandleFunc("/download/{linkId}", handlers.DownloadRedirect).Methods("GET")
+	api.Handle("/admin/downloads/stats", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetDownloadStats))).Methods("GET")
 
 	// RCON (protected)
 	api.Handle("/rcon/execute", authpkg.AdminMiddleware(http.HandlerFunc(handlers.ExecuteRcon))).Methods("POST")
+	api.Handle("/admin/rcon/metrics", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRconMetrics))).Methods("GET")
+	// WebSocket console: одноразовый тикет (POST .../ticket) в ?ticket=, проверяется в хендлере
+	api.Handle("/admin/rcon/console/ticket", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateRconConsoleTicket))).Methods("POST")
+	api.HandleFunc("/admin/rcon/console", handlers.RconConsole).Methods("GET")
+
+	// RCON allowlist for shop command templates + dry-run preview of order delivery
+	api.Handle("/admin/rcon/allowlist", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRconAllowlist))).Methods("GET")
+	api.Handle("/admin/rcon/allowlist", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateRconAllowedCommand))).Methods("POST")
+	api.Handle("/admin/rcon/allowlist/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteRconAllowedCommand))).Methods("DELETE")
+	api.Handle("/admin/orders/rcon-review", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetOrdersNeedingReview))).Methods("GET")
+	api.Handle("/admin/orders/{id}/rcon-preview", authpkg.AdminMiddleware(http.HandlerFunc(handlers.PreviewOrderCommands))).Methods("GET")
+	api.Handle("/admin/orders/{id}/rcon-review", authpkg.AdminMiddleware(http.HandlerFunc(handlers.ReviewOrderDelivery))).Methods("POST")
+
+	// i18n: content groups missing some language version
+	api.Handle("/admin/translations/missing", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetMissingTranslations))).Methods("GET")
+
+	// Security events: rejected SteamIDs / RCON argument injection attempts
+	api.Handle("/admin/security/events", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetSecurityEvents))).Methods("GET")
 
 	// Site Config (GET public, PUT protected)
 	api.HandleFunc("/site-config", handlers.GetSiteConfig).Methods("GET")
@@ -165,4 +253,20 @@ func Setup(r *mux.Router) {
 	api.Handle("/admin/clans/delete-by-name", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteClanByName))).Methods("DELETE", "POST")
 	api.Handle("/admin/social", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetSocialConfig))).Methods("GET")
 	api.Handle("/admin/social", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateSocialConfig))).Methods("PUT")
+
+	// Scheduled RCON jobs (cron)
+	api.Handle("/admin/jobs", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRconJobs))).Methods("GET")
+	api.Handle("/admin/jobs", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CreateRconJob))).Methods("POST")
+	api.Handle("/admin/jobs/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRconJob))).Methods("GET")
+	api.Handle("/admin/jobs/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UpdateRconJob))).Methods("PUT")
+	api.Handle("/admin/jobs/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteRconJob))).Methods("DELETE")
+	api.Handle("/admin/jobs/{id}/run", authpkg.AdminMiddleware(http.HandlerFunc(handlers.RunRconJobNow))).Methods("POST")
+	api.Handle("/admin/jobs/{id}/runs", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetRconJobRuns))).Methods("GET")
+
+	// Media library (uploads); local storage files are served by backend
+	api.Handle("/admin/media", authpkg.AdminMiddleware(http.HandlerFunc(handlers.GetMedia))).Methods("GET")
+	api.Handle("/admin/media", authpkg.AdminMiddleware(http.HandlerFunc(handlers.UploadMedia))).Methods("POST")
+	api.Handle("/admin/media/gc", authpkg.AdminMiddleware(http.HandlerFunc(handlers.CollectMediaGarbage))).Methods("POST")
+	api.Handle("/admin/media/{id}", authpkg.AdminMiddleware(http.HandlerFunc(handlers.DeleteMedia))).Methods("DELETE")
+	api.PathPrefix("/media/files/").HandlerFunc(handlers.ServeMediaFile).Methods("GET", "HEAD")
 }
@@ -18,6 +18,7 @@ import LegalDocument from './pages/LegalDocument';
 import Company from './pages/Company';
 import Admin from './pages/Admin';
 import Balance from './pages/Balance';
+import Tickets from './pages/Tickets';
 import NotFound from './pages/NotFound';
 import './App.css';
 
@@ -53,6 +54,8 @@ function AppContent() {
         <Route path="/legal/:type" element={<LegalDocument />} />
         <Route path="/company" element={<Company />} />
         <Route path="/balance" element={<Balance />} />
+        <Route path="/tickets" element={<Tickets />} />
+        <Route path="/tickets/:id" element={<Tickets />} />
         <Route path="/admin/*" element={<Admin />} />
         <Route path="*" element={<NotFound />} />
       </Routes>
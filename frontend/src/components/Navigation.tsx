@@ -1,6 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Link, useLocation } from 'react-router-dom';
-import { Menu, X, Download, Wallet, LogOut } from 'lucide-react';
+import { Menu, X, Download, Wallet, LogOut, LifeBuoy } from 'lucide-react';
 import { useTranslation } from 'react-i18next';
 import { motion, AnimatePresence } from 'framer-motion';
 import { apiService } from '../services/api';
@@ -15,8 +15,7 @@ const Navigation: React.FC = () => {
 
   useEffect(() => {
     apiService.getServerInfo().then((info) => {
-      const url = info?.downloadLinks?.[0]?.url || info?.downloadUrl;
-      if (url) setDownloadUrl(url);
+      if (info?.downloadLinks?.length || info?.downloadUrl) setDownloadUrl(apiService.downloadUrl('auto', info.id));
     }).catch(() => {});
   }, []);
 
@@ -76,6 +75,9 @@ const Navigation: React.FC = () => {
                       <Wallet size={18} />
                       {user.balance.toFixed(0)} ₽
                     </Link>
+                    <Link to="/tickets" className="nav-link" style={{ display: 'flex', alignItems: 'center' }} title={i18n.language === 'ru' ? 'Жалобы и апелляции' : 'Reports & appeals'}>
+                      <LifeBuoy size={18} />
+                    </Link>
                     <button onClick={logout} className="lang-btn" style={{ display: 'flex', alignItems: 'center', gap: 6 }} title={i18n.language === 'ru' ? 'Выйти' : 'Logout'}>
                       <LogOut size={18} />
                     </button>
@@ -143,6 +145,10 @@ const Navigation: React.FC = () => {
                         <Wallet size={18} style={{ verticalAlign: 'middle', marginRight: 6 }} />
                         {i18n.language === 'ru' ? 'Баланс' : 'Balance'}: {user.balance.toFixed(0)} ₽
                       </Link>
+                      <Link to="/tickets" className="mobile-nav-link" onClick={() => setMobileMenuOpen(false)}>
+                        <LifeBuoy size={18} style={{ verticalAlign: 'middle', marginRight: 6 }} />
+                        {i18n.language === 'ru' ? 'Жалобы и апелляции' : 'Reports & appeals'}
+                      </Link>
                       <button className="mobile-nav-link" onClick={() => { logout(); setMobileMenuOpen(false); }} style={{ width: '100%', textAlign: 'left', background: 'none', border: 'none', cursor: 'pointer' }}>
                         <LogOut size={18} style={{ verticalAlign: 'middle', marginRight: 6 }} />
                         {i18n.language === 'ru' ? 'Выйти' : 'Logout'}
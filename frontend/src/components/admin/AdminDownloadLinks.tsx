@@ -7,6 +7,7 @@ export default function AdminDownloadLinks({ onMessage }: { onMessage: (t: strin
   const [loading, setLoading] = useState(true);
   const [newLabel, setNewLabel] = useState('');
   const [newUrl, setNewUrl] = useState('');
+  const [newWeight, setNewWeight] = useState(1);
   const [editing, setEditing] = useState<Types.DownloadLink | null>(null);
 
   const load = () => apiService.getDownloadLinks().then(setLinks).catch(() => onMessage('Failed to load', 'error'));
@@ -24,9 +25,11 @@ export default function AdminDownloadLinks({ onMessage }: { onMessage: (t: strin
         label: newLabel,
         url: newUrl,
         order: links.length,
+        weight: newWeight,
       });
       setNewLabel('');
       setNewUrl('');
+      setNewWeight(1);
       onMessage('Added', 'success');
       load();
     } catch {
@@ -38,10 +41,11 @@ export default function AdminDownloadLinks({ onMessage }: { onMessage: (t: strin
     e.preventDefault();
     if (!editing) return;
     try {
-      await apiService.updateDownloadLink(editing.id, { label: newLabel, url: newUrl });
+      await apiService.updateDownloadLink(editing.id, { label: newLabel, url: newUrl, weight: newWeight });
       setEditing(null);
       setNewLabel('');
       setNewUrl('');
+      setNewWeight(1);
       onMessage('Updated', 'success');
       load();
     } catch {
@@ -64,6 +68,7 @@ export default function AdminDownloadLinks({ onMessage }: { onMessage: (t: strin
     setEditing(l);
     setNewLabel(l.label);
     setNewUrl(l.url);
+    setNewWeight(l.weight ?? 1);
   };
 
   if (loading) return <p>Loading...</p>;
@@ -73,15 +78,17 @@ export default function AdminDownloadLinks({ onMessage }: { onMessage: (t: strin
       <form className="admin-form" onSubmit={editing ? update : add} style={{ marginBottom: '1.5rem', padding: '1rem', background: 'var(--bg-darker)', borderRadius: 8 }}>
         <label>Label <input placeholder="e.g. Google Drive" value={newLabel} onChange={e => setNewLabel(e.target.value)} required /></label>
         <label>URL <input placeholder="https://..." type="url" value={newUrl} onChange={e => setNewUrl(e.target.value)} required /></label>
+        <label title="Share of /api/download traffic; 0 = only when chosen explicitly">Weight <input type="number" min={0} value={newWeight} onChange={e => setNewWeight(Math.max(0, parseInt(e.target.value, 10) || 0))} /></label>
         <div style={{ display: 'flex', gap: '0.5rem' }}>
           <button type="submit" className="btn">{editing ? 'Update' : 'Add'}</button>
-          {editing && <button type="button" className="btn btn-secondary" onClick={() => { setEditing(null); setNewLabel(''); setNewUrl(''); }}>Cancel</button>}
+          {editing && <button type="button" className="btn btn-secondary" onClick={() => { setEditing(null); setNewLabel(''); setNewUrl(''); setNewWeight(1); }}>Cancel</button>}
         </div>
       </form>
       <div style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem' }}>
         {links.map(l => (
           <div key={l.id} style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '0.75rem', background: 'var(--bg-darker)', borderRadius: 8 }}>
             <a href={l.url} target="_blank" rel="noopener noreferrer" style={{ color: 'var(--primary-blue)' }}>{l.label}</a>
+            <span style={{ color: 'var(--text-secondary)', fontSize: '0.85rem' }}>weight {l.weight ?? 1}</span>
             <div style={{ display: 'flex', gap: '0.5rem' }}>
               <button className="btn btn-secondary" style={{ padding: '0.5rem 1rem' }} onClick={() => edit(l)}>Edit</button>
               <button className="btn btn-secondary" style={{ padding: '0.5rem 1rem', background: '#ef4444', borderColor: '#ef4444' }} onClick={() => remove(l.id)}>Delete</button>
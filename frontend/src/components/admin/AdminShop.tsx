@@ -3,7 +3,7 @@ import { apiService } from '../../services/api';
 import * as Types from '../../types';
 
 const catEmpty: { name: string; language: 'en' | 'ru'; order: number; enabled: boolean } = { name: '', language: 'en', order: 1, enabled: true };
-const itemEmpty: { categoryId: number; language: 'en' | 'ru'; name: string; description: string; price: number; currency: string; imageUrl: string; features: string; enabled: boolean; order: number; rconCommand: string; warranty: string; specs: string; packageContents: string } = { categoryId: 1, language: 'en', name: '', description: '', price: 0, currency: 'USD', imageUrl: '', features: '[]', enabled: true, order: 1, rconCommand: '', warranty: '', specs: '', packageContents: '' };
+const itemEmpty: { categoryId: number; language: 'en' | 'ru'; name: string; description: string; price: number; currency: string; imageUrl: string; features: string; enabled: boolean; order: number; rconCommand: string; maxQuantity: number; warranty: string; specs: string; packageContents: string } = { categoryId: 1, language: 'en', name: '', description: '', price: 0, currency: 'USD', imageUrl: '', features: '[]', enabled: true, order: 1, rconCommand: '', maxQuantity: 1, warranty: '', specs: '', packageContents: '' };
 
 export default function AdminShop({ onMessage }: { onMessage: (t: string, type: 'success' | 'error') => void }) {
   const [categories, setCategories] = useState<Types.ShopCategory[]>([]);
@@ -154,7 +154,8 @@ export default function AdminShop({ onMessage }: { onMessage: (t: string, type:
             <label>Order <input type="number" value={itemForm.order} onChange={e => setItemForm({ ...itemForm, order: +e.target.value })} /></label>
           </div>
           <label>Image URL <input value={itemForm.imageUrl} onChange={e => setItemForm({ ...itemForm, imageUrl: e.target.value })} /></label>
-          <label>RCON Command (use * for SteamID) <input placeholder="give * wood 1000" value={itemForm.rconCommand} onChange={e => setItemForm({ ...itemForm, rconCommand: e.target.value })} /></label>
+          <label>RCON Commands (one per line; {'{{steamid}}'}, {'{{username}}'}, {'{{quantity}}'}, {'{{order_id}}'}) <textarea rows={3} placeholder="inv.giveplayer {{steamid}} wood 1000" value={itemForm.rconCommand} onChange={e => setItemForm({ ...itemForm, rconCommand: e.target.value })} /></label>
+          <label>Max quantity per order (&gt;1 requires {'{{quantity}}'} in the command) <input type="number" min={1} max={100} value={itemForm.maxQuantity} onChange={e => setItemForm({ ...itemForm, maxQuantity: +e.target.value })} /></label>
           <label>Warranty (Гарантия) <textarea value={itemForm.warranty} onChange={e => setItemForm({ ...itemForm, warranty: e.target.value })} rows={2} placeholder="e.g. Instant delivery, no refund for digital goods" /></label>
           <label>Specs (Характеристики) <textarea value={itemForm.specs} onChange={e => setItemForm({ ...itemForm, specs: e.target.value })} rows={2} /></label>
           <label>Package contents (Комплектация) <textarea value={itemForm.packageContents} onChange={e => setItemForm({ ...itemForm, packageContents: e.target.value })} rows={2} /></label>
@@ -169,7 +170,7 @@ export default function AdminShop({ onMessage }: { onMessage: (t: string, type:
             <div key={i.id} style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '0.75rem', background: 'var(--bg-darker)', borderRadius: 8 }}>
               <div><strong>{i.name}</strong> — {i.price} {i.currency} {i.rconCommand && `[RCON]`}</div>
               <div style={{ display: 'flex', gap: '0.5rem' }}>
-                <button className="btn btn-secondary" style={{ padding: '0.5rem 1rem' }} onClick={() => { setEditingItem(i); setItemForm({ ...itemEmpty, categoryId: i.categoryId, language: i.language as 'en' | 'ru', name: i.name, description: i.description || '', price: i.price, currency: i.currency, imageUrl: i.imageUrl || '', features: typeof i.features === 'string' ? i.features : JSON.stringify(i.features || []), enabled: i.enabled, order: i.order, rconCommand: i.rconCommand || '', warranty: i.warranty || '', specs: i.specs || '', packageContents: i.packageContents || '' }); }}>Edit</button>
+                <button className="btn btn-secondary" style={{ padding: '0.5rem 1rem' }} onClick={() => { setEditingItem(i); setItemForm({ ...itemEmpty, categoryId: i.categoryId, language: i.language as 'en' | 'ru', name: i.name, description: i.description || '', price: i.price, currency: i.currency, imageUrl: i.imageUrl || '', features: typeof i.features === 'string' ? i.features : JSON.stringify(i.features || []), enabled: i.enabled, order: i.order, rconCommand: i.rconCommand || '', maxQuantity: i.maxQuantity || 1, warranty: i.warranty || '', specs: i.specs || '', packageContents: i.packageContents || '' }); }}>Edit</button>
                 <button className="btn btn-secondary" style={{ padding: '0.5rem 1rem', background: '#ef4444', borderColor: '#ef4444' }} onClick={() => deleteItem(i.id)}>Delete</button>
               </div>
             </div>
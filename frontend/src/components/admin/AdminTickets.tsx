@@ -0,0 +1,132 @@
+import React, { useState, useEffect } from 'react';
+import { Link, useParams } from 'react-router-dom';
+import { apiService } from '../../services/api';
+import * as Types from '../../types';
+
+const STATUSES: Types.TicketStatus[] = ['open', 'in_progress', 'waiting', 'resolved', 'closed'];
+
+function TicketDetail({ id, onMessage }: { id: number; onMessage: (t: string, type: 'success' | 'error') => void }) {
+  const [ticket, setTicket] = useState<Types.Ticket | null>(null);
+  const [reply, setReply] = useState({ message: '', internal: false, status: '' as Types.TicketStatus | '' });
+
+  const load = () => apiService.getAdminTicket(id).then(setTicket).catch(() => onMessage('Ticket not found', 'error'));
+  useEffect(() => { load(); }, [id]);
+
+  const setStatus = async (status: Types.TicketStatus) => {
+    try {
+      await apiService.updateTicket(id, { status });
+      load();
+    } catch {
+      onMessage('Failed to update ticket', 'error');
+    }
+  };
+
+  const send = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!reply.message.trim()) return;
+    try {
+      await apiService.replyTicket(id, { message: reply.message, internal: reply.internal, status: reply.status || undefined });
+      setReply({ message: '', internal: false, status: '' });
+      load();
+    } catch {
+      onMessage('Failed to send reply', 'error');
+    }
+  };
+
+  if (!ticket) return null;
+  const ban = ticket.ban || ticket.activeBan;
+
+  return (
+    <div className="card" style={{ padding: '1.5rem' }}>
+      <p><Link to="/admin/tickets" style={{ color: 'var(--primary-blue)' }}>← All tickets</Link></p>
+      <h3>#{ticket.id} [{ticket.type}{ticket.category ? ` / ${ticket.category}` : ''}] {ticket.subject}</h3>
+      <p style={{ color: 'var(--text-secondary)' }}>
+        From {ticket.userLogin}{ticket.targetSteamId && <> · target <Link to={`/statistics?player=${ticket.targetSteamId}`}>{ticket.targetSteamId}</Link></>}
+        {ticket.assigneeName && <> · assigned to {ticket.assigneeName}</>}
+      </p>
+      {ban && <p style={{ color: 'var(--text-secondary)' }}>Ban #{ban.id}: {ban.reason} ({ban.active ? 'active' : 'inactive'}{ban.expiresAt ? `, until ${new Date(ban.expiresAt).toLocaleString()}` : ''})</p>}
+      {ticket.evidence && ticket.evidence.length > 0 && (
+        <ul>{ticket.evidence.map((l) => <li key={l}><a href={l} target="_blank" rel="noopener noreferrer">{l}</a></li>)}</ul>
+      )}
+      <div style={{ display: 'flex', gap: '0.5rem', flexWrap: 'wrap', margin: '1rem 0' }}>
+        {STATUSES.map((s) => (
+          <button key={s} type="button" className={ticket.status === s ? 'btn' : 'btn btn-secondary'} style={{ padding: '0.4rem 0.8rem' }} onClick={() => setStatus(s)}>{s}</button>
+        ))}
+      </div>
+      <div style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem', marginBottom: '1rem' }}>
+        {(ticket.messages || []).map((m) => (
+          <div key={m.id} style={{ padding: '0.75rem', background: m.internal ? '#44403c' : 'var(--bg-darker)', borderRadius: 8 }}>
+            <div style={{ fontSize: '0.85rem', color: 'var(--text-secondary)' }}>
+              {m.authorName} ({m.authorType}{m.internal ? ', internal note' : ''}) · {new Date(m.createdAt).toLocaleString()}
+            </div>
+            <div style={{ whiteSpace: 'pre-wrap' }}>{m.body}</div>
+          </div>
+        ))}
+      </div>
+      <form onSubmit={send} style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem' }}>
+        <textarea rows={4} value={reply.message} onChange={(e) => setReply({ ...reply, message: e.target.value })} placeholder="Reply" />
+        <div style={{ display: 'flex', gap: '1rem', alignItems: 'center', flexWrap: 'wrap' }}>
+          <label><input type="checkbox" checked={reply.internal} onChange={(e) => setReply({ ...reply, internal: e.target.checked })} /> Internal note</label>
+          <label>Set status <select value={reply.status} onChange={(e) => setReply({ ...reply, status: e.target.value as Types.TicketStatus | '' })}>
+            <option value="">auto</option>
+            {STATUSES.map((s) => <option key={s} value={s}>{s}</option>)}
+          </select></label>
+          <button type="submit" className="btn">Send</button>
+        </div>
+      </form>
+    </div>
+  );
+}
+
+export default function AdminTickets({ onMessage }: { onMessage: (t: string, type: 'success' | 'error') => void }) {
+  const { id } = useParams();
+  const [tickets, setTickets] = useState<Types.Ticket[]>([]);
+  const [filter, setFilter] = useState({ status: 'open,in_progress,waiting', type: '' as Types.TicketType | '', assignee: '' as '' | 'me' | 'none', q: '' });
+
+  useEffect(() => {
+    if (id) return;
+    apiService.getAdminTickets({
+      status: filter.status ? (filter.status.split(',') as Types.TicketStatus[]) : undefined,
+      type: filter.type || undefined,
+      assignee: filter.assignee || undefined,
+      q: filter.q || undefined,
+    }).then(setTickets).catch(() => onMessage('Failed to load tickets', 'error'));
+  }, [id, filter]);
+
+  if (id) return <TicketDetail id={+id} onMessage={onMessage} />;
+
+  return (
+    <div className="card" style={{ padding: '1.5rem' }}>
+      <h3>Tickets</h3>
+      <div style={{ display: 'flex', gap: '0.5rem', flexWrap: 'wrap', marginBottom: '1rem' }}>
+        <select value={filter.status} onChange={(e) => setFilter({ ...filter, status: e.target.value })}>
+          <option value="open,in_progress,waiting">Active</option>
+          <option value="resolved,closed">Resolved / closed</option>
+          <option value="">All</option>
+        </select>
+        <select value={filter.type} onChange={(e) => setFilter({ ...filter, type: e.target.value as Types.TicketType | '' })}>
+          <option value="">All types</option>
+          <option value="report">Reports</option>
+          <option value="appeal">Appeals</option>
+        </select>
+        <select value={filter.assignee} onChange={(e) => setFilter({ ...filter, assignee: e.target.value as '' | 'me' | 'none' })}>
+          <option value="">Any assignee</option>
+          <option value="me">Mine</option>
+          <option value="none">Unassigned</option>
+        </select>
+        <input value={filter.q} onChange={(e) => setFilter({ ...filter, q: e.target.value })} placeholder="SteamID, login or subject" />
+      </div>
+      <div style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem' }}>
+        {tickets.length === 0 && <p style={{ color: 'var(--text-secondary)' }}>No tickets</p>}
+        {tickets.map((t) => (
+          <Link key={t.id} to={`/admin/tickets/${t.id}`} style={{ display: 'flex', justifyContent: 'space-between', padding: '0.75rem', background: 'var(--bg-darker)', borderRadius: 8, color: 'var(--text-primary)', textDecoration: 'none' }}>
+            <span>#{t.id} [{t.type}] {t.subject} — {t.userLogin}</span>
+            <span style={{ color: t.lastReplyBy === 'user' ? 'var(--primary-blue)' : 'var(--text-secondary)' }}>
+              {t.status}{t.assigneeName ? ` · ${t.assigneeName}` : ''}
+            </span>
+          </Link>
+        ))}
+      </div>
+    </div>
+  );
+}
@@ -3,7 +3,7 @@ import { Routes, Route, NavLink, useNavigate, Navigate } from 'react-router-dom'
 import { motion } from 'framer-motion';
 import {
   Settings, ShoppingCart, Download, FileText, CreditCard,
-  Shield, Plug, List, LogOut, Zap, Building2, Server, Database, MessageCircle, Globe
+  Shield, Plug, List, LogOut, Zap, Building2, Server, Database, MessageCircle, Globe, LifeBuoy
 } from 'lucide-react';
 import { useTranslation } from 'react-i18next';
 import { useApp } from '../context/AppContext';
@@ -24,6 +24,7 @@ import AdminNews from '../components/admin/AdminNews';
 import AdminCompanyInfo from '../components/admin/AdminCompanyInfo';
 import AdminSocial from '../components/admin/AdminSocial';
 import AdminSiteConfig from '../components/admin/AdminSiteConfig';
+import AdminTickets from '../components/admin/AdminTickets';
 
 const Admin: React.FC = () => {
   const { t, i18n } = useTranslation();
@@ -76,6 +77,7 @@ const Admin: React.FC = () => {
     { path: 'legal', label: 'Legal Docs', icon: FileText },
     { path: 'company', label: 'Company Info', icon: Building2 },
     { path: 'social', label: 'Discord & VK', icon: MessageCircle },
+    { path: 'tickets', label: 'Tickets', icon: LifeBuoy },
   ];
 
   if (checking && !isAdmin) {
@@ -201,6 +203,8 @@ const Admin: React.FC = () => {
           <Route path="legal" element={<AdminLegal onMessage={showMessage} />} />
           <Route path="company" element={<AdminCompanyInfo onMessage={showMessage} />} />
           <Route path="social" element={<AdminSocial onMessage={showMessage} />} />
+          <Route path="tickets" element={<AdminTickets onMessage={showMessage} />} />
+          <Route path="tickets/:id" element={<AdminTickets onMessage={showMessage} />} />
         </Routes>
       </motion.div>
     </div>
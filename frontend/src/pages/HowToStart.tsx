@@ -60,7 +60,7 @@ const HowToStart: React.FC = () => {
               serverInfo.downloadLinks.map((link) => (
                 <a
                   key={link.id}
-                  href={link.url}
+                  href={apiService.downloadUrl(link.id)}
                   target="_blank"
                   rel="noopener noreferrer"
                   className="howto-download-btn"
@@ -71,7 +71,7 @@ const HowToStart: React.FC = () => {
               ))
             ) : serverInfo?.downloadUrl ? (
               <a
-                href={serverInfo.downloadUrl}
+                href={apiService.downloadUrl('auto', serverInfo.id)}
                 target="_blank"
                 rel="noopener noreferrer"
                 className="howto-download-btn"
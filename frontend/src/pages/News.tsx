@@ -1,4 +1,5 @@
 import React, { useState, useEffect } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
 import { motion } from 'framer-motion';
 import { FileText } from 'lucide-react';
 import { useTranslation } from 'react-i18next';
@@ -7,15 +8,23 @@ import * as Types from '../types';
 
 const News: React.FC = () => {
   const { t, i18n } = useTranslation();
+  const [searchParams] = useSearchParams();
+  // ?slug= — ссылка на одну новость (RSS/Atom, Discord, webhook)
+  const slug = searchParams.get('slug');
   const [items, setItems] = useState<Types.News[]>([]);
   const [loading, setLoading] = useState(true);
 
   useEffect(() => {
-    apiService.getNews(i18n.language, true)
-      .then(n => setItems((n || []).sort((a, b) => new Date(b.publishedAt).getTime() - new Date(a.publishedAt).getTime())))
+    setLoading(true);
+    const load = slug
+      ? apiService.getNewsBySlug(slug).then(n => [n])
+      : apiService.getNews(i18n.language, true)
+        .then(n => (n || []).sort((a, b) => new Date(b.publishedAt).getTime() - new Date(a.publishedAt).getTime()));
+    load
+      .then(setItems)
       .catch(() => setItems([]))
       .finally(() => setLoading(false));
-  }, [i18n.language]);
+  }, [i18n.language, slug]);
 
   if (loading) {
     return (
@@ -34,6 +43,11 @@ const News: React.FC = () => {
           <FileText size={48} color="var(--primary-blue)" style={{ filter: 'drop-shadow(0 0 20px var(--glow-blue))' }} />
           <h1 className="section-title" style={{ marginBottom: 0 }}>{t('news.title')}</h1>
         </div>
+        {slug && (
+          <p style={{ maxWidth: 900, margin: '0 auto 1rem' }}>
+            <Link to="/news" style={{ color: 'var(--primary-blue)' }}>{i18n.language === 'ru' ? '← Все новости' : '← All news'}</Link>
+          </p>
+        )}
 
         {items.length === 0 ? (
           <motion.div className="card" style={{ textAlign: 'center', padding: '4rem 2rem' }}>
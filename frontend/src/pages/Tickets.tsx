@@ -0,0 +1,209 @@
+import React, { useState, useEffect } from 'react';
+import { Link, Navigate, useParams, useSearchParams } from 'react-router-dom';
+import { motion } from 'framer-motion';
+import { LifeBuoy, Send } from 'lucide-react';
+import { useTranslation } from 'react-i18next';
+import { useApp } from '../context/AppContext';
+import { apiService } from '../services/api';
+import * as Types from '../types';
+
+const CATEGORIES: Types.TicketCategory[] = ['cheating', 'abuse', 'griefing', 'scam', 'exploit', 'other'];
+
+const STATUS_LABELS: Record<Types.TicketStatus, { ru: string; en: string }> = {
+  open: { ru: 'Открыт', en: 'Open' },
+  in_progress: { ru: 'В работе', en: 'In progress' },
+  waiting: { ru: 'Ждёт вашего ответа', en: 'Waiting for your reply' },
+  resolved: { ru: 'Решён', en: 'Resolved' },
+  closed: { ru: 'Закрыт', en: 'Closed' },
+};
+
+const inputStyle: React.CSSProperties = {
+  width: '100%',
+  padding: '0.75rem 1rem',
+  background: 'var(--bg-darker)',
+  border: '1px solid var(--border-color)',
+  borderRadius: 8,
+  color: 'var(--text-primary)',
+};
+
+const TicketThread: React.FC<{ id: number; isRu: boolean }> = ({ id, isRu }) => {
+  const [ticket, setTicket] = useState<Types.Ticket | null>(null);
+  const [reply, setReply] = useState('');
+  const [error, setError] = useState('');
+
+  const load = () => apiService.getMyTicket(id).then(setTicket).catch(() => setError(isRu ? 'Тикет не найден' : 'Ticket not found'));
+  useEffect(() => { load(); }, [id]);
+
+  const send = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!reply.trim()) return;
+    try {
+      await apiService.replyMyTicket(id, reply);
+      setReply('');
+      load();
+    } catch {
+      setError(isRu ? 'Не удалось отправить сообщение' : 'Failed to send message');
+    }
+  };
+
+  if (!ticket) return <p style={{ color: 'var(--text-secondary)' }}>{error || '...'}</p>;
+
+  return (
+    <>
+      <p><Link to="/tickets" style={{ color: 'var(--primary-blue)' }}>{isRu ? '← Все тикеты' : '← All tickets'}</Link></p>
+      <h2 style={{ marginBottom: '0.25rem' }}>#{ticket.id} {ticket.subject}</h2>
+      <p style={{ color: 'var(--text-secondary)', marginBottom: '1rem' }}>{STATUS_LABELS[ticket.status][isRu ? 'ru' : 'en']}</p>
+      <div style={{ display: 'flex', flexDirection: 'column', gap: '0.75rem', marginBottom: '1rem' }}>
+        {(ticket.messages || []).map((m) => (
+          <div key={m.id} style={{ padding: '0.75rem 1rem', background: m.authorType === 'staff' ? 'var(--bg-card)' : 'var(--bg-darker)', borderRadius: 8, border: '1px solid var(--border-color)' }}>
+            <div style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', marginBottom: 4 }}>
+              {m.authorType === 'staff' ? (isRu ? 'Поддержка' : 'Support') : m.authorName} · {new Date(m.createdAt).toLocaleString()}
+            </div>
+            <div style={{ whiteSpace: 'pre-wrap' }}>{m.body}</div>
+          </div>
+        ))}
+      </div>
+      {error && <div style={{ color: 'var(--accent-red)', marginBottom: '1rem' }}>{error}</div>}
+      {ticket.status !== 'closed' && (
+        <form onSubmit={send} style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem' }}>
+          <textarea rows={4} value={reply} onChange={(e) => setReply(e.target.value)} style={inputStyle} placeholder={isRu ? 'Ваш ответ' : 'Your reply'} />
+          <button type="submit" className="btn" style={{ alignSelf: 'flex-start', display: 'flex', alignItems: 'center', gap: 8 }}>
+            <Send size={18} /> {isRu ? 'Отправить' : 'Send'}
+          </button>
+        </form>
+      )}
+    </>
+  );
+};
+
+const Tickets: React.FC = () => {
+  const { user, authLoading, refreshUser } = useApp();
+  const { i18n } = useTranslation();
+  const { id } = useParams();
+  const [searchParams] = useSearchParams();
+  const steamResult = searchParams.get('steam');
+  const isRu = i18n.language === 'ru';
+  const [tickets, setTickets] = useState<Types.Ticket[]>([]);
+  const [activeBan, setActiveBan] = useState<Types.Ban | null>(null);
+  const [form, setForm] = useState({ type: 'report' as Types.TicketType, category: 'cheating' as Types.TicketCategory, targetSteamId: '', subject: '', message: '', evidence: '', email: '' });
+  const [error, setError] = useState('');
+  const [sent, setSent] = useState(false);
+
+  useEffect(() => {
+    if (steamResult === 'linked') refreshUser();
+  }, [steamResult]);
+
+  useEffect(() => {
+    if (!user || id) return;
+    apiService.getMyTickets().then(setTickets).catch(() => {});
+    if (user.steamId) apiService.getPlayerBan(user.steamId).then((info) => setActiveBan(info.ban)).catch(() => {});
+  }, [user, id, sent]);
+
+  if (authLoading) return <div className="page-container" />;
+  if (!user) return <Navigate to="/shop" replace />;
+
+  const linkSteam = async () => {
+    try {
+      const { url } = await apiService.linkSteam();
+      window.location.href = url;
+    } catch {
+      setError(isRu ? 'Не удалось начать вход через Steam' : 'Failed to start Steam login');
+    }
+  };
+
+  const submit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError('');
+    try {
+      await apiService.createTicket({
+        type: form.type,
+        category: form.type === 'report' ? form.category : undefined,
+        targetSteamId: form.type === 'report' ? form.targetSteamId.trim() : undefined,
+        banId: form.type === 'appeal' ? activeBan?.id : undefined,
+        subject: form.subject,
+        message: form.message,
+        evidence: form.evidence.split('\n').map((l) => l.trim()).filter(Boolean),
+        email: form.email || undefined,
+      });
+      setForm({ ...form, targetSteamId: '', subject: '', message: '', evidence: '' });
+      setSent(!sent);
+    } catch {
+      setError(isRu ? 'Не удалось создать тикет — проверьте поля' : 'Failed to create ticket — check the fields');
+    }
+  };
+
+  return (
+    <div className="page-container">
+      <motion.div initial={{ opacity: 0, y: 30 }} animate={{ opacity: 1, y: 0 }} transition={{ duration: 0.6 }} className="card" style={{ maxWidth: 760, margin: '2rem auto', padding: '2rem' }}>
+        <div style={{ display: 'flex', alignItems: 'center', gap: '1rem', marginBottom: '1.5rem' }}>
+          <LifeBuoy size={40} color="var(--primary-blue)" />
+          <h1 className="section-title" style={{ marginBottom: 0, fontSize: '1.75rem' }}>
+            {isRu ? 'Жалобы и апелляции' : 'Reports & appeals'}
+          </h1>
+        </div>
+
+        {steamResult && steamResult !== 'linked' && (
+          <div style={{ color: 'var(--accent-red)', marginBottom: '1rem' }}>
+            {steamResult === 'taken'
+              ? (isRu ? 'Этот Steam аккаунт уже привязан к другому пользователю' : 'This Steam account is linked to another user')
+              : (isRu ? 'Не удалось подтвердить Steam аккаунт, попробуйте ещё раз' : 'Steam verification failed, please try again')}
+          </div>
+        )}
+
+        {id ? <TicketThread id={+id} isRu={isRu} /> : (
+          <>
+            <div style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem', marginBottom: '2rem' }}>
+              {tickets.length === 0 && <p style={{ color: 'var(--text-secondary)' }}>{isRu ? 'У вас пока нет тикетов' : 'You have no tickets yet'}</p>}
+              {tickets.map((tk) => (
+                <Link key={tk.id} to={`/tickets/${tk.id}`} style={{ display: 'flex', justifyContent: 'space-between', padding: '0.75rem 1rem', background: 'var(--bg-darker)', borderRadius: 8, color: 'var(--text-primary)', textDecoration: 'none' }}>
+                  <span>#{tk.id} {tk.subject}</span>
+                  <span style={{ color: tk.status === 'waiting' ? 'var(--primary-blue)' : 'var(--text-secondary)' }}>{STATUS_LABELS[tk.status][isRu ? 'ru' : 'en']}</span>
+                </Link>
+              ))}
+            </div>
+
+            <form onSubmit={submit} style={{ display: 'flex', flexDirection: 'column', gap: '0.75rem' }}>
+              <h2 style={{ fontSize: '1.25rem' }}>{isRu ? 'Новый тикет' : 'New ticket'}</h2>
+              <div style={{ display: 'flex', gap: 8 }}>
+                {(['report', 'appeal'] as Types.TicketType[]).map((tp) => (
+                  <button key={tp} type="button" onClick={() => setForm({ ...form, type: tp })} className={form.type === tp ? 'btn' : 'btn btn-secondary'}>
+                    {tp === 'report' ? (isRu ? 'Жалоба на игрока' : 'Report a player') : (isRu ? 'Апелляция бана' : 'Ban appeal')}
+                  </button>
+                ))}
+              </div>
+              {form.type === 'report' ? (
+                <>
+                  <input style={inputStyle} value={form.targetSteamId} onChange={(e) => setForm({ ...form, targetSteamId: e.target.value })} placeholder="SteamID64" required />
+                  <select style={inputStyle} value={form.category} onChange={(e) => setForm({ ...form, category: e.target.value as Types.TicketCategory })}>
+                    {CATEGORIES.map((c) => <option key={c} value={c}>{c}</option>)}
+                  </select>
+                </>
+              ) : activeBan ? (
+                <p style={{ color: 'var(--text-secondary)' }}>{isRu ? 'Бан' : 'Ban'} #{activeBan.id}: {activeBan.reason}</p>
+              ) : (
+                user.steamId ? (
+                  <p style={{ color: 'var(--text-secondary)' }}>{isRu ? 'У вашего SteamID нет активного бана' : 'Your SteamID has no active ban'}</p>
+                ) : (
+                  <div>
+                    <p style={{ color: 'var(--text-secondary)', marginBottom: '0.5rem' }}>{isRu ? 'Чтобы обжаловать бан, подтвердите свой Steam аккаунт' : 'Sign in through Steam to appeal your ban'}</p>
+                    <button type="button" className="btn btn-secondary" onClick={linkSteam}>{isRu ? 'Войти через Steam' : 'Sign in through Steam'}</button>
+                  </div>
+                )
+              )}
+              <input style={inputStyle} value={form.subject} onChange={(e) => setForm({ ...form, subject: e.target.value })} placeholder={isRu ? 'Тема' : 'Subject'} maxLength={200} />
+              <textarea style={inputStyle} rows={5} value={form.message} onChange={(e) => setForm({ ...form, message: e.target.value })} placeholder={isRu ? 'Опишите ситуацию' : 'Describe what happened'} required />
+              <textarea style={inputStyle} rows={2} value={form.evidence} onChange={(e) => setForm({ ...form, evidence: e.target.value })} placeholder={isRu ? 'Ссылки на доказательства (по одной на строку)' : 'Evidence links (one per line)'} />
+              <input style={inputStyle} type="email" required value={form.email} onChange={(e) => setForm({ ...form, email: e.target.value })} placeholder={isRu ? 'Email для уведомлений об ответах' : 'Email for reply notifications'} />
+              {error && <div style={{ color: 'var(--accent-red)' }}>{error}</div>}
+              <button type="submit" className="btn" disabled={form.type === 'appeal' && !activeBan} style={{ alignSelf: 'flex-start' }}>
+                {isRu ? 'Отправить' : 'Submit'}
+              </button>
+            </form>
+          </>
+        )}
+      </motion.div>
+    </div>
+  );
+};
+
+export default Tickets;
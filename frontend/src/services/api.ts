@@ -107,6 +107,10 @@ class ApiService {
     return this.request<Types.News>(`/news/${id}`);
   }
 
+  async getNewsBySlug(slug: string): Promise<Types.News> {
+    return this.request<Types.News>(`/news/by-slug/${encodeURIComponent(slug)}`);
+  }
+
   async createNews(data: Omit<Types.News, 'id' | 'createdAt' | 'updatedAt'>): Promise<Types.News> {
     return this.request<Types.News>('/news', {
       method: 'POST',
@@ -325,15 +329,237 @@ class ApiService {
     return this.request<Types.Player>(`/players/${steamId}`);
   }
 
-  async getClans(withMembers?: boolean): Promise<Types.Clan[]> {
-    const query = withMembers ? '?members=true' : '';
-    return this.request<Types.Clan[]>(`/clans${query}`);
+  async searchPlayers(q: string, limit?: number): Promise<Types.PlayerSearchResult[]> {
+    const params = new URLSearchParams({ q });
+    if (limit) params.append('limit', limit.toString());
+    return this.request<Types.PlayerSearchResult[]>(`/players/search?${params.toString()}`);
+  }
+
+  async getPlayerNames(steamId: string): Promise<Types.PlayerName[]> {
+    return this.request<Types.PlayerName[]>(`/players/${steamId}/names`);
+  }
+
+  async getPlayerKills(steamId: string, days?: number): Promise<Types.PlayerKills> {
+    const params = new URLSearchParams();
+    if (days !== undefined) params.append('days', days.toString());
+    const qs = params.toString();
+    return this.request<Types.PlayerKills>(`/players/${steamId}/kills${qs ? `?${qs}` : ''}`);
+  }
+
+  async getKillFeed(opts: { serverId?: number; after?: number; limit?: number; steamId?: string } = {}): Promise<Types.KillEvent[]> {
+    const params = new URLSearchParams();
+    if (opts.serverId) params.append('serverId', opts.serverId.toString());
+    if (opts.after) params.append('after', opts.after.toString());
+    if (opts.limit) params.append('limit', opts.limit.toString());
+    if (opts.steamId) params.append('steamId', opts.steamId);
+    const qs = params.toString();
+    return this.request<Types.KillEvent[]>(`/kills/feed${qs ? `?${qs}` : ''}`);
+  }
+
+  async getWeaponStats(days?: number, serverId?: number): Promise<Types.WeaponStat[]> {
+    const params = new URLSearchParams();
+    if (days !== undefined) params.append('days', days.toString());
+    if (serverId) params.append('serverId', serverId.toString());
+    const qs = params.toString();
+    return this.request<Types.WeaponStat[]>(`/kills/weapons${qs ? `?${qs}` : ''}`);
+  }
+
+  async getPlayerSessions(steamId: string, days = 30, limit?: number): Promise<Types.PlayerSessions> {
+    const params = new URLSearchParams({ days: days.toString() });
+    if (limit) params.append('limit', limit.toString());
+    return this.request<Types.PlayerSessions>(`/players/${steamId}/sessions?${params.toString()}`);
+  }
+
+  async getBans(opts: { q?: string; all?: boolean; page?: number; limit?: number } = {}): Promise<Types.Ban[]> {
+    const params = new URLSearchParams();
+    if (opts.q) params.append('q', opts.q);
+    if (opts.all) params.append('all', '1');
+    if (opts.page) params.append('page', opts.page.toString());
+    if (opts.limit) params.append('limit', opts.limit.toString());
+    const qs = params.toString();
+    return this.request<Types.Ban[]>(`/bans${qs ? `?${qs}` : ''}`);
+  }
+
+  async getPlayerBan(steamId: string): Promise<Types.PlayerBanInfo> {
+    return this.request<Types.PlayerBanInfo>(`/bans/${steamId}`);
+  }
+
+  async getAdminBans(opts: { q?: string; status?: 'active' | 'inactive' | 'rcon_failed'; page?: number; limit?: number } = {}): Promise<Types.Ban[]> {
+    const params = new URLSearchParams();
+    if (opts.q) params.append('q', opts.q);
+    if (opts.status) params.append('status', opts.status);
+    if (opts.page) params.append('page', opts.page.toString());
+    if (opts.limit) params.append('limit', opts.limit.toString());
+    const qs = params.toString();
+    return this.request<Types.Ban[]>(`/admin/bans${qs ? `?${qs}` : ''}`);
+  }
+
+  async createBan(data: { steamId: string; reason: string; duration?: string; evidence?: string[] }): Promise<Types.Ban> {
+    return this.request<Types.Ban>('/admin/bans', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async updateBan(id: number, data: { reason?: string; duration?: string; evidence?: string[] }): Promise<Types.Ban> {
+    return this.request<Types.Ban>(`/admin/bans/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async unban(id: number, reason?: string): Promise<Types.Ban> {
+    return this.request<Types.Ban>(`/admin/bans/${id}/unban`, {
+      method: 'POST',
+      body: JSON.stringify({ reason: reason ?? '' }),
+    });
+  }
+
+  /** Queues RCON re-apply; result shows up in ban.rconStatus */
+  async resyncBan(id: number): Promise<{ ok: boolean; rconStatus: Types.Ban['rconStatus'] }> {
+    return this.request<{ ok: boolean; rconStatus: Types.Ban['rconStatus'] }>(`/admin/bans/${id}/resync`, { method: 'POST' });
+  }
+
+  // Steam OpenID: returns Steam login URL, after login the browser comes back to /tickets?steam=linked
+  async linkSteam(): Promise<{ url: string }> {
+    return this.request<{ url: string }>('/auth/steam/link', { method: 'POST' });
+  }
+
+  async createTicket(data: {
+    type: Types.TicketType;
+    category?: Types.TicketCategory;
+    targetSteamId?: string;
+    banId?: number;
+    subject?: string;
+    message: string;
+    evidence?: string[];
+    email?: string;
+  }): Promise<Types.Ticket> {
+    return this.request<Types.Ticket>('/tickets', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async getMyTickets(): Promise<Types.Ticket[]> {
+    return this.request<Types.Ticket[]>('/tickets');
+  }
+
+  async getMyTicket(id: number): Promise<Types.Ticket> {
+    return this.request<Types.Ticket>(`/tickets/${id}`);
+  }
+
+  async replyMyTicket(id: number, message: string): Promise<Types.TicketMessage> {
+    return this.request<Types.TicketMessage>(`/tickets/${id}/messages`, {
+      method: 'POST',
+      body: JSON.stringify({ message }),
+    });
+  }
+
+  async getAdminTickets(opts: { status?: Types.TicketStatus[]; type?: Types.TicketType; assignee?: 'me' | 'none' | number; q?: string; page?: number; limit?: number } = {}): Promise<Types.Ticket[]> {
+    const params = new URLSearchParams();
+    if (opts.status?.length) params.append('status', opts.status.join(','));
+    if (opts.type) params.append('type', opts.type);
+    if (opts.assignee !== undefined) params.append('assignee', opts.assignee.toString());
+    if (opts.q) params.append('q', opts.q);
+    if (opts.page) params.append('page', opts.page.toString());
+    if (opts.limit) params.append('limit', opts.limit.toString());
+    const qs = params.toString();
+    return this.request<Types.Ticket[]>(`/admin/tickets${qs ? `?${qs}` : ''}`);
+  }
+
+  async getAdminTicket(id: number): Promise<Types.Ticket> {
+    return this.request<Types.Ticket>(`/admin/tickets/${id}`);
+  }
+
+  async updateTicket(id: number, data: { status?: Types.TicketStatus; assigneeId?: number; category?: Types.TicketCategory }): Promise<Types.Ticket> {
+    return this.request<Types.Ticket>(`/admin/tickets/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async replyTicket(id: number, data: { message: string; internal?: boolean; status?: Types.TicketStatus }): Promise<Types.TicketMessage> {
+    return this.request<Types.TicketMessage>(`/admin/tickets/${id}/messages`, {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async getPlayerAchievements(steamId: string): Promise<Types.PlayerAchievement[]> {
+    return this.request<Types.PlayerAchievement[]>(`/players/${steamId}/achievements`);
+  }
+
+  async getAchievements(): Promise<Types.Achievement[]> {
+    return this.request<Types.Achievement[]>('/achievements');
+  }
+
+  async createAchievement(data: Omit<Types.Achievement, 'id' | 'unlocked' | 'createdAt' | 'updatedAt'>): Promise<Types.Achievement> {
+    return this.request<Types.Achievement>('/achievements', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async updateAchievement(id: number, data: Partial<Types.Achievement>): Promise<Types.Achievement> {
+    return this.request<Types.Achievement>(`/achievements/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async deleteAchievement(id: number): Promise<void> {
+    return this.request<void>(`/achievements/${id}`, {
+      method: 'DELETE',
+    });
+  }
+
+  async evaluateAchievements(): Promise<{ ok: string }> {
+    return this.request<{ ok: string }>('/admin/achievements/evaluate', { method: 'POST' });
+  }
+
+  async getAchievementRewardsForReview(): Promise<Types.AchievementRewardReview[]> {
+    return this.request<Types.AchievementRewardReview[]>('/admin/achievements/rewards/review');
+  }
+
+  async reviewAchievementReward(achievementId: number, steamId: string, action: 'retry' | 'skip'): Promise<{ rewardStatus: string }> {
+    return this.request<{ rewardStatus: string }>(`/admin/achievements/${achievementId}/rewards/${steamId}/review`, {
+      method: 'POST',
+      body: JSON.stringify({ action }),
+    });
+  }
+
+  async getPlayerHistory(steamId: string, metric: Types.PlayerHistoryMetric = 'killedPlayers', range = '30d', bucket?: 'hour' | 'day' | 'week'): Promise<Types.PlayerHistory> {
+    const params = new URLSearchParams({ metric, range });
+    if (bucket) params.append('bucket', bucket);
+    return this.request<Types.PlayerHistory>(`/players/${steamId}/history?${params.toString()}`);
+  }
+
+  async getLeaderboard(metric: Types.LeaderboardMetric, opts: { limit?: number; cursor?: string; minPlaytime?: number } = {}): Promise<Types.LeaderboardPage> {
+    const params = new URLSearchParams();
+    if (opts.limit) params.append('limit', opts.limit.toString());
+    if (opts.cursor) params.append('cursor', opts.cursor);
+    if (opts.minPlaytime) params.append('minPlaytime', opts.minPlaytime.toString());
+    const q = params.toString();
+    return this.request<Types.LeaderboardPage>(`/leaderboards/${metric}${q ? `?${q}` : ''}`);
+  }
+
+  async getClans(withMembers?: boolean, sort?: Types.ClanSort): Promise<Types.Clan[]> {
+    const params = new URLSearchParams();
+    if (withMembers) params.append('members', 'true');
+    if (sort) params.append('sort', sort);
+    const q = params.toString();
+    return this.request<Types.Clan[]>(`/clans${q ? `?${q}` : ''}`);
   }
 
   async getClan(id: number): Promise<Types.Clan> {
     return this.request<Types.Clan>(`/clans/${id}`);
   }
 
+  async getClanHistory(id: number, days = 90): Promise<Types.ClanStatHistory[]> {
+    return this.request<Types.ClanStatHistory[]>(`/clans/${id}/history?days=${days}`);
+  }
+
   async getShopCategories(lang?: string): Promise<Types.ShopCategory[]> {
     const query = lang ? `?lang=${lang}` : '';
     return this.request<Types.ShopCategory[]>(`/shop/categories${query}`);
@@ -416,6 +642,19 @@ class ApiService {
     return this.request(url);
   }
 
+  async getOnlineHistory(range: string, bucket?: Types.OnlineHistoryBucket, serverType?: string): Promise<Types.OnlineHistoryPoint[]> {
+    const params = new URLSearchParams({ range });
+    if (bucket) params.append('bucket', bucket);
+    if (serverType) params.append('type', serverType);
+    return this.request<Types.OnlineHistoryPoint[]>(`/server-status/history?${params.toString()}`);
+  }
+
+  async getServerActivity(days = 30, serverType?: string): Promise<Types.ActivityDay[]> {
+    let url = `/server-status/activity?days=${days}`;
+    if (serverType) url += `&type=${serverType}`;
+    return this.request<Types.ActivityDay[]>(url);
+  }
+
   async getAllServers(): Promise<Types.ServerInfo[]> {
     return this.request<Types.ServerInfo[]>('/servers');
   }
@@ -489,6 +728,13 @@ class ApiService {
     return { ok: true, paymentUrl: data.paymentUrl };
   }
 
+  /** Tracked download link: backend records the click and redirects to a healthy mirror */
+  downloadUrl(linkId: number | 'auto', serverId?: number): string {
+    const base = getApiUrl();
+    const q = linkId === 'auto' && serverId ? `?serverId=${serverId}` : '';
+    return `${base}/download/${linkId}${q}`;
+  }
+
   async getDownloadLinks(serverId?: number): Promise<Types.DownloadLink[]> {
     const q = serverId ? `?serverId=${serverId}` : '';
     return this.request<Types.DownloadLink[]>(`/download-links${q}`);
@@ -519,6 +765,16 @@ class ApiService {
     });
   }
 
+  /** WebSocket URL of the RCON console with a single-use ticket (valid for ~30s) */
+  async getRconConsoleUrl(serverId?: number): Promise<string> {
+    const { ticket } = await this.request<{ ticket: string; expiresIn: number }>('/admin/rcon/console/ticket', { method: 'POST' });
+    const base = getApiUrl();
+    const http = base.startsWith('http') ? base : `${window.location.origin}${base}`;
+    const params = new URLSearchParams({ ticket });
+    if (serverId) params.set('serverId', String(serverId));
+    return `${http.replace(/^http/, 'ws')}/admin/rcon/console?${params}`;
+  }
+
   async clearClansAndPlayers(): Promise<{ ok: boolean }> {
     return this.request<{ ok: boolean }>('/admin/clear-clans-players', { method: 'DELETE' });
   }
@@ -5,6 +5,7 @@ export interface ServerInfo {
   gameVersion: string;
   downloadUrl: string;
   virusTotalUrl?: string;
+  clientSha256?: string;
   descriptions: Description[];
   downloadLinks?: DownloadLink[];
   type?: 'classic' | 'deathmatch';
@@ -20,6 +21,11 @@ export interface DownloadLink {
   label: string;
   url: string;
   order: number;
+  weight?: number;
+  status?: 'unknown' | 'ok' | 'degraded' | 'mismatch' | 'dead';
+  size?: number;
+  checksumOk?: boolean | null;
+  checkedAt?: string;
 }
 
 export interface Description {
@@ -33,6 +39,7 @@ export interface Feature {
   id: number;
   serverInfoId: number;
   language: string;
+  translationGroup?: string;
   title: string;
   description?: string;
   icon: string;
@@ -42,11 +49,15 @@ export interface Feature {
 export interface News {
   id: number;
   language: string;
+  translationGroup?: string;
+  slug?: string;
   title: string;
   content: string;
   imageUrl?: string;
+  tags?: string[];
   published: boolean;
   publishedAt: string;
+  announcedAt?: string;
   createdAt: string;
   updatedAt: string;
 }
@@ -54,6 +65,7 @@ export interface News {
 export interface HowToStartStep {
   id: number;
   language: string;
+  translationGroup?: string;
   stepNumber: number;
   title: string;
   content: string;
@@ -65,6 +77,7 @@ export interface ServerDetail {
   id: number;
   serverId?: number;
   language: string;
+  translationGroup?: string;
   section: string;
   title: string;
   content: string;
@@ -77,6 +90,7 @@ export interface Plugin {
   id: number;
   serverId?: number;
   language: string;
+  translationGroup?: string;
   name: string;
   description: string;
   commands: Command[];
@@ -94,9 +108,11 @@ export interface Command {
 export interface Rule {
   id: number;
   language: string;
+  translationGroup?: string;
   title: string;
   content: string;
   order: number;
+  version?: number;
 }
 
 export interface Clan {
@@ -117,6 +133,30 @@ export interface Clan {
   totalKills?: number;
   totalDeaths?: number;
   totalFarm?: number;
+  trackedMembers?: number;
+  onlineMembers?: number;
+  avgKills?: number;
+  kd?: number;
+  wood?: number;
+  metal?: number;
+  sulfur?: number;
+  raidObjects?: number;
+}
+
+export type ClanSort = 'experience' | 'level' | 'kills' | 'kd' | 'farm' | 'raid' | 'online';
+
+export interface ClanStatHistory {
+  hexId: string;
+  day: string;
+  name: string;
+  level: number;
+  experience: number;
+  members: number;
+  totalKills: number;
+  totalDeaths: number;
+  kd: number;
+  totalFarm: number;
+  raidObjects: number;
 }
 
 export interface ClanMember {
@@ -144,6 +184,7 @@ export interface Player {
   clan?: Clan;
   rankPosition?: number;
   stats?: PlayerStats;
+  achievements?: PlayerAchievement[];
 }
 
 export interface PlayerStats {
@@ -159,6 +200,236 @@ export interface PlayerStats {
   suicides: number;
 }
 
+export interface PlayerSearchResult {
+  steamId: string;
+  username: string;
+  matchedName: string;
+  isCurrent: boolean;
+  score: number;
+  clanId?: number;
+  isOnline: boolean;
+  killedPlayers: number;
+}
+
+export interface PlayerName {
+  steamId: string;
+  name: string;
+  seenAt: string;
+}
+
+export interface Achievement {
+  id: number;
+  key: string;
+  name: string;
+  nameEn: string;
+  description: string;
+  descriptionEn: string;
+  icon: string;
+  metric: string;
+  threshold: number;
+  rewardCommand?: string;
+  serverId: number;
+  enabled: boolean;
+  order: number;
+  unlocked: number;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface PlayerAchievement {
+  steamId: string;
+  achievementId: number;
+  achievement?: Achievement;
+  unlockedAt: string;
+  rewardStatus?: 'pending' | 'delivering' | 'ok' | 'failed' | 'needs_review';
+}
+
+// Награда, остановленная на строке с неизвестным результатом (таймаут/обрыв после отправки)
+export interface AchievementRewardReview extends PlayerAchievement {
+  rewardStep: number;
+  rewardError: string;
+}
+
+export interface KillEvent {
+  id: number;
+  serverId: number;
+  killerSteamId: string;
+  killerName: string;
+  victimSteamId: string;
+  victimName: string;
+  weapon: string;
+  distance: number;
+  killedAt: string;
+}
+
+export interface WeaponStat {
+  weapon: string;
+  kills: number;
+  killers: number;
+  avgDistance: number;
+  maxDistance: number;
+}
+
+export interface KillOpponent {
+  steamId: string;
+  username: string;
+  kills: number;
+  lastAt: string;
+}
+
+export interface PlayerKills {
+  steamId: string;
+  days: number;
+  kills: number;
+  deaths: number;
+  nemeses: KillOpponent[];
+  victims: KillOpponent[];
+  weapons: WeaponStat[];
+  recent: KillEvent[];
+}
+
+export interface PlayerSession {
+  id: number;
+  serverId: number;
+  serverType: string;
+  steamId: string;
+  username: string;
+  startedAt: string;
+  endedAt: string | null;
+  lastSeenAt: string;
+  duration: number;
+}
+
+export interface PlayerSessions {
+  steamId: string;
+  days: number;
+  isOnline: boolean;
+  lastSeen: string | null;
+  playSeconds: number;
+  sessionCount: number;
+  sessions: PlayerSession[];
+}
+
+export interface Ban {
+  id: number;
+  steamId: string;
+  username: string;
+  reason: string;
+  evidence: string[] | null;
+  adminName: string;
+  expiresAt: string | null;
+  active: boolean;
+  unbannedAt?: string;
+  unbannedBy?: string;
+  unbanReason?: string;
+  rconStatus: 'pending' | 'ok' | 'failed';
+  rconError?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface PlayerBanInfo {
+  banned: boolean;
+  ban: Ban | null;
+  history: Ban[];
+}
+
+export type TicketType = 'report' | 'appeal';
+export type TicketStatus = 'open' | 'in_progress' | 'waiting' | 'resolved' | 'closed';
+export type TicketCategory = 'cheating' | 'abuse' | 'griefing' | 'scam' | 'exploit' | 'other';
+
+export interface TicketMessage {
+  id: number;
+  ticketId: number;
+  authorType: 'user' | 'staff';
+  authorName: string;
+  body: string;
+  internal: boolean;
+  createdAt: string;
+}
+
+export interface Ticket {
+  id: number;
+  type: TicketType;
+  category: TicketCategory | '';
+  status: TicketStatus;
+  subject: string;
+  userId: number;
+  userLogin: string;
+  email?: string;
+  targetSteamId?: string;
+  banId?: number;
+  evidence: string[] | null;
+  assigneeId: number | null;
+  assigneeName?: string;
+  lastReplyAt: string;
+  lastReplyBy: 'user' | 'staff';
+  messages?: TicketMessage[];
+  createdAt: string;
+  updatedAt: string;
+  // admin view
+  ban?: Ban;
+  activeBan?: Ban;
+}
+
+export type OnlineHistoryBucket = 'raw' | 'hour' | 'day' | 'week' | 'month';
+
+export interface OnlineHistoryPoint {
+  serverId: number;
+  serverType: string;
+  recordedAt: string;
+  players: number;
+  // only for aggregated buckets
+  min?: number;
+  avg?: number;
+  max?: number;
+  peakAt?: string;
+  samples?: number;
+}
+
+export interface ActivityDay {
+  day: string;
+  activePlayers: number;
+  newPlayers: number;
+  sessions: number;
+  playSeconds: number;
+  peak: number;
+}
+
+export type LeaderboardMetric =
+  | 'kills' | 'kd' | 'playtime' | 'raid' | 'farm' | 'wood' | 'metal' | 'sulfur' | 'animals' | 'mutants';
+
+export interface LeaderboardEntry {
+  rank: number;
+  steamId: string;
+  username: string;
+  clanId?: number;
+  clanAbbrev?: string;
+  isOnline: boolean;
+  playTime: number;
+  value: number;
+}
+
+export interface LeaderboardPage {
+  metric: LeaderboardMetric;
+  total: number;
+  entries: LeaderboardEntry[];
+  nextCursor?: string;
+}
+
+export type PlayerHistoryMetric =
+  | 'killedPlayers' | 'killedMutants' | 'killedAnimals' | 'deaths' | 'playTime'
+  | 'raidObjects' | 'wood' | 'metal' | 'sulfur';
+
+export interface PlayerHistory {
+  steamId: string;
+  metric: PlayerHistoryMetric;
+  range: string;
+  bucket: 'hour' | 'day' | 'week';
+  total: number;
+  points: { t: string; value: number }[];
+}
+
 export interface PaymentMethod {
   id: number;
   name: string;
@@ -170,16 +441,35 @@ export interface PaymentMethod {
 export interface LegalDocument {
   id: number;
   language: string;
+  translationGroup?: string;
   type: 'terms' | 'privacy' | 'rules' | 'company_info' | 'payment_rules' | 'refund_policy';
   title: string;
   content: string;
+  version?: number;
   updatedAt: string;
 }
 
+export interface ContentRevision {
+  id: number;
+  entityType: 'legal_document' | 'rule';
+  entityId: number;
+  version: number;
+  language: string;
+  type?: string;
+  title: string;
+  content?: string;
+  order?: number;
+  authorId?: number | null;
+  authorName: string;
+  comment?: string;
+  createdAt: string;
+}
+
 export interface ShopCategory {
   id: number;
   name: string;
   language: string;
+  translationGroup?: string;
   order: number;
   enabled: boolean;
 }
@@ -188,6 +478,7 @@ export interface ShopItem {
   id: number;
   categoryId: number;
   language: string;
+  translationGroup?: string;
   name: string;
   description: string;
   price: number;
@@ -198,6 +489,7 @@ export interface ShopItem {
   features?: string[];
   discount?: number;
   rconCommand?: string;
+  maxQuantity?: number; // 0/1 — по одной штуке; >1 только с {{quantity}} в rconCommand
   warranty?: string;
   specs?: string;
   packageContents?: string;
@@ -301,3 +593,20 @@ export interface SiteConfig {
   fullWipe: WipeSchedule;
   partialWipe: WipeSchedule;
 }
+
+export interface MediaFile {
+  id: number;
+  key: string;
+  thumbKey?: string;
+  storage: 'local' | 's3';
+  url: string;
+  thumbUrl?: string;
+  originalName: string;
+  contentType: string;
+  size: number;
+  width?: number;
+  height?: number;
+  uploadedBy: string;
+  createdAt: string;
+  used?: boolean;
+}